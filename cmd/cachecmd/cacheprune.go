@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package cachecmd provides commands for maintaining the mirror cache of source repositories.
+package cachecmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"itiquette/git-provider-sync/cmd/baseoption"
+	"itiquette/git-provider-sync/internal/configuration"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider"
+	"itiquette/git-provider-sync/internal/target/mirrorcache"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCachePruneCommand creates and returns a new cobra.Command for the 'cache-prune' subcommand.
+func NewCachePruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache-prune",
+		Short: "Remove cached mirrors of repositories no longer in the source",
+		Long: `The 'cache-prune' command removes mirrors from the git.cachedir of the source providers,
+for repositories the sources no longer have. Every configured source is listed before anything is removed.`,
+		Run: runCachePrune,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Only list the mirrors that would be removed")
+
+	return cmd
+}
+
+func runCachePrune(cmd *cobra.Command, _ []string) {
+	ctx := cmd.Root().Context()
+	ctx = baseoption.AddRootInputOptionsToContext(ctx, cmd)
+
+	withCaller := model.CLIOptions(ctx).VerbosityWithCaller
+	outputFormat := model.CLIOptions(ctx).OutputFormat
+	ctx = log.InitLogger(ctx, cmd, withCaller, outputFormat)
+
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	model.HandleError(ctx, err)
+
+	conf, err := configuration.DefaultConfigLoader{}.LoadConfiguration(ctx)
+	model.HandleError(ctx, err)
+
+	model.HandleError(ctx, prune(ctx, conf, dryRun))
+}
+
+// prune removes the mirrors no source refers to, per cache directory.
+// Configurations may share a cache directory, so a mirror is kept if any of their sources has the repository.
+func prune(ctx context.Context, conf *gpsconfig.AppConfiguration, dryRun bool) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering prune")
+
+	keep, err := cachedMirrors(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	if len(keep) == 0 {
+		logger.Info().Msg("No source provider has a git.cachedir configured")
+
+		return nil
+	}
+
+	for _, cacheDir := range slices.Sorted(maps.Keys(keep)) {
+		removed, err := mirrorcache.Prune(ctx, cacheDir, keep[cacheDir], dryRun)
+		if err != nil {
+			return fmt.Errorf("prune %s: %w", cacheDir, err)
+		}
+
+		message := "Removed cached mirrors"
+		if dryRun {
+			message = "dry-run enabled, would remove cached mirrors"
+		}
+
+		logger.Info().Str("cacheDir", cacheDir).Int("count", len(removed)).Strs("mirrors", removed).Msg(message)
+	}
+
+	return nil
+}
+
+// cachedMirrors returns, per cache directory, the mirror directories of all repositories the sources currently have.
+func cachedMirrors(ctx context.Context, conf *gpsconfig.AppConfiguration) (map[string][]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering cachedMirrors")
+
+	keep := map[string][]string{}
+
+	for _, name := range slices.Sorted(maps.Keys(conf.Configurations)) {
+		sourceCfg := conf.Configurations[name].SourceProvider
+		if sourceCfg.Git.CacheDir == "" {
+			continue
+		}
+
+		client, err := provider.NewGitProviderClient(ctx, model.GitProviderClientOption{
+			ProviderType: sourceCfg.ProviderType,
+			HTTPClient:   sourceCfg.HTTPClient,
+			Domain:       sourceCfg.GetDomain(),
+			Repositories: sourceCfg.Repositories,
+			UploadURL:    sourceCfg.GitHubUploadURL(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize provider client for %s: %w", name, err)
+		}
+
+		// Without filtering, repositories that are only excluded or inactive for now keep their mirror
+		projectinfos, err := client.ProjectInfos(ctx, sourceCfg, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list source repositories for %s: %w", name, err)
+		}
+
+		mirrors := keep[sourceCfg.Git.CacheDir]
+
+		for _, projectinfo := range provider.WithWikis(sourceCfg, projectinfos) {
+			mirrorDir, err := mirrorcache.Dir(sourceCfg.Git.CacheDir, model.CloneURL(projectinfo, sourceCfg))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+
+			mirrors = append(mirrors, mirrorDir)
+		}
+
+		keep[sourceCfg.Git.CacheDir] = mirrors
+	}
+
+	return keep, nil
+}
@@ -7,6 +7,7 @@ package cmd
 import (
 	"context"
 
+	"itiquette/git-provider-sync/cmd/cachecmd"
 	"itiquette/git-provider-sync/cmd/mancmd"
 	"itiquette/git-provider-sync/cmd/printcmd"
 	"itiquette/git-provider-sync/cmd/synccmd"
@@ -22,7 +23,7 @@ func newRootCommand(ctx context.Context, versionString string) *cobra.Command {
 		Version: versionString,
 		Short:   "Utility for mirroring and storing Git repositories",
 		Long: `A utility for mirroring Git repositories to various Git providers or storage.
-Supports GitHub, Gitea, GitLab, uncompressed directories, and a compressed archive format (tar.gz).
+Supports GitHub, Gitea, GitLab, Bitbucket Cloud, Bitbucket Server, Azure DevOps, uncompressed directories, and a compressed archive format (tar.gz).
 Allows syncing to multiple target destinations.`,
 	}
 
@@ -38,7 +39,7 @@ Allows syncing to multiple target destinations.`,
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 
 	// Add subcommands,
-	rootCmd.AddCommand(mancmd.NewManCommand(), printcmd.NewPrintCommand(), synccmd.NewSyncCommand())
+	rootCmd.AddCommand(mancmd.NewManCommand(), printcmd.NewPrintCommand(), synccmd.NewSyncCommand(), cachecmd.NewCachePruneCommand())
 
 	return rootCmd
 }
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// collision.go - Repositories of several configurations with the same name at a target
+package synccmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// hashSuffixLength is the number of hex digits of the source hash the suffix-hash policy appends.
+const hashSuffixLength = 8
+
+// targetNames are the names repositories are given at the targets, instead of the name taken by a repository
+// of another configuration, by configuration, target and original name.
+type targetNames map[string]map[string]map[string]string
+
+// of returns the names the repositories of a configuration are given at its targets, by target.
+func (n targetNames) of(configuration string) map[string]map[string]string {
+	return n[configuration]
+}
+
+func (n targetNames) set(configuration, target, originalName, name string) {
+	if n[configuration] == nil {
+		n[configuration] = map[string]map[string]string{}
+	}
+
+	if n[configuration][target] == nil {
+		n[configuration][target] = map[string]string{}
+	}
+
+	n[configuration][target][originalName] = name
+}
+
+// targetClaim is a repository a configuration syncs to a target, under the name it gets there.
+type targetClaim struct {
+	configuration string
+	target        string
+	// targetKey is the owner or directory at the target the repository is synced to
+	targetKey    string
+	originalName string
+	name         string
+	// source identifies the source repository, the same repository synced by two configurations is no collision
+	source string
+	owner  string
+	domain string
+	policy string
+}
+
+// planTargetNames finds the repositories synced by several configurations to the same owner or directory
+// under the same name, before anything is synced, and names them after the syncrun.oncollision policy of their target.
+// Only the configurations with a target shared with another configuration have their source listed for it,
+// and the listings are returned by configuration for their sync.
+func planTargetNames(ctx context.Context, cfg *gpsconfig.AppConfiguration) (targetNames, map[string]*sourceListing, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering planTargetNames")
+
+	listings := map[string]*sourceListing{}
+
+	shared := sharedTargets(cfg)
+	if len(shared) == 0 {
+		return targetNames{}, listings, nil
+	}
+
+	var claims []targetClaim
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Configurations)) {
+		config := cfg.Configurations[name]
+
+		targets := slices.DeleteFunc(slices.Sorted(maps.Keys(config.ProviderTargets)), func(targetName string) bool {
+			return !shared[targetKey(config.ProviderTargets[targetName])]
+		})
+		if len(targets) == 0 {
+			continue
+		}
+
+		sourceCfg := config.SourceProvider
+
+		listing, err := listSource(ctx, sourceCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		listings[name] = listing
+
+		projectinfos, err := listing.synced(ctx, sourceCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, targetName := range targets {
+			targetCtx, targetCfg := withSubgroups(ctx, sourceCfg, config.ProviderTargets[targetName])
+			targetCtx = withNameTemplate(targetCtx, sourceCfg, targetCfg)
+
+			// Wikis are named after their repository, and follow it
+			for _, projectinfo := range projectinfos {
+				claims = append(claims, targetClaim{
+					configuration: name,
+					target:        targetName,
+					targetKey:     targetKey(targetCfg),
+					originalName:  projectinfo.OriginalName,
+					name:          projectinfo.Name(targetCtx),
+					source:        sourceIdentity(sourceCfg, projectinfo),
+					owner:         sourceCfg.Group + sourceCfg.User,
+					domain:        sourceCfg.GetDomain(),
+					policy:        targetCfg.SyncRun.CollisionPolicy(),
+				})
+			}
+		}
+	}
+
+	names, err := resolveCollisions(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return names, listings, nil
+}
+
+// sharedTargets returns the owners and directories at the targets that several configurations sync to.
+func sharedTargets(cfg *gpsconfig.AppConfiguration) map[string]bool {
+	configurations := map[string]map[string]bool{}
+
+	for name, config := range cfg.Configurations {
+		for _, targetCfg := range config.ProviderTargets {
+			key := targetKey(targetCfg)
+			if configurations[key] == nil {
+				configurations[key] = map[string]bool{}
+			}
+
+			configurations[key][name] = true
+		}
+	}
+
+	shared := map[string]bool{}
+
+	for key, names := range configurations {
+		if len(names) > 1 {
+			shared[key] = true
+		}
+	}
+
+	return shared
+}
+
+// targetKey returns the owner or directory at the target repositories are synced to.
+func targetKey(targetCfg gpsconfig.ProviderConfig) string {
+	switch strings.ToLower(targetCfg.ProviderType) {
+	case gpsconfig.ARCHIVE:
+		return gpsconfig.ARCHIVE + ":" + targetCfg.ArchiveTargetDir()
+	case gpsconfig.DIRECTORY:
+		return gpsconfig.DIRECTORY + ":" + targetCfg.DirectoryTargetDir()
+	default:
+		return strings.ToLower(targetCfg.ProviderType + ":" + targetCfg.GetDomain() + "/" + targetCfg.Group + targetCfg.User)
+	}
+}
+
+// sourceIdentity identifies a source repository by its clone url, or by its domain, owner and name without one.
+func sourceIdentity(sourceCfg gpsconfig.ProviderConfig, projectinfo model.ProjectInfo) string {
+	if projectinfo.HTTPSURL != "" {
+		return strings.ToLower(strings.TrimSuffix(projectinfo.HTTPSURL, ".git"))
+	}
+
+	return strings.ToLower(sourceCfg.ProviderType + ":" + sourceCfg.GetDomain() + "/" + sourceCfg.Group + sourceCfg.User + "/" + projectinfo.OriginalName)
+}
+
+// resolveCollisions names the repositories of several sources with the same name at a target after their policy.
+// The names are compared regardless of case, as most providers do. A collision of a repository whose policy is
+// fail, or one that remains after renaming, fails the run.
+func resolveCollisions(claims []targetClaim) (targetNames, error) {
+	byName := map[string][]targetClaim{}
+
+	for _, claim := range claims {
+		key := claim.targetKey + "/" + strings.ToLower(claim.name)
+		byName[key] = append(byName[key], claim)
+	}
+
+	names := targetNames{}
+	final := map[string]string{}
+
+	var collisions []string
+
+	for _, key := range slices.Sorted(maps.Keys(byName)) {
+		colliding := byName[key]
+		collides := slices.ContainsFunc(colliding, func(claim targetClaim) bool { return claim.source != colliding[0].source })
+
+		for _, claim := range colliding {
+			name := claim.name
+
+			if collides {
+				if claim.policy == gpsconfig.OnCollisionFail {
+					collisions = append(collisions, fmt.Sprintf("%s from %s", claim.name, claim.configuration))
+
+					continue
+				}
+
+				name = collisionName(claim)
+				names.set(claim.configuration, claim.target, claim.originalName, name)
+			}
+
+			// The renamed repositories must not take the name of another repository either
+			finalKey := claim.targetKey + "/" + strings.ToLower(name)
+			if source, found := final[finalKey]; found && source != claim.source {
+				collisions = append(collisions, fmt.Sprintf("%s from %s", name, claim.configuration))
+
+				continue
+			}
+
+			final[finalKey] = claim.source
+		}
+	}
+
+	if len(collisions) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTargetCollision, strings.Join(collisions, ", "))
+	}
+
+	return names, nil
+}
+
+// collisionName returns the name a colliding repository is given by its policy.
+// A plain git source has no owner or domain to prefix with, its repositories get the hash suffix instead.
+func collisionName(claim targetClaim) string {
+	var prefix string
+
+	switch claim.policy {
+	case gpsconfig.OnCollisionPrefixOwner:
+		prefix = strings.ReplaceAll(claim.owner, "/", "-")
+	case gpsconfig.OnCollisionPrefixDomain:
+		prefix = claim.domain
+	}
+
+	if prefix != "" {
+		return prefix + "-" + claim.name
+	}
+
+	hash := sha256.Sum256([]byte(claim.source))
+
+	return claim.name + "-" + hex.EncodeToString(hash[:])[:hashSuffixLength]
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"testing"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedTargets(t *testing.T) {
+	gitea := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITEA, Domain: "gitea.example.com", Group: "Mirrors"}
+	other := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITEA, Domain: "gitea.example.com", Group: "other"}
+
+	shared := sharedTargets(&gpsconfig.AppConfiguration{Configurations: map[string]gpsconfig.ProvidersConfig{
+		"github": {ProviderTargets: map[string]gpsconfig.ProviderConfig{"gitea": gitea, "other": other}},
+		"gitlab": {ProviderTargets: map[string]gpsconfig.ProviderConfig{"gitea": {ProviderType: "Gitea", Domain: "gitea.example.com", Group: "mirrors"}}},
+	}})
+
+	require.Equal(t, map[string]bool{targetKey(gitea): true}, shared)
+}
+
+func TestResolveCollisions(t *testing.T) {
+	claim := func(configuration, name, source, policy string) targetClaim {
+		return targetClaim{
+			configuration: configuration,
+			target:        "gitea",
+			targetKey:     "gitea:gitea.example.com/mirrors",
+			originalName:  name,
+			name:          name,
+			source:        source,
+			owner:         configuration + "/team",
+			domain:        configuration + ".com",
+			policy:        policy,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		claims    []targetClaim
+		wantNames targetNames
+		wantErr   bool
+	}{
+		{
+			name:      "different names",
+			claims:    []targetClaim{claim("github", "api", "github.com/team/api", gpsconfig.OnCollisionFail), claim("gitlab", "web", "gitlab.com/team/web", gpsconfig.OnCollisionFail)},
+			wantNames: targetNames{},
+		},
+		{
+			name:      "same source repository",
+			claims:    []targetClaim{claim("github", "api", "github.com/team/api", gpsconfig.OnCollisionFail), claim("backup", "api", "github.com/team/api", gpsconfig.OnCollisionFail)},
+			wantNames: targetNames{},
+		},
+		{
+			name:    "fail",
+			claims:  []targetClaim{claim("github", "api", "github.com/team/api", gpsconfig.OnCollisionPrefixOwner), claim("gitlab", "API", "gitlab.com/team/api", gpsconfig.OnCollisionFail)},
+			wantErr: true,
+		},
+		{
+			name:   "prefix owner and domain",
+			claims: []targetClaim{claim("github", "api", "github.com/team/api", gpsconfig.OnCollisionPrefixOwner), claim("gitlab", "api", "gitlab.com/team/api", gpsconfig.OnCollisionPrefixDomain)},
+			wantNames: targetNames{
+				"github": {"gitea": {"api": "github-team-api"}},
+				"gitlab": {"gitea": {"api": "gitlab.com-api"}},
+			},
+		},
+		{
+			name:   "suffix hash",
+			claims: []targetClaim{claim("github", "api", "github.com/team/api", gpsconfig.OnCollisionSuffixHash), claim("gitlab", "api", "gitlab.com/team/api", gpsconfig.OnCollisionSuffixHash)},
+			wantNames: targetNames{
+				"github": {"gitea": {"api": "api-2d8a94ff"}},
+				"gitlab": {"gitea": {"api": "api-ebdc0a34"}},
+			},
+		},
+		{
+			name: "renamed onto another repository",
+			claims: []targetClaim{
+				claim("github", "api", "github.com/team/api", gpsconfig.OnCollisionPrefixOwner), claim("gitlab", "api", "gitlab.com/team/api", gpsconfig.OnCollisionSuffixHash),
+				claim("gitlab", "github-team-api", "gitlab.com/team/github-team-api", gpsconfig.OnCollisionFail),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			names, err := resolveCollisions(tabletest.claims)
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrTargetCollision)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.wantNames, names)
+		})
+	}
+}
+
+func TestTargetNamesName(t *testing.T) {
+	ctx := model.WithTargetNames(model.WithCLIOption(context.Background(), model.CLIOption{}), map[string]string{"api": "github-team-api"})
+
+	require.Equal(t, "github-team-api", model.ProjectInfo{OriginalName: "api"}.Name(ctx))
+	require.Equal(t, "github-team-api.wiki", model.ProjectInfo{OriginalName: "api"}.Wiki().Name(ctx))
+	require.Equal(t, "web", model.ProjectInfo{OriginalName: "web"}.Name(ctx))
+}
@@ -22,6 +22,8 @@ var (
 	ErrInvalidRepoName    = errors.New("invalid repository name")
 	ErrEmptyMetainfo      = errors.New("empty repository metainfo")
 	ErrMissingSyncRunMeta = errors.New("missing sync run metadata")
+	ErrRepositoryFailures = errors.New("one or more repositories failed to sync")
+	ErrTargetCollision    = errors.New("repositories of several configurations have the same name at a target")
 )
 
 func NewSyncCommand() *cobra.Command {
@@ -67,6 +69,10 @@ func addFlagsToContext(ctx context.Context, flags *syncFlags) context.Context {
 	opts.CleanupName = flags.cleanupName
 	opts.DryRun = flags.dryRun
 	opts.ActiveFromLimit = flags.activeFromLimit
+	opts.ContinueOnError = flags.continueOnError
+	opts.Resume = flags.resume
+	opts.JournalFile = flags.journalFile
+	opts.Prune = flags.prune
 
 	return model.WithCLIOption(ctx, opts)
 }
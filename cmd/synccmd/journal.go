@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// journal.go - Resuming interrupted runs
+package synccmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/runjournal"
+)
+
+const tmpDirPrefix = "gitprovidersync"
+
+// journalHashLength is the number of hex digits of the configuration file path hash in the name of the default journal.
+const journalHashLength = 16
+
+// startRun creates the temporary directory and the journal of the run, and adds them to the context.
+// With --resume, the journal of an interrupted run is picked up instead, reusing its temporary directory if still present.
+// Without a journal location, the run is not journaled and can't be resumed.
+func startRun(ctx context.Context, cfg *gpsconfig.AppConfiguration) (context.Context, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering startRun")
+
+	path, err := journalPath(ctx)
+	if err != nil {
+		if model.CLIOptions(ctx).Resume {
+			return nil, err
+		}
+
+		logger.Debug().Err(err).Msg("run journal disabled")
+
+		return model.CreateTmpDir(ctx, "", tmpDirPrefix) //nolint:wrapcheck
+	}
+
+	fingerprint, err := runjournal.Fingerprint(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("run journal: %w", err)
+	}
+
+	if model.CLIOptions(ctx).Resume {
+		journal, err := runjournal.Resume(ctx, path, fingerprint)
+
+		switch {
+		case err == nil:
+			return resumeRun(ctx, journal)
+		case errors.Is(err, runjournal.ErrNoJournal), errors.Is(err, runjournal.ErrJournalMismatch):
+			logger.Info().Str("reason", err.Error()).Msg("Nothing to resume, starting a new run")
+		default:
+			return nil, fmt.Errorf("resume run: %w", err)
+		}
+	}
+
+	ctx, err = model.CreateTmpDir(ctx, "", tmpDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	tmpDir, err := model.GetTmpDirPath(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get temporary directory: %w", err)
+	}
+
+	journal, err := runjournal.Start(ctx, path, fingerprint, tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("start run journal: %w", err)
+	}
+
+	return runjournal.WithJournal(ctx, journal), nil
+}
+
+func resumeRun(ctx context.Context, journal *runjournal.Journal) (context.Context, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering resumeRun")
+
+	tmpDir := journal.TmpDir()
+
+	if info, err := os.Stat(tmpDir); err == nil && info.IsDir() {
+		logger.Info().Str("tmpDir", tmpDir).Msg("Resuming interrupted run")
+
+		ctx = context.WithValue(ctx, model.TmpDirKey{}, tmpDir)
+
+		return runjournal.WithJournal(ctx, journal), nil
+	}
+
+	ctx, err := model.CreateTmpDir(ctx, "", tmpDirPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	tmpDir, err = model.GetTmpDirPath(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get temporary directory: %w", err)
+	}
+
+	if err := journal.SetTmpDir(tmpDir); err != nil {
+		return nil, fmt.Errorf("resume run: %w", err)
+	}
+
+	logger.Info().Str("tmpDir", tmpDir).Msg("Resuming interrupted run, its temporary directory is gone")
+
+	return runjournal.WithJournal(ctx, journal), nil
+}
+
+// journalPath returns where the run journal is kept, by default in the user cache directory.
+// The default journal is named after the configuration file, so runs of different configuration files don't share one.
+func journalPath(ctx context.Context) (string, error) {
+	cliOption := model.CLIOptions(ctx)
+	if cliOption.JournalFile != "" {
+		return cliOption.JournalFile, nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no location for the run journal, set --journal-file: %w", err)
+	}
+
+	configPath := cliOption.ConfigFilePath
+	if configPath != "" {
+		if absPath, err := filepath.Abs(configPath); err == nil {
+			configPath = absPath
+		}
+	}
+
+	hash := sha256.Sum256([]byte(configPath))
+
+	return filepath.Join(cacheDir, tmpDirPrefix, "journal-"+hex.EncodeToString(hash[:])[:journalHashLength]+".json"), nil
+}
+
+// pendingProjectInfos leaves out the repositories an interrupted run already completed for every target
+// still to sync, they need not be cloned again.
+func pendingProjectInfos(ctx context.Context, name string, config gpsconfig.ProvidersConfig, projectinfos []model.ProjectInfo) []model.ProjectInfo {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering pendingProjectInfos")
+
+	journal := runjournal.FromContext(ctx)
+	if journal == nil {
+		return projectinfos
+	}
+
+	pending := slices.DeleteFunc(slices.Clone(projectinfos), func(projectinfo model.ProjectInfo) bool {
+		for target := range config.ProviderTargets {
+			if !journal.Completed(runjournal.Key(name, target)) &&
+				!journal.Completed(runjournal.Key(name, target, projectinfo.OriginalName)) {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	if completed := len(projectinfos) - len(pending); completed > 0 {
+		logger.Info().Int("count", completed).Msg("Skipping repositories completed before the interruption")
+	}
+
+	return pending
+}
+
+// completeStep records a step of the run as completed. Failing to write the journal doesn't fail the sync,
+// it only means the step is repeated if the run is resumed.
+func completeStep(ctx context.Context, names ...string) {
+	if err := runjournal.FromContext(ctx).Complete(runjournal.Key(names...)); err != nil {
+		log.Logger(ctx).Warn().Err(err).Msg("failed to record progress in the run journal")
+	}
+}
+
+// stepCompleted reports whether an interrupted run already completed the step.
+func stepCompleted(ctx context.Context, names ...string) bool {
+	return runjournal.FromContext(ctx).Completed(runjournal.Key(names...))
+}
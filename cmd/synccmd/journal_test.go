@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/runjournal"
+
+	"github.com/stretchr/testify/require"
+)
+
+func journalContext(t *testing.T, resume bool) (context.Context, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+
+	return model.WithCLIOption(context.Background(), model.CLIOption{Resume: resume, JournalFile: path}), path
+}
+
+func TestStartRunResume(t *testing.T) {
+	require := require.New(t)
+
+	cfg := &gpsconfig.AppConfiguration{Configurations: map[string]gpsconfig.ProvidersConfig{
+		"config": {SourceProvider: gpsconfig.ProviderConfig{ProviderType: "gitlab"}},
+	}}
+
+	ctx, path := journalContext(t, false)
+
+	started, err := startRun(ctx, cfg)
+	require.NoError(err)
+
+	tmpDir, err := model.GetTmpDirPath(started)
+	require.NoError(err)
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	completeStep(started, "config", "target", "repo")
+
+	resumeCtx := model.WithCLIOption(context.Background(), model.CLIOption{Resume: true, JournalFile: path})
+
+	resumed, err := startRun(resumeCtx, cfg)
+	require.NoError(err)
+
+	resumedTmpDir, err := model.GetTmpDirPath(resumed)
+	require.NoError(err)
+	require.Equal(tmpDir, resumedTmpDir, "the temporary directory of the interrupted run is reused")
+	require.True(stepCompleted(resumed, "config", "target", "repo"))
+
+	// A changed configuration starts over
+	cfg.Configurations["config"] = gpsconfig.ProvidersConfig{SourceProvider: gpsconfig.ProviderConfig{ProviderType: "github"}}
+
+	restarted, err := startRun(resumeCtx, cfg)
+	require.NoError(err)
+	require.False(stepCompleted(restarted, "config", "target", "repo"))
+
+	restartedTmpDir, err := model.GetTmpDirPath(restarted)
+	require.NoError(err)
+	require.NotEqual(tmpDir, restartedTmpDir)
+	os.RemoveAll(restartedTmpDir)
+}
+
+func TestPendingProjectInfos(t *testing.T) {
+	require := require.New(t)
+
+	ctx, path := journalContext(t, true)
+
+	journal, err := runjournal.Start(ctx, path, "fingerprint", t.TempDir())
+	require.NoError(err)
+
+	ctx = runjournal.WithJournal(ctx, journal)
+	completeStep(ctx, "config", "first", "done")
+	completeStep(ctx, "config", "second", "done")
+	completeStep(ctx, "config", "first", "partly")
+	completeStep(ctx, "config", "third")
+
+	config := gpsconfig.ProvidersConfig{ProviderTargets: map[string]gpsconfig.ProviderConfig{"first": {}, "second": {}, "third": {}}}
+	projectinfos := []model.ProjectInfo{{OriginalName: "done"}, {OriginalName: "partly"}, {OriginalName: "new"}}
+
+	require.Equal(projectinfos[1:], pendingProjectInfos(ctx, "config", config, projectinfos))
+	require.Equal(projectinfos, pendingProjectInfos(context.Background(), "config", config, projectinfos))
+}
+
+func TestJournalPath(t *testing.T) {
+	require := require.New(t)
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	pathOf := func(cliOption model.CLIOption) string {
+		path, err := journalPath(model.WithCLIOption(context.Background(), cliOption))
+		require.NoError(err)
+
+		return path
+	}
+
+	require.Equal("/cache/journal.json", pathOf(model.CLIOption{JournalFile: "/cache/journal.json", ConfigFilePath: "/etc/a.yaml"}))
+	require.Equal(pathOf(model.CLIOption{ConfigFilePath: "/etc/a.yaml"}), pathOf(model.CLIOption{ConfigFilePath: "/etc/a.yaml"}))
+	require.NotEqual(pathOf(model.CLIOption{ConfigFilePath: "/etc/a.yaml"}), pathOf(model.CLIOption{ConfigFilePath: "/etc/b.yaml"}))
+}
@@ -9,7 +9,6 @@ import (
 	"context"
 	"strings"
 
-	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
@@ -17,13 +16,13 @@ import (
 	"github.com/rs/zerolog"
 )
 
-func initTargetSync(ctx context.Context, sourceProvider gpsconfig.ProviderConfig, targetProvider gpsconfig.ProviderConfig, repositories []interfaces.GitRepository) context.Context {
-	meta := model.NewSyncRunMetainfo(0, sourceProvider.GetDomain(), targetProvider.ProviderType, len(repositories))
+func initTargetSync(ctx context.Context, sourceProvider gpsconfig.ProviderConfig, targetProvider gpsconfig.ProviderConfig, total int) (context.Context, *model.SyncRunMetainfo) {
+	meta := model.NewSyncRunMetainfo(0, sourceProvider.GetDomain(), targetProvider.ProviderType, total)
 	ctx = context.WithValue(ctx, model.SyncRunMetainfoKey{}, meta)
 
 	logSyncStart(ctx, sourceProvider, targetProvider)
 
-	return ctx
+	return ctx, meta
 }
 
 func logSyncStart(ctx context.Context, _, target gpsconfig.ProviderConfig) {
@@ -67,28 +66,41 @@ func summary(ctx context.Context, sourceProvider gpsconfig.ProviderConfig) {
 		Str("usr/group", userGroup).
 		Msg("Completed sync run")
 
-	logger.Info().Msgf("Sync request: %d repositories", syncRunMetaInfo.Total)
+	logger.Info().Int("synced", syncRunMetaInfo.SyncedCount()).Msgf("Sync request: %d repositories", syncRunMetaInfo.Total)
 	logFailures(logger, syncRunMetaInfo)
 }
 
 func logFailures(logger *zerolog.Logger, meta *model.SyncRunMetainfo) {
-	if len(meta.Fail) == 0 {
-		return
-	}
-
-	if invalidCount := len(meta.Fail["invalid"]); invalidCount > 0 {
+	if invalid := meta.Failures("invalid"); len(invalid) > 0 {
 		logger.Info().
-			Int("count", invalidCount).
-			Strs("repositories", meta.Fail["invalid"]).
+			Int("count", len(invalid)).
+			Strs("repositories", invalid).
 			Msg("skipped repositories due to invalid naming")
 	}
 
-	if upToDateCount := len(meta.Fail["uptodate"]); upToDateCount > 0 {
+	if upToDate := meta.Failures("uptodate"); len(upToDate) > 0 {
 		logger.Info().
-			Int("count", upToDateCount).
-			Strs("repositories", meta.Fail["uptodate"]).
+			Int("count", len(upToDate)).
+			Strs("repositories", upToDate).
 			Msg("ignored up-to-date repositories")
 	}
+
+	if resumed := meta.Failures("resumed"); len(resumed) > 0 {
+		logger.Info().
+			Int("count", len(resumed)).
+			Strs("repositories", resumed).
+			Msg("skipped repositories completed before the interruption")
+	}
+
+	for _, category := range model.FailureCategories {
+		if failed := meta.Failures(category); len(failed) > 0 {
+			logger.Error().
+				Str("category", category).
+				Int("count", len(failed)).
+				Strs("repositories", failed).
+				Msg("failed repositories")
+		}
+	}
 }
 
 func logDryRun(ctx context.Context, cfg gpsconfig.ProviderConfig, metainfo []model.ProjectInfo) {
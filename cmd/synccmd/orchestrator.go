@@ -7,10 +7,12 @@ package synccmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/runjournal"
 )
 
 func sync(ctx context.Context, cfg *gpsconfig.AppConfiguration) error {
@@ -18,17 +20,50 @@ func sync(ctx context.Context, cfg *gpsconfig.AppConfiguration) error {
 	logger.Trace().Msg("Entering sync")
 	cfg.DebugLog(logger)
 
-	ctx, err := model.CreateTmpDir(ctx, "", "gitprovidersync")
+	ctx, err := startRun(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create temporary directory: %w", err)
+		return err
 	}
 
 	//defer cleanup(ctx)
 
-	for _, config := range cfg.Configurations {
-		if err := sourceToTarget(ctx, config); err != nil {
-			return fmt.Errorf("failed source to target: %w", err)
+	// Collisions at the targets are found before anything is pushed
+	names, listings, err := planTargetNames(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Repository failures are only returned when continuing on errors, they don't stop the other configurations
+	var failures []error
+
+	for name, config := range cfg.Configurations {
+		if stepCompleted(ctx, name) {
+			logger.Info().Str("configuration", name).Msg("Skipping configuration completed before the interruption")
+
+			continue
+		}
+
+		if err := sourceToTarget(ctx, name, config, names.of(name), listings[name]); err != nil {
+			if !errors.Is(err, ErrRepositoryFailures) {
+				return fmt.Errorf("failed source to target: %w", err)
+			}
+
+			failures = append(failures, err)
+
+			continue
 		}
+
+		completeStep(ctx, name)
+	}
+
+	if len(failures) > 0 {
+		logger.Error().Int("count", len(failures)).Msg("All syncs completed with failures")
+
+		return errors.Join(failures...)
+	}
+
+	if err := runjournal.FromContext(ctx).Finish(); err != nil {
+		logger.Warn().Err(err).Msg("failed to record the end of the run in the run journal")
 	}
 
 	logger.Info().Msg("All syncs completed")
@@ -36,22 +71,69 @@ func sync(ctx context.Context, cfg *gpsconfig.AppConfiguration) error {
 	return nil
 }
 
-func sourceToTarget(ctx context.Context, config gpsconfig.ProvidersConfig) error {
+func sourceToTarget(ctx context.Context, name string, config gpsconfig.ProvidersConfig, names map[string]map[string]string, listing *sourceListing) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering sourceToTarget")
 
-	repositories, err := sourceRepositories(ctx, config.SourceProvider)
+	var failures []error
+
+	state, err := loadSyncState(ctx, name, config)
 	if err != nil {
-		return fmt.Errorf("failed to fetch source repositories: %w", err)
+		return err
 	}
 
-	for _, targetProvider := range config.ProviderTargets {
-		if err := toTarget(ctx, config.SourceProvider, targetProvider, repositories); err != nil {
-			return fmt.Errorf("failed to sync to target: %w", err)
+	// Listed once for the clones and the reconciliation, unless listed for the collisions at the targets already
+	if listing == nil {
+		listing, err = listSource(ctx, config.SourceProvider)
+		if err != nil {
+			return fmt.Errorf("failed to list source repositories: %w", err)
 		}
 	}
 
-	return nil
+	repositories, err := sourceRepositories(ctx, name, config, listing, state)
+	if err != nil {
+		if !errors.Is(err, ErrRepositoryFailures) {
+			return fmt.Errorf("failed to fetch source repositories: %w", err)
+		}
+
+		failures = append(failures, err)
+	}
+
+	defer releaseRepositories(repositories)
+
+	for targetName, targetProvider := range config.ProviderTargets {
+		if stepCompleted(ctx, name, targetName) {
+			logger.Info().Str("target", targetName).Msg("Skipping target completed before the interruption")
+
+			continue
+		}
+
+		targetCtx, targetCfg := withSubgroups(ctx, config.SourceProvider, targetProvider)
+		targetCtx = withNameTemplate(targetCtx, config.SourceProvider, targetCfg)
+		targetCtx = model.WithTargetNames(targetCtx, names[targetName])
+
+		if err := reconcile(targetCtx, config.SourceProvider, targetName, targetCfg, repositories, listing, state); err != nil {
+			if !errors.Is(err, ErrRepositoryFailures) {
+				return fmt.Errorf("failed to reconcile target: %w", err)
+			}
+
+			failures = append(failures, err)
+		}
+
+		if err := toTarget(targetCtx, name, config.SourceProvider, targetName, targetCfg, repositories, state); err != nil {
+			if !errors.Is(err, ErrRepositoryFailures) {
+				return fmt.Errorf("failed to sync to target: %w", err)
+			}
+
+			failures = append(failures, err)
+
+			continue
+		}
+
+		completeStep(ctx, name, targetName)
+	}
+
+	return errors.Join(failures...)
 }
 
 // func cleanup(ctx context.Context) {
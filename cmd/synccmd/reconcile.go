@@ -0,0 +1,278 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// reconcile.go - Following repositories renamed or deleted at the source on the targets
+package synccmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider"
+)
+
+// sourceListing is every repository at the source, regardless of the repository filters,
+// as a repository left out by a filter is not missing at the source.
+// The source is listed once for the sync of a configuration, the repositories to sync are filtered from the listing.
+type sourceListing struct {
+	client       interfaces.GitProvider
+	projectinfos []model.ProjectInfo
+}
+
+// listSource lists the repositories of the source. An archive source extracts its archives when listed.
+func listSource(ctx context.Context, sourceCfg gpsconfig.ProviderConfig) (*sourceListing, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering listSource")
+
+	client, err := createProviderClient(ctx, sourceCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider client: %w", err)
+	}
+
+	logger.Info().
+		Str("domain", sourceCfg.GetDomain()).
+		Str("provider", client.Name()).
+		Str("usr/grp", sourceCfg.User+sourceCfg.Group).
+		Msg("Fetching repository projectinfo/s from:")
+
+	projectinfos, err := client.ProjectInfos(ctx, sourceCfg, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository metainfo for %s: %w", sourceCfg.ProviderType, err)
+	}
+
+	return &sourceListing{client: client, projectinfos: projectinfos}, nil
+}
+
+// synced returns the listed repositories the repository filters of the source keep.
+func (l *sourceListing) synced(ctx context.Context, sourceCfg gpsconfig.ProviderConfig) ([]model.ProjectInfo, error) {
+	projectinfos, err := l.client.FilterProjectInfos(ctx, sourceCfg, l.projectinfos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter repository metainfo for %s: %w", sourceCfg.ProviderType, err)
+	}
+
+	return projectinfos, nil
+}
+
+// reconcile follows the repositories renamed and deleted at the source on the target, before syncing to it.
+//
+// A repository renamed at the source is recognized by the project ID recorded in the state file,
+// and renamed at the target too, instead of being pushed anew under its new name.
+//
+// A target repository created by this configuration, but gone at the source, gets the syncrun.onmissing policy
+// of the target applied. Such repositories are known from the state file, or from the description
+// Git Provider Sync gave them, when that names a repository of the source owner.
+func reconcile(ctx context.Context, sourceCfg gpsconfig.ProviderConfig, targetName string, targetCfg gpsconfig.ProviderConfig, repositories []interfaces.GitRepository, listing *sourceListing, state *syncState) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering reconcile")
+
+	if targetCfg.ProviderType == gpsconfig.ARCHIVE || targetCfg.ProviderType == gpsconfig.DIRECTORY {
+		return nil
+	}
+
+	renames := state.renamed(targetName, repositories)
+	policy := targetCfg.SyncRun.MissingPolicy()
+
+	if len(renames) == 0 && policy == gpsconfig.OnMissingIgnore {
+		return nil
+	}
+
+	client, err := createProviderClient(ctx, targetCfg)
+	if err != nil {
+		return fmt.Errorf("create target provider client: %w", err)
+	}
+
+	targetInfos, err := client.ProjectInfos(ctx, targetCfg, false)
+	if err != nil {
+		return fmt.Errorf("%w: %w", provider.ErrRepositoryLookup, err)
+	}
+
+	existing := map[string]bool{}
+	for _, projectinfo := range targetInfos {
+		existing[strings.ToLower(projectinfo.OriginalName)] = true
+	}
+
+	dryRun := model.CLIOptions(ctx).DryRun
+
+	var failures []error
+
+	for _, repository := range slices.Sorted(maps.Keys(renames)) {
+		oldName, newName := targetRepositoryName(ctx, repository), targetRepositoryName(ctx, renames[repository])
+		repoLogger := logger.With().Str("name", oldName).Str("newName", newName).Str("target", targetName).Logger()
+
+		oldExists, newExists := existing[strings.ToLower(oldName)], existing[strings.ToLower(newName)]
+
+		// Not missing but renamed, syncrun.onmissing leaves the old name alone also when it can't be renamed
+		existing[strings.ToLower(oldName)] = false
+
+		if !oldExists || newExists {
+			repoLogger.Debug().Msg("Repository renamed at the source, but not found under the old name or already present under the new one at the target")
+
+			continue
+		}
+
+		if dryRun {
+			repoLogger.Info().Msg("Dry run: would rename repository renamed at the source")
+
+			continue
+		}
+
+		if err := provider.Rename(ctx, targetCfg, client, oldName, newName); err != nil {
+			repoLogger.Error().Err(err).Msg("failed to rename repository renamed at the source")
+			failures = append(failures, err)
+
+			continue
+		}
+
+		repoLogger.Info().Msg("Renamed repository renamed at the source")
+
+		existing[strings.ToLower(newName)] = true
+
+		state.move(targetName, repository, renames[repository])
+	}
+
+	if policy != gpsconfig.OnMissingIgnore {
+		failures = append(failures, removeMissing(ctx, sourceCfg, targetName, targetCfg, client, targetInfos, existing, listing, state)...)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w: %d while reconciling %s target: %w", ErrRepositoryFailures, len(failures), targetCfg.ProviderType, errors.Join(failures...))
+	}
+
+	return nil
+}
+
+// removeMissing applies the syncrun.onmissing policy to the target repositories whose source repository is gone.
+func removeMissing(ctx context.Context, sourceCfg gpsconfig.ProviderConfig, targetName string, targetCfg gpsconfig.ProviderConfig, client interfaces.GitProvider, targetInfos []model.ProjectInfo, existing map[string]bool, listing *sourceListing, state *syncState) []error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering removeMissing")
+
+	policy := targetCfg.SyncRun.MissingPolicy()
+	suffix := strings.ToLower(targetCfg.SyncRun.RemovedSuffix())
+
+	atSource := map[string]bool{}
+	for _, projectinfo := range listing.projectinfos {
+		atSource[strings.ToLower(projectinfo.Name(ctx))] = true
+	}
+
+	// The source repository names of the target repositories known from the state file
+	known := map[string]string{}
+	for repository := range state.synced(targetName) {
+		known[strings.ToLower(targetRepositoryName(ctx, repository))] = repository
+	}
+
+	var missing []model.ProjectInfo
+
+	for _, projectinfo := range targetInfos {
+		targetRepoName := strings.ToLower(projectinfo.OriginalName)
+
+		_, isKnown := known[targetRepoName]
+		sourceURL, isMarked := provider.SourceURL(projectinfo.Description)
+
+		switch {
+		case !existing[targetRepoName], atSource[targetRepoName]:
+			continue
+		case !isKnown && (!isMarked || !fromSource(sourceURL, sourceCfg)):
+			continue
+		case policy == gpsconfig.OnMissingArchive && projectinfo.Archived:
+			continue
+		case policy == gpsconfig.OnMissingRename && strings.HasSuffix(targetRepoName, suffix):
+			continue
+		}
+
+		missing = append(missing, projectinfo)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// An empty listing is more likely a wrong token or group than a source without repositories
+	if len(listing.projectinfos) == 0 {
+		logger.Warn().Int("count", len(missing)).Str("target", targetName).
+			Msg("The source lists no repositories at all, not applying syncrun.onmissing")
+
+		return nil
+	}
+
+	var failures []error
+
+	for _, projectinfo := range missing {
+		repoLogger := logger.With().Str("name", projectinfo.OriginalName).Str("target", targetName).Str("policy", policy).Logger()
+
+		if model.CLIOptions(ctx).DryRun {
+			repoLogger.Info().Msg("Dry run: would apply syncrun.onmissing to repository missing at the source")
+
+			continue
+		}
+
+		if err := provider.RemoveMissing(ctx, targetCfg, client, projectinfo.OriginalName); err != nil {
+			repoLogger.Error().Err(err).Msg("failed to apply syncrun.onmissing to repository missing at the source")
+			failures = append(failures, err)
+
+			continue
+		}
+
+		repoLogger.Info().Msg("Applied syncrun.onmissing to repository missing at the source")
+
+		if repository, found := known[strings.ToLower(projectinfo.OriginalName)]; found {
+			state.forget(targetName, repository)
+		}
+	}
+
+	return failures
+}
+
+// renamed returns the repositories synced to the target under another name, by their old name.
+// They are recognized by the project ID the source reported for them, when it is still reported under a new name.
+func (s *syncState) renamed(target string, repositories []interfaces.GitRepository) map[string]string {
+	byID := map[string]string{}
+
+	for _, repo := range repositories {
+		if projectID := repo.ProjectInfo().ProjectID; projectID != "" {
+			byID[projectID] = repo.ProjectInfo().OriginalName
+		}
+	}
+
+	renames := map[string]string{}
+
+	for repository, state := range s.synced(target) {
+		newName, found := byID[state.SourceID]
+		if found && state.SourceID != "" && !strings.EqualFold(newName, repository) {
+			renames[repository] = newName
+		}
+	}
+
+	return renames
+}
+
+// fromSource reports whether a repository URL points to the domain and owner of the source.
+func fromSource(repositoryURL string, sourceCfg gpsconfig.ProviderConfig) bool {
+	owner := sourceCfg.Group
+	if !sourceCfg.IsGroup() {
+		owner = sourceCfg.User
+	}
+
+	repositoryURL = strings.ToLower(repositoryURL)
+	domain := strings.ToLower(sourceCfg.GetDomain())
+	owner = strings.ToLower(owner)
+
+	if domain == "" || owner == "" || !strings.Contains(repositoryURL, domain) {
+		return false
+	}
+
+	return strings.Contains(repositoryURL, "/"+owner+"/") || strings.Contains(repositoryURL, ":"+owner+"/")
+}
+
+// targetRepositoryName returns the name a source repository has at the target.
+func targetRepositoryName(ctx context.Context, repository string) string {
+	return model.ProjectInfo{OriginalName: repository}.Name(ctx)
+}
@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	mocks "itiquette/git-provider-sync/generated/mocks/mockgogit"
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider"
+	"itiquette/git-provider-sync/internal/syncstate"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testReconcileState(t *testing.T) *syncState {
+	t.Helper()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	store, err := syncstate.Load(ctx, path)
+	require.NoError(t, err)
+
+	store.Set(syncstate.Key("config", "target", "old-name"), syncstate.RepositoryState{Result: syncstate.ResultSynced, SourceID: "1"})
+	store.Set(syncstate.Key("config", "target", "kept"), syncstate.RepositoryState{Result: syncstate.ResultSynced, SourceID: "2"})
+	store.Set(syncstate.Key("config", "target", "deleted"), syncstate.RepositoryState{Result: syncstate.ResultSynced, SourceID: "3"})
+	require.NoError(t, store.Save(ctx))
+
+	state, err := loadSyncState(ctx, "config", gpsconfig.ProvidersConfig{
+		SourceProvider:  gpsconfig.ProviderConfig{SyncRun: gpsconfig.SyncRunOption{StateFile: path}},
+		ProviderTargets: map[string]gpsconfig.ProviderConfig{"target": {}},
+	})
+	require.NoError(t, err)
+
+	return state
+}
+
+func TestSyncStateRenamed(t *testing.T) {
+	require := require.New(t)
+	state := testReconcileState(t)
+
+	var repositories []interfaces.GitRepository
+
+	for _, projectinfo := range []model.ProjectInfo{{OriginalName: "new-name", ProjectID: "1"}, {OriginalName: "kept", ProjectID: "2"}, {OriginalName: "added", ProjectID: "4"}} {
+		repo := mocks.NewGitRepository(t)
+		repo.EXPECT().ProjectInfo().Return(projectinfo)
+		repositories = append(repositories, repo)
+	}
+
+	renames := state.renamed("target", repositories)
+	require.Equal(map[string]string{"old-name": "new-name"}, renames)
+	require.Empty(state.renamed("other", repositories))
+
+	state.move("target", "old-name", "new-name")
+
+	synced := state.synced("target")
+	require.Contains(synced, "new-name")
+	require.NotContains(synced, "old-name")
+	require.Equal("1", synced["new-name"].SourceID)
+
+	var disabled *syncState
+	require.Empty(disabled.renamed("target", repositories))
+}
+
+func TestRemoveMissing(t *testing.T) {
+	ctx := model.WithCLIOption(context.Background(), model.CLIOption{})
+	sourceCfg := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, Domain: "gitlab.com", Group: "source"}
+
+	targetInfos := []model.ProjectInfo{
+		{OriginalName: "kept", Description: provider.DescriptionMarker + "https://gitlab.com/source/kept.git: "},
+		{OriginalName: "deleted"},
+		{OriginalName: "marked", Description: provider.DescriptionMarker + "https://gitlab.com/source/marked.git: "},
+		{OriginalName: "other-source", Description: provider.DescriptionMarker + "https://gitlab.com/other/other-source.git: "},
+		{OriginalName: "unmanaged", Description: "Created by hand"},
+		{OriginalName: "archived", Archived: true, Description: provider.DescriptionMarker + "https://gitlab.com/source/archived.git: "},
+		{OriginalName: "marked-removed", Description: provider.DescriptionMarker + "https://gitlab.com/source/marked.git: "},
+	}
+
+	tests := []struct {
+		name       string
+		policy     string
+		listing    []model.ProjectInfo
+		setupMocks func(*mocks.GitProvider)
+		wantForget bool
+	}{
+		{
+			name:    "delete",
+			policy:  gpsconfig.OnMissingDelete,
+			listing: []model.ProjectInfo{{OriginalName: "kept"}},
+			setupMocks: func(client *mocks.GitProvider) {
+				for _, name := range []string{"deleted", "marked", "archived", "marked-removed"} {
+					client.EXPECT().DeleteProject(mock.Anything, "target", name).Return(nil)
+				}
+			},
+			wantForget: true,
+		},
+		{
+			name:    "archive skips archived",
+			policy:  gpsconfig.OnMissingArchive,
+			listing: []model.ProjectInfo{{OriginalName: "kept"}},
+			setupMocks: func(client *mocks.GitProvider) {
+				for _, name := range []string{"deleted", "marked", "marked-removed"} {
+					client.EXPECT().ArchiveProject(mock.Anything, "target", name).Return(nil)
+				}
+			},
+			wantForget: true,
+		},
+		{
+			name:    "rename skips renamed",
+			policy:  gpsconfig.OnMissingRename,
+			listing: []model.ProjectInfo{{OriginalName: "kept"}},
+			setupMocks: func(client *mocks.GitProvider) {
+				for _, name := range []string{"deleted", "marked", "archived"} {
+					client.EXPECT().RenameProject(mock.Anything, "target", name, name+"-removed").Return(nil)
+				}
+			},
+			wantForget: true,
+		},
+		{
+			name:       "empty source listing",
+			policy:     gpsconfig.OnMissingDelete,
+			listing:    nil,
+			setupMocks: func(_ *mocks.GitProvider) {},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+			state := testReconcileState(t)
+
+			client := mocks.NewGitProvider(t)
+			tabletest.setupMocks(client)
+
+			targetCfg := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, Group: "target", SyncRun: gpsconfig.SyncRunOption{OnMissing: tabletest.policy}}
+
+			existing := map[string]bool{}
+			for _, projectinfo := range targetInfos {
+				existing[projectinfo.OriginalName] = true
+			}
+
+			failures := removeMissing(ctx, sourceCfg, "target", targetCfg, client, targetInfos, existing, &sourceListing{projectinfos: tabletest.listing}, state)
+			require.Empty(failures)
+
+			_, found := state.synced("target")["deleted"]
+			require.Equal(!tabletest.wantForget, found)
+		})
+	}
+}
+
+func TestFromSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		sourceCfg gpsconfig.ProviderConfig
+		want      bool
+	}{
+		{"https group", "https://gitlab.com/source/repo.git", gpsconfig.ProviderConfig{Domain: "gitlab.com", Group: "source"}, true},
+		{"ssh user", "git@github.com:someone/repo.git", gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITHUB, User: "someone"}, true},
+		{"other owner", "https://gitlab.com/other/repo.git", gpsconfig.ProviderConfig{Domain: "gitlab.com", Group: "source"}, false},
+		{"other domain", "https://example.com/source/repo.git", gpsconfig.ProviderConfig{Domain: "gitlab.com", Group: "source"}, false},
+		{"no owner", "https://gitlab.com/source/repo.git", gpsconfig.ProviderConfig{Domain: "gitlab.com"}, false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, fromSource(tabletest.url, tabletest.sourceCfg))
+		})
+	}
+}
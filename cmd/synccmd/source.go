@@ -8,6 +8,7 @@ package synccmd
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
@@ -19,20 +20,20 @@ import (
 	"itiquette/git-provider-sync/internal/target/gitlib"
 )
 
-func sourceRepositories(ctx context.Context, sourceCfg gpsconfig.ProviderConfig) ([]interfaces.GitRepository, error) {
+func sourceRepositories(ctx context.Context, name string, config gpsconfig.ProvidersConfig, listing *sourceListing, state *syncState) ([]interfaces.GitRepository, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering sourceRepositories")
 
-	providerClient, err := createProviderClient(ctx, sourceCfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create provider client: %w", err)
-	}
+	sourceCfg := config.SourceProvider
+	providerClient := listing.client
 
-	metainfo, err := provider.FetchProjectInfo(ctx, sourceCfg, providerClient)
+	metainfo, err := listing.synced(ctx, sourceCfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repository metainfo for %s: %w", sourceCfg.ProviderType, err)
+		return nil, err
 	}
 
+	metainfo = provider.WithWikis(sourceCfg, metainfo)
+
 	if model.CLIOptions(ctx).DryRun {
 		logDryRun(ctx, sourceCfg, metainfo)
 
@@ -44,14 +45,43 @@ func sourceRepositories(ctx context.Context, sourceCfg gpsconfig.ProviderConfig)
 		return nil, fmt.Errorf("get source reader: %w", err)
 	}
 
-	repositories, err := provider.Clone(ctx, reader, sourceCfg, metainfo)
+	metainfo = pendingProjectInfos(ctx, name, config, metainfo)
+
+	metainfo, err = state.changed(ctx, reader, sourceCfg, metainfo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirrors are updated between the providers, nothing is routed through here
+	if mirrorsOnly(config, providerClient) {
+		return provider.Listed(ctx, sourceCfg, metainfo), nil
+	}
+
+	meta := model.NewSyncRunMetainfo(0, sourceCfg.GetDomain(), "", len(metainfo))
+	ctx = context.WithValue(ctx, model.SyncRunMetainfoKey{}, meta)
+
+	repositories, err := provider.Clone(ctx, reader, sourceCfg, metainfo, sourceCfg.SyncRun.Concurrency)
 	if err != nil {
 		return nil, fmt.Errorf("clone repositories: %w", err)
 	}
 
+	// Failed clones are left out, the repositories that could be cloned are still synced
+	if failures := meta.FailureCount(); failures > 0 {
+		logFailures(logger, meta)
+
+		return repositories, fmt.Errorf("%w: %d of %d could not be cloned", ErrRepositoryFailures, failures, len(metainfo))
+	}
+
 	return repositories, nil
 }
 
+// releaseRepositories releases the cloned repositories once they are synced to all targets.
+func releaseRepositories(repositories []interfaces.GitRepository) {
+	for _, repository := range repositories {
+		repository.Release()
+	}
+}
+
 func getSourceReader(cfg gpsconfig.ProviderConfig) (interfaces.SourceReader, error) {
 	if !cfg.Git.UseGitBinary {
 		return gitlib.NewService(), nil
@@ -65,7 +95,7 @@ func getSourceReader(cfg gpsconfig.ProviderConfig) (interfaces.SourceReader, err
 	return reader, nil
 }
 
-func processRepository(ctx context.Context, targetCfg gpsconfig.ProviderConfig, client interfaces.GitProvider, repo interfaces.GitRepository, sourceCfg gpsconfig.ProviderConfig) error {
+func processRepository(ctx context.Context, targetCfg gpsconfig.ProviderConfig, client, sourceClient interfaces.GitProvider, repo interfaces.GitRepository, sourceCfg gpsconfig.ProviderConfig) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering processRepository")
 	repo.ProjectInfo().DebugLog(logger).Msg("processRepository")
@@ -78,12 +108,27 @@ func processRepository(ctx context.Context, targetCfg gpsconfig.ProviderConfig,
 		return err
 	}
 
-	if err := prepareRepository(ctx, targetCfg, repo); err != nil {
-		return fmt.Errorf("failed to prepare repository: %w", err)
+	switch {
+	case targetCfg.SyncRun.ProviderMirror():
+		if err := mirrorRepository(ctx, sourceCfg, targetCfg, client, repo); err != nil {
+			return fmt.Errorf("failed to mirror repository: %w", err)
+		}
+	case pushMirrored(targetCfg, sourceClient):
+		if err := pushMirrorRepository(ctx, sourceCfg, targetCfg, client, sourceClient, repo); err != nil {
+			return fmt.Errorf("failed to push mirror repository: %w", err)
+		}
+	default:
+		if err := prepareRepository(ctx, targetCfg, repo); err != nil {
+			return fmt.Errorf("failed to prepare repository: %w", err)
+		}
+
+		if err := pushRepository(ctx, sourceCfg, targetCfg, client, sourceClient, repo); err != nil {
+			return fmt.Errorf("failed to push repository: %w", err)
+		}
 	}
 
-	if err := pushRepository(ctx, sourceCfg, targetCfg, client, repo); err != nil {
-		return fmt.Errorf("failed to push repository: %w", err)
+	if err := syncReleasesAndMetadata(ctx, targetCfg, client, sourceClient, repo, sourceCfg); err != nil {
+		return err
 	}
 
 	if targetCfg.ProviderType == gpsconfig.DIRECTORY {
@@ -91,7 +136,9 @@ func processRepository(ctx context.Context, targetCfg gpsconfig.ProviderConfig,
 		storageHandler := directory.NewStorageHandler()
 		dirService := directory.NewService(gitHandler, storageHandler)
 
-		if err := dirService.Pull(ctx, sourceCfg, targetCfg.DirectoryTargetDir(), repo); err != nil {
+		prune := model.CLIOptions(ctx).Prune || targetCfg.SyncRun.Prune
+
+		if err := dirService.Pull(ctx, sourceCfg, targetCfg, repo, prune); err != nil {
 			return fmt.Errorf("failed to pull repository for directory target: %w", err)
 		}
 	}
@@ -99,22 +146,36 @@ func processRepository(ctx context.Context, targetCfg gpsconfig.ProviderConfig,
 	return nil
 }
 
+// syncReleasesAndMetadata syncs the releases and metadata of a repository, after it was pushed or found unchanged.
+func syncReleasesAndMetadata(ctx context.Context, targetCfg gpsconfig.ProviderConfig, client, sourceClient interfaces.GitProvider, repo interfaces.GitRepository, sourceCfg gpsconfig.ProviderConfig) error {
+	if err := syncReleases(ctx, sourceCfg, targetCfg, client, sourceClient, repo); err != nil {
+		return fmt.Errorf("failed to sync releases: %w", err)
+	}
+
+	if err := syncMetadata(ctx, sourceCfg, targetCfg, client, sourceClient, repo); err != nil {
+		return fmt.Errorf("failed to sync metadata: %w", err)
+	}
+
+	return nil
+}
+
 func validateRepository(ctx context.Context, client interfaces.GitProvider, repo interfaces.GitRepository, targetCfg gpsconfig.ProviderConfig) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering validateRepository")
 
-	if client.IsValidProjectName(ctx, repo.ProjectInfo().Name(ctx)) {
+	if validProjectPath(ctx, client, targetCfg, repo.ProjectInfo().Name(ctx)) {
 		return nil
 	}
 
 	name := repo.ProjectInfo().OriginalName
-	markRepositoryInvalid(ctx, name)
 
 	opts := model.CLIOptions(ctx)
 	if !opts.IgnoreInvalidName && !targetCfg.SyncRun.IgnoreInvalidName {
 		return fmt.Errorf("%w: %s", ErrInvalidRepoName, name)
 	}
 
+	markRepositoryInvalid(ctx, name)
+
 	log.Logger(ctx).Debug().
 		Str("name", name).
 		Bool("ignoreInvalidName", opts.IgnoreInvalidName).
@@ -123,9 +184,26 @@ func validateRepository(ctx context.Context, client interfaces.GitProvider, repo
 	return nil
 }
 
+// validProjectPath reports whether the repository name is valid at the target. A repository in a subgroup
+// is named by its path at a target recreating the subgroups, each subgroup is to be a valid name too.
+// At any other target a name with a / in it, as project.nametemplate can give, is not valid.
+func validProjectPath(ctx context.Context, client interfaces.GitProvider, targetCfg gpsconfig.ProviderConfig, name string) bool {
+	if !targetCfg.Repositories.IncludeSubgroups && strings.Contains(name, "/") {
+		return false
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if !client.IsValidProjectName(ctx, segment) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func markRepositoryInvalid(ctx context.Context, repoName string) {
-	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(model.SyncRunMetainfo); ok {
-		meta.Fail["invalid"] = append(meta.Fail["invalid"], repoName)
+	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(*model.SyncRunMetainfo); ok {
+		meta.AddFailure("invalid", repoName)
 	}
 }
 
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// state.go - Skipping repositories unchanged since the last sync
+package synccmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/syncstate"
+	"itiquette/git-provider-sync/internal/workerpool"
+)
+
+// syncState tracks the repositories of one configuration against its state file.
+// A configuration without a state file has a nil *syncState, and every method then
+// treats all repositories as changed.
+// The source states are only written by changed, before the repositories are synced concurrently.
+type syncState struct {
+	store         *syncstate.Store
+	configuration string
+	targets       []string
+	sources       map[string]sourceState
+	skipped       []model.ProjectInfo
+}
+
+// sourceState is what the source reported for a repository in this run.
+type sourceState struct {
+	refs           map[string]string
+	lastActivityAt *time.Time
+	projectID      string
+}
+
+func loadSyncState(ctx context.Context, name string, config gpsconfig.ProvidersConfig) (*syncState, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering loadSyncState")
+
+	path := config.SourceProvider.SyncRun.StateFile
+	if path == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	store, err := syncstate.Load(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("load state file: %w", err)
+	}
+
+	return &syncState{
+		store:         store,
+		configuration: name,
+		targets:       slices.Sorted(maps.Keys(config.ProviderTargets)),
+		sources:       map[string]sourceState{},
+	}, nil
+}
+
+// changed lists the references of every repository at the source, and returns the ones that changed
+// since they were last synced to at least one target. The others need not be cloned at all.
+// A repository whose references can't be listed is compared by its last activity time instead.
+func (s *syncState) changed(ctx context.Context, reader interfaces.SourceReader, sourceCfg gpsconfig.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering changed")
+
+	if s == nil {
+		return projectinfos, nil
+	}
+
+	sources := make([]sourceState, len(projectinfos))
+
+	err := workerpool.Run(ctx, max(sourceCfg.SyncRun.Concurrency, 1), len(projectinfos), func(ctx context.Context, index int) error {
+		projectinfo := projectinfos[index]
+		ctx = log.WithRepository(ctx, projectinfo.OriginalName)
+
+		option := model.CloneOption{
+			Name:       projectinfo.OriginalName,
+			URL:        model.CloneURL(projectinfo, sourceCfg),
+			Git:        sourceCfg.Git,
+			HTTPClient: sourceCfg.HTTPClient,
+			SSHClient:  sourceCfg.SSHClient,
+		}
+
+		refs, err := reader.RemoteRefs(ctx, option)
+		if err != nil {
+			log.Logger(ctx).Debug().Err(err).Msg("could not list source references, comparing last activity instead")
+
+			refs = nil
+		}
+
+		sources[index] = sourceState{refs: refs, lastActivityAt: projectinfo.LastActivityAt, projectID: projectinfo.ProjectID}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compare source references: %w", err)
+	}
+
+	var result []model.ProjectInfo
+
+	for index, projectinfo := range projectinfos {
+		s.sources[projectinfo.OriginalName] = sources[index]
+
+		changed := slices.ContainsFunc(s.targets, func(target string) bool {
+			return !s.unchangedFor(target, projectinfo.OriginalName)
+		})
+		if changed {
+			result = append(result, projectinfo)
+
+			continue
+		}
+
+		s.skipped = append(s.skipped, projectinfo)
+	}
+
+	if len(s.skipped) > 0 {
+		logger.Info().Int("count", len(s.skipped)).Msg("Skipping repositories unchanged since the last sync")
+	}
+
+	return result, nil
+}
+
+// unchangedFor reports whether the repository is unchanged since it was last synced to the target.
+func (s *syncState) unchangedFor(target, repository string) bool {
+	if s == nil {
+		return false
+	}
+
+	source, found := s.sources[repository]
+	if !found {
+		return false
+	}
+
+	state, found := s.store.Get(syncstate.Key(s.configuration, target, repository))
+
+	return found && state.Unchanged(source.refs, source.lastActivityAt)
+}
+
+// skippedRepositories returns the repositories that were not cloned, as they are unchanged for every target.
+// Only their push is skipped, their releases and metadata are still synced, as the references don't tell whether those changed.
+func (s *syncState) skippedRepositories() []model.ProjectInfo {
+	if s == nil {
+		return nil
+	}
+
+	return s.skipped
+}
+
+// record stores the result of syncing a repository to the target, along with the source state it was synced from.
+func (s *syncState) record(target, repository, result string) {
+	if s == nil {
+		return
+	}
+
+	source := s.sources[repository]
+
+	s.store.Set(syncstate.Key(s.configuration, target, repository), syncstate.RepositoryState{
+		Refs:           source.refs,
+		LastActivityAt: source.lastActivityAt,
+		LastSync:       time.Now().UTC(),
+		Result:         result,
+		SourceID:       source.projectID,
+	})
+}
+
+// synced returns the states of the repositories synced to the target in this or earlier runs, by source repository name.
+func (s *syncState) synced(target string) map[string]syncstate.RepositoryState {
+	if s == nil {
+		return nil
+	}
+
+	return s.store.Repositories(s.configuration, target)
+}
+
+// move keeps the state of a repository synced to the target under its new name, after it was renamed at the source.
+func (s *syncState) move(target, repository, newName string) {
+	if s == nil {
+		return
+	}
+
+	state, found := s.store.Get(syncstate.Key(s.configuration, target, repository))
+	if !found {
+		return
+	}
+
+	s.store.Delete(syncstate.Key(s.configuration, target, repository))
+	s.store.Set(syncstate.Key(s.configuration, target, newName), state)
+}
+
+// forget drops the state of a repository no longer synced to the target.
+func (s *syncState) forget(target, repository string) {
+	if s == nil {
+		return
+	}
+
+	s.store.Delete(syncstate.Key(s.configuration, target, repository))
+}
+
+func (s *syncState) save(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+
+	if err := s.store.Save(ctx); err != nil {
+		return fmt.Errorf("save state file: %w", err)
+	}
+
+	return nil
+}
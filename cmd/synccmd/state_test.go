@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	mocks "itiquette/git-provider-sync/generated/mocks/mockgogit"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/syncstate"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncStateChanged(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	refs := map[string]string{"refs/heads/main": "abc"}
+
+	store, err := syncstate.Load(ctx, path)
+	require.NoError(err)
+
+	synced := syncstate.RepositoryState{Refs: refs, Result: syncstate.ResultSynced}
+	store.Set(syncstate.Key("config", "first", "unchanged"), synced)
+	store.Set(syncstate.Key("config", "second", "unchanged"), synced)
+	store.Set(syncstate.Key("config", "first", "partly"), synced)
+	store.Set(syncstate.Key("config", "first", "moved"), synced)
+	store.Set(syncstate.Key("config", "second", "moved"), synced)
+	require.NoError(store.Save(ctx))
+
+	config := gpsconfig.ProvidersConfig{
+		SourceProvider:  gpsconfig.ProviderConfig{SyncRun: gpsconfig.SyncRunOption{StateFile: path}},
+		ProviderTargets: map[string]gpsconfig.ProviderConfig{"first": {}, "second": {}},
+	}
+
+	state, err := loadSyncState(ctx, "config", config)
+	require.NoError(err)
+
+	reader := mocks.NewSourceReader(t)
+	reader.EXPECT().RemoteRefs(mock.Anything, mock.MatchedBy(func(option model.CloneOption) bool {
+		return option.Name != "moved" && option.Name != "unlisted"
+	})).Return(refs, nil)
+	reader.EXPECT().RemoteRefs(mock.Anything, mock.MatchedBy(func(option model.CloneOption) bool {
+		return option.Name == "moved"
+	})).Return(map[string]string{"refs/heads/main": "def"}, nil)
+	reader.EXPECT().RemoteRefs(mock.Anything, mock.MatchedBy(func(option model.CloneOption) bool {
+		return option.Name == "unlisted"
+	})).Return(nil, errors.New("ls-remote failed"))
+
+	projectinfos := []model.ProjectInfo{
+		{OriginalName: "unchanged"},
+		{OriginalName: "partly"},
+		{OriginalName: "moved"},
+		{OriginalName: "unlisted"},
+	}
+
+	changed, err := state.changed(ctx, reader, config.SourceProvider, projectinfos)
+	require.NoError(err)
+	require.Equal(projectinfos[1:], changed)
+	require.Equal(projectinfos[:1], state.skippedRepositories())
+
+	require.True(state.unchangedFor("first", "partly"))
+	require.False(state.unchangedFor("second", "partly"))
+	require.False(state.unchangedFor("first", "moved"))
+
+	state.record("second", "partly", syncstate.ResultSynced)
+	state.record("first", "moved", model.FailPush)
+	require.NoError(state.save(ctx))
+
+	reloaded, err := loadSyncState(ctx, "config", config)
+	require.NoError(err)
+
+	changed, err = reloaded.changed(ctx, reader, config.SourceProvider, projectinfos)
+	require.NoError(err)
+	require.Equal(projectinfos[2:], changed)
+}
+
+func TestSyncStateDisabled(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	state, err := loadSyncState(ctx, "config", gpsconfig.ProvidersConfig{})
+	require.NoError(err)
+	require.Nil(state)
+
+	projectinfos := []model.ProjectInfo{{OriginalName: "repo"}}
+
+	changed, err := state.changed(ctx, mocks.NewSourceReader(t), gpsconfig.ProviderConfig{}, projectinfos)
+	require.NoError(err)
+	require.Equal(projectinfos, changed)
+	require.Empty(state.skippedRepositories())
+	require.False(state.unchangedFor("target", "repo"))
+	require.NoError(state.save(ctx))
+}
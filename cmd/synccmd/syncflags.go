@@ -19,6 +19,10 @@ type syncFlags struct {
 	cleanupName       bool
 	activeFromLimit   string
 	dryRun            bool
+	continueOnError   bool
+	resume            bool
+	journalFile       string
+	prune             bool
 }
 
 func addSyncFlags(cmd *cobra.Command) {
@@ -28,6 +32,10 @@ func addSyncFlags(cmd *cobra.Command) {
 	flags.Bool("cleanup-name", false, "Remove non-alphanumeric characters from repository names")
 	flags.String("active-from-limit", "", "A negative time duration (e.g., '-1h') to consider repositories active from")
 	flags.Bool("dry-run", false, "Simulate sync run without performing clone and push actions")
+	flags.Bool("continue-on-error", false, "Record failing repositories and continue, exit with an error when done")
+	flags.Bool("resume", false, "Resume an interrupted run with the repositories it did not complete, or start a new run")
+	flags.String("journal-file", "", "Path to the run journal used to resume (default: user cache dir/gitprovidersync/journal.json)")
+	flags.Bool("prune", false, "Remove branches and tags from targets that no longer exist at the source")
 }
 
 func (syn syncFlags) DebugLog(logger *zerolog.Logger) *zerolog.Event {
@@ -36,7 +44,11 @@ func (syn syncFlags) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 				Bool("ignoreInvalidName", syn.ignoreInvalidName).
 				Bool("cleanupName", syn.cleanupName).
 				Str("activeFromLimit", syn.activeFromLimit).
-				Bool("dryRun", syn.dryRun)
+				Bool("dryRun", syn.dryRun).
+				Bool("continueOnError", syn.continueOnError).
+				Bool("resume", syn.resume).
+				Str("journalFile", syn.journalFile).
+				Bool("prune", syn.prune)
 }
 
 func getSyncFlags(_ context.Context, cmd *cobra.Command) (*syncFlags, error) {
@@ -64,5 +76,21 @@ func getSyncFlags(_ context.Context, cmd *cobra.Command) (*syncFlags, error) {
 		return nil, fmt.Errorf("get dry-run flag: %w", err)
 	}
 
+	if flags.continueOnError, err = cmd.Flags().GetBool("continue-on-error"); err != nil {
+		return nil, fmt.Errorf("get continue-on-error flag: %w", err)
+	}
+
+	if flags.resume, err = cmd.Flags().GetBool("resume"); err != nil {
+		return nil, fmt.Errorf("get resume flag: %w", err)
+	}
+
+	if flags.journalFile, err = cmd.Flags().GetString("journal-file"); err != nil {
+		return nil, fmt.Errorf("get journal-file flag: %w", err)
+	}
+
+	if flags.prune, err = cmd.Flags().GetBool("prune"); err != nil {
+		return nil, fmt.Errorf("get prune flag: %w", err)
+	}
+
 	return flags, nil
 }
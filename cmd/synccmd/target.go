@@ -7,7 +7,10 @@ package synccmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"itiquette/git-provider-sync/internal/interfaces"
@@ -15,46 +18,341 @@ import (
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider"
+	"itiquette/git-provider-sync/internal/syncstate"
 	"itiquette/git-provider-sync/internal/target/archive"
 	"itiquette/git-provider-sync/internal/target/directory"
 	"itiquette/git-provider-sync/internal/target/gitbinary"
 	"itiquette/git-provider-sync/internal/target/gitlib"
+	"itiquette/git-provider-sync/internal/workerpool"
 )
 
-func toTarget(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, repositories []interfaces.GitRepository) error {
+func toTarget(ctx context.Context, name string, sourceCfg gpsconfig.ProviderConfig, targetName string, targetCfg gpsconfig.ProviderConfig, repositories []interfaces.GitRepository, state *syncState) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering toTarget")
 	targetCfg.DebugLog(logger)
 
-	ctx = initTargetSync(ctx, sourceCfg, targetCfg, repositories)
+	// Unchanged for every target, so not cloned, but their releases and metadata are synced too
+	repositories = slices.Concat(repositories, provider.Listed(ctx, sourceCfg, state.skippedRepositories()))
+	ctx, meta := initTargetSync(ctx, sourceCfg, targetCfg, len(repositories))
+
+	continueOnError := model.CLIOptions(ctx).ContinueOnError || targetCfg.SyncRun.ContinueOnError || sourceCfg.SyncRun.ContinueOnError
+
+	sourceClient, err := sourceAPIClient(ctx, sourceCfg, targetCfg)
+	if err != nil {
+		return fmt.Errorf("create source provider client: %w", err)
+	}
 
 	client, err := createProviderClient(ctx, targetCfg)
 	if err != nil {
 		return fmt.Errorf("create target provider client: %w", err)
 	}
 
-	for _, repo := range repositories {
-		if err := processRepository(ctx, targetCfg, client, repo, sourceCfg); err != nil {
-			return fmt.Errorf("process repository: %w", err)
+	if err := validateNameTemplate(ctx, client, targetCfg); err != nil {
+		return err
+	}
+
+	if targetCfg.SyncRun.SourceMirror() && !sourceClient.SupportsPushMirror() {
+		logger.Warn().Str("provider", sourceCfg.ProviderType).Msg("Source provider has no push mirrors, pushing the repositories instead")
+	}
+
+	concurrency := targetConcurrency(sourceCfg, targetCfg)
+	logger.Debug().Int("concurrency", concurrency).Msg("toTarget")
+
+	process := func(ctx context.Context, repo interfaces.GitRepository) error {
+		repoName := repo.ProjectInfo().OriginalName
+		ctx = log.WithRepository(ctx, repoName)
+
+		// Cloned for another target, but already synced to this one, before an interruption or in an earlier run
+		if stepCompleted(ctx, name, targetName, repoName) {
+			meta.AddFailure("resumed", repoName)
+
+			return nil
 		}
+
+		unchanged := state.unchangedFor(targetName, repoName)
+		sync := processRepository
+
+		// The references are up to date, the releases and metadata may not be
+		if unchanged {
+			sync = syncReleasesAndMetadata
+		}
+
+		if err := sync(ctx, targetCfg, client, sourceClient, repo, sourceCfg); err != nil {
+			err = fmt.Errorf("process repository %s: %w", repoName, err)
+			category := failureCategory(err)
+			state.record(targetName, repoName, category)
+
+			if !continueOnError {
+				return err
+			}
+
+			model.AddRepositoryFailure(ctx, category, repoName, err)
+
+			return nil
+		}
+
+		if unchanged {
+			meta.AddFailure("uptodate", repoName)
+
+			return nil
+		}
+
+		state.record(targetName, repoName, syncstate.ResultSynced)
+		completeStep(ctx, name, targetName, repoName)
+		incrementSyncCount(ctx)
+
+		return nil
+	}
+
+	// A wiki is pushed to the wiki of its repository, so only once all repositories are synced
+	for _, batch := range splitWikis(repositories) {
+		err = workerpool.Run(ctx, concurrency, len(batch), func(ctx context.Context, index int) error {
+			return process(ctx, batch[index])
+		})
+		if err != nil {
+			break
+		}
+	}
+
+	// Keep what was synced, also when the run was stopped by a failing repository
+	if saveErr := state.save(ctx); saveErr != nil {
+		err = errors.Join(err, saveErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("process repositories: %w", err)
 	}
 
 	summary(ctx, sourceCfg)
 
+	if failures := meta.FailureCount(); failures > 0 {
+		return fmt.Errorf("%w: %d of %d to %s target", ErrRepositoryFailures, failures, len(repositories), targetCfg.ProviderType)
+	}
+
+	return nil
+}
+
+// splitWikis splits the repositories in the repositories themselves, and their wiki repositories, keeping their order.
+func splitWikis(repositories []interfaces.GitRepository) [2][]interfaces.GitRepository {
+	var split [2][]interfaces.GitRepository
+
+	for _, repo := range repositories {
+		if repo.ProjectInfo().IsWiki() {
+			split[1] = append(split[1], repo)
+		} else {
+			split[0] = append(split[0], repo)
+		}
+	}
+
+	return split
+}
+
+// failureCategory tells in which step of the sync a repository failed.
+func failureCategory(err error) string {
+	switch {
+	case errors.Is(err, ErrInvalidRepoName):
+		return model.FailName
+	case errors.Is(err, provider.ErrRepositoryLookup), errors.Is(err, provider.ErrCreateRepository), errors.Is(err, provider.ErrEnableWiki):
+		return model.FailCreate
+	case errors.Is(err, provider.ErrProtectRepository):
+		return model.FailProtect
+	case errors.Is(err, provider.ErrDefaultBranch):
+		return model.FailDefaultBranch
+	case errors.Is(err, provider.ErrMirrorRepository):
+		return model.FailMirror
+	case errors.Is(err, provider.ErrSyncRelease):
+		return model.FailRelease
+	case errors.Is(err, provider.ErrSyncMetadata):
+		return model.FailMetadata
+	default:
+		return model.FailPush
+	}
+}
+
+// withSubgroups prepares syncing to the target when the repositories of the subgroups of the source are included,
+// which are named by their path in the group. A GitLab group recreates the subgroups, and has the repositories
+// in its own subgroups listed too. Other targets, and GitLab targets with project.subgroupseparator set,
+// have the path joined with the subgroup separator.
+func withSubgroups(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig) (context.Context, gpsconfig.ProviderConfig) {
+	if !sourceCfg.Repositories.IncludeSubgroups {
+		return ctx, targetCfg
+	}
+
+	separator := targetCfg.Project.SubgroupSeparator
+	if separator == "" && strings.EqualFold(targetCfg.ProviderType, gpsconfig.GITLAB) && targetCfg.IsGroup() {
+		targetCfg.Repositories.IncludeSubgroups = true
+
+		return ctx, targetCfg
+	}
+
+	if separator == "" {
+		separator = gpsconfig.DefaultSubgroupSeparator
+	}
+
+	return model.WithSubgroupSeparator(ctx, separator), targetCfg
+}
+
+// withNameTemplate returns the context the repositories are named in at the target, with project.nametemplate when it has one.
+func withNameTemplate(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig) context.Context {
+	if targetCfg.Project.NameTemplate == "" {
+		return ctx
+	}
+
+	// Parsed when the configuration was validated
+	tmpl, err := model.ParseNameTemplate(targetCfg.Project.NameTemplate)
+	if err != nil {
+		log.Logger(ctx).Warn().Err(err).Msg("Ignoring project.nametemplate")
+
+		return ctx
+	}
+
+	// A nested group is a path, which would be taken for subgroups in the name
+	return model.WithNameTemplate(ctx, model.NameTemplate{
+		Template:     tmpl,
+		Owner:        strings.ReplaceAll(sourceCfg.Group+sourceCfg.User, "/", "-"),
+		SourceDomain: sourceCfg.GetDomain(),
+		ProviderType: sourceCfg.ProviderType,
+	})
+}
+
+// validateNameTemplate checks that project.nametemplate gives a name the target accepts, before any repository is synced.
+// The name of each repository is checked again before it is pushed, as the template can give them names the target doesn't accept.
+func validateNameTemplate(ctx context.Context, client interfaces.GitProvider, targetCfg gpsconfig.ProviderConfig) error {
+	if targetCfg.Project.NameTemplate == "" {
+		return nil
+	}
+
+	if name := (model.ProjectInfo{OriginalName: "repository"}).Name(ctx); !validProjectPath(ctx, client, targetCfg, name) {
+		return fmt.Errorf("%w: project.nametemplate gives %s, which is not valid at %s", ErrInvalidRepoName, name, targetCfg.ProviderType)
+	}
+
 	return nil
 }
 
-func pushRepository(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, client interfaces.GitProvider, repo interfaces.GitRepository) error {
+// targetConcurrency returns how many repositories are pushed to the target at the same time.
+// A target without its own setting uses the one of its configuration, set on the source.
+func targetConcurrency(sourceCfg, targetCfg gpsconfig.ProviderConfig) int {
+	if targetCfg.SyncRun.Concurrency > 0 {
+		return targetCfg.SyncRun.Concurrency
+	}
+
+	return max(sourceCfg.SyncRun.Concurrency, 1)
+}
+
+// sourceAPIClient returns the client releases and metadata are read from, and push mirrors are set up with,
+// or nil when none of them is needed.
+func sourceAPIClient(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig) (interfaces.GitProvider, error) {
+	if !sourceCfg.Repositories.IncludeReleases && !sourceCfg.Repositories.IncludeMetadata && !targetCfg.SyncRun.SourceMirror() {
+		return nil, nil //nolint:nilnil
+	}
+
+	return createProviderClient(ctx, sourceCfg)
+}
+
+func pushRepository(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, client, sourceClient interfaces.GitProvider, repo interfaces.GitRepository) error {
 	writer, err := getTargetWriter(targetCfg)
 	if err != nil {
 		return fmt.Errorf("get target writer: %w", err)
 	}
 
+	// An archive keeps the releases and metadata in the tarball, next to the repository
+	if archiveWriter, ok := writer.(*archive.Service); ok {
+		if sourceCfg.Repositories.IncludeReleases {
+			archiveWriter.WithExporters(func(ctx context.Context, dir string) error {
+				return provider.ExportReleases(ctx, sourceCfg, sourceClient, repo, dir) //nolint:wrapcheck
+			})
+		}
+
+		if sourceCfg.Repositories.IncludeMetadata {
+			archiveWriter.WithExporters(func(ctx context.Context, dir string) error {
+				return provider.ExportMetadata(ctx, sourceCfg, sourceClient, repo, filepath.Join(dir, provider.MetadataDir)) //nolint:wrapcheck
+			})
+		}
+	}
+
 	if err := provider.Push(ctx, targetCfg, client, writer, repo, sourceCfg); err != nil {
 		return fmt.Errorf("push to target: %w", err)
 	}
 
-	incrementSyncCount(ctx)
+	return nil
+}
+
+// mirrorRepository has a provider target mirror the repository itself, instead of it being pushed.
+func mirrorRepository(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, client interfaces.GitProvider, repo interfaces.GitRepository) error {
+	if err := provider.Mirror(ctx, targetCfg, client, repo, sourceCfg); err != nil {
+		return fmt.Errorf("mirror at target: %w", err)
+	}
+
+	return nil
+}
+
+// pushMirrorRepository has the source provider push mirror the repository to the target, instead of it being pushed.
+func pushMirrorRepository(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, client, sourceClient interfaces.GitProvider, repo interfaces.GitRepository) error {
+	if err := provider.PushMirror(ctx, targetCfg, client, repo, sourceCfg, sourceClient); err != nil {
+		return fmt.Errorf("mirror at target: %w", err)
+	}
+
+	return nil
+}
+
+// pushMirrored reports whether the source provider push mirrors the repositories to the target.
+// A source provider without push mirrors has them cloned and pushed instead.
+func pushMirrored(targetCfg gpsconfig.ProviderConfig, sourceClient interfaces.GitProvider) bool {
+	return targetCfg.SyncRun.SourceMirror() && sourceClient != nil && sourceClient.SupportsPushMirror()
+}
+
+// mirrorsOnly reports whether every target is a mirror of the source, so none of the repositories need to be cloned.
+func mirrorsOnly(config gpsconfig.ProvidersConfig, sourceClient interfaces.GitProvider) bool {
+	for _, targetCfg := range config.ProviderTargets {
+		if !targetCfg.SyncRun.ProviderMirror() && !pushMirrored(targetCfg, sourceClient) {
+			return false
+		}
+	}
+
+	return len(config.ProviderTargets) > 0
+}
+
+// syncReleases creates and updates the releases of a pushed repository at a provider target.
+// A mirror is updated in the background, the tags of the releases may not be there yet.
+func syncReleases(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, client, sourceClient interfaces.GitProvider, repo interfaces.GitRepository) error {
+	if !sourceCfg.Repositories.IncludeReleases || targetCfg.SyncRun.ProviderMirror() || pushMirrored(targetCfg, sourceClient) {
+		return nil
+	}
+
+	switch strings.ToLower(targetCfg.ProviderType) {
+	case gpsconfig.ARCHIVE, gpsconfig.DIRECTORY:
+		return nil
+	}
+
+	if err := provider.SyncReleases(ctx, sourceCfg, sourceClient, targetCfg, client, repo); err != nil {
+		return fmt.Errorf("sync releases to target: %w", err)
+	}
+
+	return nil
+}
+
+// syncMetadata recreates the issues, with their labels and milestones, of a pushed repository at a provider target.
+// A directory target gets them exported next to the repository, an archive target got them in its tarball.
+func syncMetadata(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, client, sourceClient interfaces.GitProvider, repo interfaces.GitRepository) error {
+	if !sourceCfg.Repositories.IncludeMetadata {
+		return nil
+	}
+
+	switch strings.ToLower(targetCfg.ProviderType) {
+	case gpsconfig.ARCHIVE:
+		return nil
+	case gpsconfig.DIRECTORY:
+		dir := filepath.Join(targetCfg.DirectoryTargetDir(), repo.ProjectInfo().Name(ctx)+provider.MetadataSuffix)
+		if err := provider.ExportMetadata(ctx, sourceCfg, sourceClient, repo, dir); err != nil {
+			return fmt.Errorf("export metadata to target: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := provider.SyncMetadata(ctx, sourceCfg, sourceClient, targetCfg, client, repo); err != nil {
+		return fmt.Errorf("sync metadata to target: %w", err)
+	}
 
 	return nil
 }
@@ -87,7 +385,7 @@ func getTargetWriter(cfg gpsconfig.ProviderConfig) (interfaces.TargetWriter, err
 }
 
 func incrementSyncCount(ctx context.Context) {
-	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(model.SyncRunMetainfo); ok {
-		meta.Total++
+	if meta, ok := ctx.Value(model.SyncRunMetainfoKey{}).(*model.SyncRunMetainfo); ok {
+		meta.IncrementSynced()
 	}
 }
@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package synccmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	mocks "itiquette/git-provider-sync/generated/mocks/mockgogit"
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailureCategory(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"invalid name", fmt.Errorf("%w: repo", ErrInvalidRepoName), model.FailName},
+		{"lookup", fmt.Errorf("push to target: %w", provider.ErrRepositoryLookup), model.FailCreate},
+		{"create", fmt.Errorf("push to target: %w", provider.ErrCreateRepository), model.FailCreate},
+		{"protect", fmt.Errorf("push to target: %w", provider.ErrProtectRepository), model.FailProtect},
+		{"default branch", fmt.Errorf("push to target: %w", provider.ErrDefaultBranch), model.FailDefaultBranch},
+		{"push", fmt.Errorf("push to target: %w", provider.ErrPushChanges), model.FailPush},
+		{"release", fmt.Errorf("sync releases to target: %w", provider.ErrSyncRelease), model.FailRelease},
+		{"mirror", fmt.Errorf("mirror at target: %w", provider.ErrMirrorRepository), model.FailMirror},
+		{"metadata", fmt.Errorf("sync metadata to target: %w", provider.ErrSyncMetadata), model.FailMetadata},
+		{"other", errors.New("failed to prepare repository"), model.FailPush},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, failureCategory(tabletest.err))
+		})
+	}
+}
+
+func TestTargetConcurrency(t *testing.T) {
+	tests := []struct {
+		name   string
+		source int
+		target int
+		want   int
+	}{
+		{"unset", 0, 0, 1},
+		{"from source", 4, 0, 4},
+		{"target overrides source", 4, 2, 2},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			sourceCfg := gpsconfig.ProviderConfig{SyncRun: gpsconfig.SyncRunOption{Concurrency: tabletest.source}}
+			targetCfg := gpsconfig.ProviderConfig{SyncRun: gpsconfig.SyncRunOption{Concurrency: tabletest.target}}
+
+			require.Equal(t, tabletest.want, targetConcurrency(sourceCfg, targetCfg))
+		})
+	}
+}
+
+func TestMirrorsOnly(t *testing.T) {
+	providerMirror := gpsconfig.ProviderConfig{SyncRun: gpsconfig.SyncRunOption{Mode: gpsconfig.ModeProviderMirror}}
+	sourceMirror := gpsconfig.ProviderConfig{SyncRun: gpsconfig.SyncRunOption{Mode: gpsconfig.ModeSourceMirror}}
+	push := gpsconfig.ProviderConfig{}
+
+	tests := []struct {
+		name          string
+		targets       []gpsconfig.ProviderConfig
+		pushMirroring bool
+		want          bool
+	}{
+		{"no targets", nil, true, false},
+		{"provider and source mirrors", []gpsconfig.ProviderConfig{providerMirror, sourceMirror}, true, true},
+		{"source without push mirrors", []gpsconfig.ProviderConfig{providerMirror, sourceMirror}, false, false},
+		{"pushed target", []gpsconfig.ProviderConfig{providerMirror, push}, true, false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			sourceClient := mocks.NewGitProvider(t)
+			sourceClient.EXPECT().SupportsPushMirror().Return(tabletest.pushMirroring).Maybe()
+
+			targets := make(map[string]gpsconfig.ProviderConfig)
+			for index, target := range tabletest.targets {
+				targets[fmt.Sprint(index)] = target
+			}
+
+			config := gpsconfig.ProvidersConfig{ProviderTargets: targets}
+
+			require.Equal(t, tabletest.want, mirrorsOnly(config, sourceClient))
+		})
+	}
+}
+
+func TestWithSubgroups(t *testing.T) {
+	subgroups := gpsconfig.ProviderConfig{Repositories: gpsconfig.RepositoriesOption{IncludeSubgroups: true}}
+
+	tests := []struct {
+		name          string
+		sourceCfg     gpsconfig.ProviderConfig
+		targetCfg     gpsconfig.ProviderConfig
+		wantName      string
+		wantSubgroups bool
+	}{
+		{
+			name:      "without subgroups",
+			targetCfg: gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITHUB, Group: "org"},
+			wantName:  "team/backend/api",
+		},
+		{
+			name:          "gitlab group recreates subgroups",
+			sourceCfg:     subgroups,
+			targetCfg:     gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, Group: "org"},
+			wantName:      "team/backend/api",
+			wantSubgroups: true,
+		},
+		{
+			name:      "gitlab user flattens",
+			sourceCfg: subgroups,
+			targetCfg: gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, User: "me"},
+			wantName:  "team_backend_api",
+		},
+		{
+			name:      "github flattens with separator",
+			sourceCfg: subgroups,
+			targetCfg: gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITHUB, Group: "org", Project: gpsconfig.ProjectOption{SubgroupSeparator: "--"}},
+			wantName:  "team--backend--api",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			ctx := model.WithCLIOption(context.Background(), model.CLIOption{})
+
+			ctx, targetCfg := withSubgroups(ctx, tabletest.sourceCfg, tabletest.targetCfg)
+
+			require.Equal(t, tabletest.wantName, model.ProjectInfo{OriginalName: "team/backend/api"}.Name(ctx))
+			require.Equal(t, tabletest.wantSubgroups, targetCfg.Repositories.IncludeSubgroups)
+		})
+	}
+}
+
+func TestWithNameTemplate(t *testing.T) {
+	source := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITHUB, Group: "MyOrg"}
+	subgroups := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, Group: "myorg", Repositories: gpsconfig.RepositoriesOption{IncludeSubgroups: true}}
+	gitlabGroup := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, Group: "mirrors"}
+
+	tests := []struct {
+		name      string
+		sourceCfg gpsconfig.ProviderConfig
+		template  string
+		original  string
+		want      string
+	}{
+		{"no template", source, "", "api", "api"},
+		{"owner and name", source, "mirror-{{.Owner}}-{{.Name}}", "api", "mirror-MyOrg-api"},
+		{"functions", source, `{{.SourceDomain | replace "." "-"}}-{{.Owner | lower}}-{{.Name | trimprefix "svc-"}}`, "svc-api", "github-com-myorg-api"},
+		{"provider type", source, "{{.ProviderType}}-{{.Name}}", "api", "github-api"},
+		{"recreated subgroups", subgroups, "mirror-{{.Name}}", "team/backend/api", "team/backend/mirror-api"},
+		{"nested group owner", gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITLAB, Group: "myorg/team"}, "{{.Owner}}-{{.Name}}", "api", "myorg-team-api"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			targetCfg := gitlabGroup
+			targetCfg.Project.NameTemplate = tabletest.template
+
+			ctx, targetCfg := withSubgroups(model.WithCLIOption(context.Background(), model.CLIOption{}), tabletest.sourceCfg, targetCfg)
+			ctx = withNameTemplate(ctx, tabletest.sourceCfg, targetCfg)
+
+			projectinfo := model.ProjectInfo{OriginalName: tabletest.original}
+			require.Equal(t, tabletest.want, projectinfo.Name(ctx))
+			require.Equal(t, tabletest.want+model.WikiSuffix, projectinfo.Wiki().Name(ctx))
+		})
+	}
+}
+
+func TestValidateNameTemplate(t *testing.T) {
+	ctx := model.WithCLIOption(context.Background(), model.CLIOption{})
+	targetCfg := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITEA, Project: gpsconfig.ProjectOption{NameTemplate: "mirror {{.Name}}"}}
+	ctx = withNameTemplate(ctx, gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITHUB, User: "me"}, targetCfg)
+
+	client := mocks.NewGitProvider(t)
+	client.EXPECT().IsValidProjectName(ctx, "mirror repository").Return(false)
+
+	require.ErrorIs(t, validateNameTemplate(ctx, client, targetCfg), ErrInvalidRepoName)
+	require.NoError(t, validateNameTemplate(ctx, client, gpsconfig.ProviderConfig{}))
+}
+
+func TestValidProjectPath(t *testing.T) {
+	ctx := model.WithCLIOption(context.Background(), model.CLIOption{})
+	subgroups := gpsconfig.ProviderConfig{Repositories: gpsconfig.RepositoriesOption{IncludeSubgroups: true}}
+
+	tests := []struct {
+		name      string
+		targetCfg gpsconfig.ProviderConfig
+		path      string
+		valid     bool
+	}{
+		{"name", gpsconfig.ProviderConfig{}, "api", true},
+		{"path without subgroups", gpsconfig.ProviderConfig{}, "myorg/api", false},
+		{"path with subgroups", subgroups, "team/api", true},
+		{"invalid subgroup", subgroups, "team!/api", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			client := mocks.NewGitProvider(t)
+			client.EXPECT().IsValidProjectName(ctx, mock.Anything).RunAndReturn(func(_ context.Context, name string) bool {
+				return name != "team!"
+			}).Maybe()
+
+			require.Equal(t, tabletest.valid, validProjectPath(ctx, client, tabletest.targetCfg, tabletest.path))
+		})
+	}
+}
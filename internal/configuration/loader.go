@@ -35,6 +35,14 @@ func (DefaultConfigLoader) LoadConfiguration(ctx context.Context) (*config.AppCo
 		return nil, fmt.Errorf("failed to read configuration file: %w", err)
 	}
 
+	for _, config := range appConfig.Configurations {
+		if err := validateOwners(config); err != nil {
+			return nil, fmt.Errorf("failed to validate configuration: %w", err)
+		}
+	}
+
+	appConfig.ExpandOwners()
+
 	for _, config := range appConfig.Configurations {
 		if err := validateConfiguration(config); err != nil {
 			return nil, fmt.Errorf("failed to validate configuration: %w", err)
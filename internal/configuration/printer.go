@@ -87,6 +87,14 @@ func printProviderDetails(config config.ProviderConfig, writer io.Writer) {
 
 	fmt.Fprintf(writer, " Include: %s\n", config.Repositories.Include)
 	fmt.Fprintf(writer, " Exclude: %s\n", config.Repositories.Exclude)
+
+	if len(config.Repositories.URLs) > 0 {
+		fmt.Fprintf(writer, " URLs: %s\n", config.Repositories.URLs)
+	}
+
+	if len(config.Repositories.URLsFile) > 0 {
+		fmt.Fprintf(writer, " URLsFile: %s\n", config.Repositories.URLsFile)
+	}
 }
 
 func printProjectOption(writer io.Writer, config config.ProviderConfig) {
@@ -11,11 +11,15 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 	"time"
 
+	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	gpsprovider "itiquette/git-provider-sync/internal/provider"
+	"itiquette/git-provider-sync/internal/provider/azuredevops"
 	"itiquette/git-provider-sync/internal/target/gitbinary"
 
 	"golang.org/x/crypto/ssh/agent"
@@ -33,17 +37,22 @@ const (
 
 var (
 	// Provider Type Errors.
-	ErrUnsupportedProvider          = errors.New("unsupported provider")
-	ErrUnsupportedArchiveProvider   = errors.New("source provider: does not support reading from archive")
-	ErrUnsupportedDirectoryProvider = errors.New("source provider: does not support reading from directory")
-	ErrInvalidURL                   = errors.New("invalid URL")
+	ErrUnsupportedProvider = errors.New("unsupported provider")
+	ErrInvalidURL          = errors.New("invalid URL")
 
 	// Configuration Errors.
-	ErrNoSourceDomain    = errors.New("source provider: no domain configured")
-	ErrNoTargetDomain    = errors.New("target provider: no domain configured")
-	ErrNoTargetProviders = errors.New("no target provider/s configured")
-	ErrNoHTTPToken       = errors.New("no httpclient token set")
-	ErrInvalidDuration   = errors.New("invalid duration format")
+	ErrNoSourceDomain      = errors.New("source provider: no domain configured")
+	ErrNoTargetDomain      = errors.New("target provider: no domain configured")
+	ErrNoTargetProviders   = errors.New("no target provider/s configured")
+	ErrNoHTTPToken         = errors.New("no httpclient token set")
+	ErrInvalidDuration     = errors.New("invalid duration format")
+	ErrInvalidConcurrency  = errors.New("syncrun.concurrency must not be negative")
+	ErrInvalidMaxAttempts  = errors.New("httpclient.maxattempts must not be negative")
+	ErrInvalidOnMissing    = errors.New("invalid syncrun.onmissing")
+	ErrInvalidReviewRefs   = errors.New("invalid repositories.reviewrefs")
+	ErrInvalidMode         = errors.New("invalid syncrun.mode")
+	ErrInvalidOnCollision  = errors.New("invalid syncrun.oncollision")
+	ErrInvalidNameTemplate = errors.New("invalid project.nametemplate")
 
 	// Authentication Errors.
 	ErrTokenAuth        = errors.New("target provider currently only supports token auth")
@@ -62,24 +71,38 @@ var (
 	ErrBothGroupAndUser    = errors.New("provider: group path and user configured, only one is allowed")
 	ErrInvalidGroupName    = errors.New("invalid group name")
 	ErrInvalidUserName     = errors.New("invalid username")
+	ErrInvalidOwners       = errors.New("invalid groups or users")
 
 	// Repository Errors.
 	ErrExcludeIsConfiguredButEmpty = errors.New("exclude is configured but 'repositories:' contains no repository names")
 	ErrIncludeIsConfiguredButEmpty = errors.New("include is configured but 'repositories:' contains no repository names")
 	ErrInvalidRepoName             = errors.New("invalid repository name")
 	ErrInvalidDescription          = errors.New("invalid repository description")
+	ErrNoRepositoryURLs            = errors.New("source provider: gitremote requires repositories.urls or repositories.urlsfile")
+	ErrRepositoryURLsNotSupported  = errors.New("repositories.urls and repositories.urlsfile are only valid for a gitremote source provider")
 
 	// Path Errors.
 	ErrArchiveMissingTargetPath   = errors.New("archive target provider: missing property archivetargetdir")
 	ErrDirectoryMissingTargetPath = errors.New("directory target provider: missing property directorytargetdir")
+	ErrArchiveMissingSourcePath   = errors.New("archive source provider: missing property archivesourcedir")
+	ErrDirectoryMissingSourcePath = errors.New("directory source provider: missing property directorysourcedir")
 	ErrInvalidPath                = errors.New("invalid file path")
 )
 
 var (
-	ValidSourceGitProviders = []string{config.GITHUB, config.GITLAB, config.GITEA}
-	ValidTargetGitProviders = []string{config.GITHUB, config.GITLAB, config.GITEA, config.ARCHIVE, config.DIRECTORY}
-	ValidProtocolTypes      = []string{"", config.HTTPS, config.SSHAGENT}
-	ValidSchemeTypes        = []string{"", config.HTTPS, config.HTTP}
+	ValidSourceGitProviders  = []string{config.GITHUB, config.GITLAB, config.GITEA, config.BITBUCKET, config.BITBUCKETSERVER, config.AZUREDEVOPS, config.GITREMOTE, config.ARCHIVE, config.DIRECTORY}
+	ValidTargetGitProviders  = []string{config.GITHUB, config.GITLAB, config.GITEA, config.BITBUCKET, config.BITBUCKETSERVER, config.AZUREDEVOPS, config.ARCHIVE, config.DIRECTORY}
+	ValidProtocolTypes       = []string{"", config.HTTPS, config.SSHAGENT}
+	ValidSchemeTypes         = []string{"", config.HTTPS, config.HTTP}
+	ValidOnMissingPolicies   = []string{config.OnMissingIgnore, config.OnMissingArchive, config.OnMissingRename, config.OnMissingDelete}
+	ValidSyncModes           = []string{"", config.ModePush, config.ModeProviderMirror, config.ModeSourceMirror}
+	ValidOnCollisionPolicies = []string{config.OnCollisionFail, config.OnCollisionPrefixOwner, config.OnCollisionPrefixDomain, config.OnCollisionSuffixHash}
+
+	// reservedRefNamespaces are kept by git or the providers, which refuse pushes to them.
+	reservedRefNamespaces = []string{"refs/heads", "refs/tags", "refs/remotes", "refs/pull", "refs/merge-requests", "refs/pull-requests", "refs/keep-around", "refs/environments", "refs/pipelines"}
+
+	// bitbucketServerProjectKeyRegex matches a Bitbucket Server project key.
+	bitbucketServerProjectKeyRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
 )
 
 // validateConfiguration performs validation of the entire ProvidersConfig.
@@ -96,6 +119,74 @@ func validateConfiguration(providersConfig config.ProvidersConfig) error {
 		if err := validateTargetProvider(target); err != nil {
 			return fmt.Errorf("failed to validate target provider: %w", err)
 		}
+
+		if err := validateMode(providersConfig.SourceProvider, target); err != nil {
+			return fmt.Errorf("failed to validate target provider: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateOwners validates a source with several owners, before it is split into one configuration per owner.
+// A target is given the owners from the source, or its own group or user, unless the owner mapping names another owner.
+func validateOwners(providersConfig config.ProvidersConfig) error {
+	source := providersConfig.SourceProvider
+
+	if !source.HasOwners() {
+		for _, target := range providersConfig.ProviderTargets {
+			if len(target.OwnerMapping) > 0 {
+				return fmt.Errorf("%w: target provider: ownermapping needs source groups or users", ErrInvalidOwners)
+			}
+		}
+
+		return nil
+	}
+
+	switch strings.ToLower(source.ProviderType) {
+	case config.GITREMOTE, config.ARCHIVE, config.DIRECTORY:
+		return fmt.Errorf("%w: source provider: groups and users are not supported for %s", ErrInvalidOwners, source.ProviderType)
+	}
+
+	if source.Group != "" || source.User != "" {
+		return fmt.Errorf("%w: source provider: group and user can't be combined with groups and users", ErrInvalidOwners)
+	}
+
+	owners := source.OwnerGroups()
+	for _, group := range owners {
+		if err := validateGroupName(group); err != nil {
+			return fmt.Errorf("source provider: groups: %w", err)
+		}
+	}
+
+	for _, user := range source.OwnerUsers() {
+		if err := validateUsername(user); err != nil {
+			return fmt.Errorf("source provider: users: %w", err)
+		}
+	}
+
+	owners = append(owners, source.OwnerUsers()...)
+	listed := map[string]bool{}
+
+	// Each owner is synced in a configuration named by it
+	for _, owner := range owners {
+		if listed[strings.ToLower(owner)] {
+			return fmt.Errorf("%w: source provider: %s is listed more than once", ErrInvalidOwners, owner)
+		}
+
+		listed[strings.ToLower(owner)] = true
+	}
+
+	for _, target := range providersConfig.ProviderTargets {
+		for sourceOwner, targetOwner := range target.OwnerMapping {
+			if !listed[strings.ToLower(sourceOwner)] {
+				return fmt.Errorf("%w: target provider: ownermapping %s is not one of the source groups or users", ErrInvalidOwners, sourceOwner)
+			}
+
+			if targetOwner == "" {
+				return fmt.Errorf("%w: target provider: ownermapping %s has no target owner", ErrInvalidOwners, sourceOwner)
+			}
+		}
 	}
 
 	return nil
@@ -107,18 +198,42 @@ func validateSourceProvider(provider config.ProviderConfig) error {
 		return fmt.Errorf("source provider: must be one of %v: %w", ValidSourceGitProviders, ErrUnsupportedProvider)
 	}
 
-	if err := validateDomainName(provider.GetDomain()); err != nil {
-		return fmt.Errorf("%w %w", ErrNoSourceDomain, err)
-	}
+	// Plain git and restore sources are given by urls or paths, there is no domain, group or user to read from
+	switch provider.ProviderType {
+	case config.GITREMOTE:
+		if err := validateGitRemote(provider); err != nil {
+			return err
+		}
+	case config.ARCHIVE, config.DIRECTORY:
+		if err := validateRestoreSource(provider); err != nil {
+			return err
+		}
+	default:
+		if err := validateDomainName(provider.GetDomain()); err != nil {
+			return fmt.Errorf("%w %w", ErrNoSourceDomain, err)
+		}
 
-	if err := validateGroupAndUser(provider); err != nil {
-		return err
+		if err := validateGroupAndUser(provider); err != nil {
+			return err
+		}
+
+		if err := validateProviderSpecific(provider, ErrNoSourceDomain); err != nil {
+			return err
+		}
+
+		if provider.Repositories.URLs != "" || provider.Repositories.URLsFile != "" {
+			return ErrRepositoryURLsNotSupported
+		}
 	}
 
 	if err := validateHTTPClient(provider); err != nil {
 		return err
 	}
 
+	if err := validateTimeouts(provider); err != nil {
+		return fmt.Errorf("source provider: %w", err)
+	}
+
 	if err := validateSSHClient(provider); err != nil {
 		return err
 	}
@@ -143,6 +258,42 @@ func validateSourceProvider(provider config.ProviderConfig) error {
 		}
 	}
 
+	if provider.SyncRun.Concurrency < 0 {
+		return fmt.Errorf("source provider: %w", ErrInvalidConcurrency)
+	}
+
+	if provider.Repositories.IncludeWikis && !gpsprovider.SupportsWikis(provider.ProviderType) {
+		return fmt.Errorf("source provider: repositories.includewikis is not supported for %s, only github, gitlab and gitea", provider.ProviderType)
+	}
+
+	if provider.Repositories.IncludeReleases && !gpsprovider.SupportsReleases(provider.ProviderType) {
+		return fmt.Errorf("source provider: repositories.includereleases is not supported for %s, only github, gitlab and gitea", provider.ProviderType)
+	}
+
+	if provider.Repositories.IncludeMetadata && !gpsprovider.SupportsMetadata(provider.ProviderType) {
+		return fmt.Errorf("source provider: repositories.includemetadata is not supported for %s, only github, gitlab and gitea", provider.ProviderType)
+	}
+
+	if provider.Repositories.IncludeSubgroups && (!strings.EqualFold(provider.ProviderType, config.GITLAB) || !provider.IsGroup()) {
+		return fmt.Errorf("source provider: repositories.includesubgroups is only supported for gitlab groups, not %s", provider.ProviderType)
+	}
+
+	if err := validateReviewRefs(provider); err != nil {
+		return err
+	}
+
+	if provider.Git.LFS && strings.EqualFold(provider.Git.Type, config.SSHAGENT) {
+		return errors.New("source provider: git.lfs needs git.type https, LFS objects are not fetched over ssh")
+	}
+
+	if provider.Git.CacheDir != "" && !filepath.IsAbs(provider.Git.CacheDir) {
+		return fmt.Errorf("source provider: git.cachedir: %w: must be an absolute path", ErrInvalidPath)
+	}
+
+	if provider.SyncRun.StateFile != "" && !filepath.IsAbs(provider.SyncRun.StateFile) {
+		return fmt.Errorf("source provider: syncrun.statefile: %w: must be an absolute path", ErrInvalidPath)
+	}
+
 	if provider.Project.Description != "" {
 		return errors.New("source provider does not support project.description, only target does")
 	}
@@ -155,11 +306,27 @@ func validateSourceProvider(provider config.ProviderConfig) error {
 		return errors.New("source provider does not support project.visibility, only target does")
 	}
 
-	if provider.SyncRun.CleanupInvalidName || provider.SyncRun.ForcePush || provider.SyncRun.IgnoreInvalidName {
-		return errors.New("source provider does not support syncrun.cleanupinvalidname, forcepush, ignoreninvalid")
+	if provider.Project.NameTemplate != "" {
+		return errors.New("source provider does not support project.nametemplate, only target does")
+	}
+
+	if provider.SyncRun.CleanupInvalidName || provider.SyncRun.ForcePush || provider.SyncRun.IgnoreInvalidName || provider.SyncRun.Prune {
+		return errors.New("source provider does not support syncrun.cleanupinvalidname, forcepush, ignoreninvalid, prune")
+	}
+
+	if provider.SyncRun.OnMissing != "" || provider.SyncRun.MissingSuffix != "" {
+		return errors.New("source provider does not support syncrun.onmissing, missingsuffix, only target does")
 	}
 
-	if provider.Additional != nil {
+	if provider.SyncRun.Mode != "" {
+		return errors.New("source provider does not support syncrun.mode, only target does")
+	}
+
+	if provider.SyncRun.OnCollision != "" {
+		return errors.New("source provider does not support syncrun.oncollision, only target does")
+	}
+
+	if provider.Additional != nil && provider.ProviderType != config.ARCHIVE && provider.ProviderType != config.DIRECTORY {
 		return errors.New("additional is not valid for a source provider")
 	}
 
@@ -180,6 +347,10 @@ func validateTargetProvider(providerConfig config.ProviderConfig) error {
 			return err
 		}
 
+		if err := validateProviderSpecific(providerConfig, ErrNoTargetDomain); err != nil {
+			return err
+		}
+
 		if providerConfig.Git.UseGitBinary {
 			if _, err := gitbinary.ValidateGitBinary(); err != nil {
 				return ErrNoGitBinaryFound
@@ -190,6 +361,14 @@ func validateTargetProvider(providerConfig config.ProviderConfig) error {
 			return errors.New("target provider: git.invalid forks is not valid here")
 		}
 
+		if providerConfig.Git.CacheDir != "" {
+			return errors.New("target provider: git.cachedir is only valid for source provider configurations")
+		}
+
+		if providerConfig.Git.LFS {
+			return errors.New("target provider: git.lfs is only valid for source provider configurations")
+		}
+
 		if !isValidProtocolType(providerConfig.Git.Type) {
 			return fmt.Errorf("gitinfo type: must be one of %v: %w", ValidProtocolTypes, ErrUnsupportedProtocolType)
 		}
@@ -198,6 +377,10 @@ func validateTargetProvider(providerConfig config.ProviderConfig) error {
 			return err
 		}
 
+		if err := validateTimeouts(providerConfig); err != nil {
+			return fmt.Errorf("target provider: %w", err)
+		}
+
 		if err := validateSSHClient(providerConfig); err != nil {
 			return err
 		}
@@ -206,7 +389,8 @@ func validateTargetProvider(providerConfig config.ProviderConfig) error {
 			return errors.New("target Provider: using proxy command requires Git.UseGitBinary true due to restrictions in underlying go-git library")
 		}
 
-		if len(providerConfig.Repositories.Include) != 0 || len(providerConfig.Repositories.Exclude) != 0 {
+		if len(providerConfig.Repositories.Include) != 0 || len(providerConfig.Repositories.Exclude) != 0 ||
+			len(providerConfig.Repositories.URLs) != 0 || len(providerConfig.Repositories.URLsFile) != 0 {
 			return errors.New("target provider: repositories is only valid for source provider configurations")
 		}
 
@@ -221,6 +405,30 @@ func validateTargetProvider(providerConfig config.ProviderConfig) error {
 		}
 	}
 
+	if providerConfig.SyncRun.Concurrency < 0 {
+		return fmt.Errorf("target provider: %w", ErrInvalidConcurrency)
+	}
+
+	if separator := providerConfig.Project.SubgroupSeparator; strings.ContainsAny(separator, "/\\ ") {
+		return fmt.Errorf("target provider: project.subgroupseparator must not contain slashes or spaces, was %q", separator)
+	}
+
+	if err := validateNameTemplate(providerConfig.Project.NameTemplate); err != nil {
+		return fmt.Errorf("target provider: %w", err)
+	}
+
+	if providerConfig.SyncRun.StateFile != "" {
+		return errors.New("target provider: syncrun.statefile is only valid for source provider configurations")
+	}
+
+	if err := validateOnMissing(providerConfig); err != nil {
+		return fmt.Errorf("target provider: %w", err)
+	}
+
+	if policy := providerConfig.SyncRun.CollisionPolicy(); !slices.Contains(ValidOnCollisionPolicies, policy) {
+		return fmt.Errorf("target provider: %w: must be one of %v, was %s", ErrInvalidOnCollision, ValidOnCollisionPolicies, providerConfig.SyncRun.OnCollision)
+	}
+
 	if err := validateAdditional(providerConfig.ProviderType, providerConfig.Additional); err != nil {
 		return fmt.Errorf("invalid additional: %w", err)
 	}
@@ -228,6 +436,85 @@ func validateTargetProvider(providerConfig config.ProviderConfig) error {
 	return nil
 }
 
+// validateNameTemplate validates a template repositories are named with, by naming an example repository with it.
+// Whether the names are valid at the target is checked by its provider, when syncing to it.
+func validateNameTemplate(text string) error {
+	if text == "" {
+		return nil
+	}
+
+	tmpl, err := model.ParseNameTemplate(text)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidNameTemplate, err)
+	}
+
+	name, err := model.NameTemplate{Template: tmpl, Owner: "owner", SourceDomain: "example.com", ProviderType: config.GITHUB}.Render("repository")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidNameTemplate, err)
+	}
+
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, "/\\") {
+		return fmt.Errorf("%w: must give a name without slashes, gave %q", ErrInvalidNameTemplate, name)
+	}
+
+	return nil
+}
+
+// validateOnMissing validates the policy for target repositories whose source repository is gone.
+// Archive and directory targets have no repositories to archive, rename or delete through an API.
+func validateOnMissing(providerConfig config.ProviderConfig) error {
+	policy := providerConfig.SyncRun.MissingPolicy()
+
+	if !slices.Contains(ValidOnMissingPolicies, policy) {
+		return fmt.Errorf("%w: must be one of %v, was %s", ErrInvalidOnMissing, ValidOnMissingPolicies, providerConfig.SyncRun.OnMissing)
+	}
+
+	if policy != config.OnMissingIgnore && (providerConfig.ProviderType == config.ARCHIVE || providerConfig.ProviderType == config.DIRECTORY) {
+		return fmt.Errorf("%w: only ignore is supported by %s targets", ErrInvalidOnMissing, providerConfig.ProviderType)
+	}
+
+	if suffix := providerConfig.SyncRun.MissingSuffix; suffix != "" && strings.TrimSpace(suffix) != suffix {
+		return fmt.Errorf("%w: syncrun.missingsuffix must not start or end with whitespace", ErrInvalidOnMissing)
+	}
+
+	return nil
+}
+
+// validateMode validates how the target is synced. A provider mirror is only created by GitLab and Gitea,
+// and fetches from the https url of a source provider, as there is nothing to fetch from plain git or restore sources.
+// A source mirror pushes to a provider target, whose branches can't be protected against it with project.disabled.
+// Sources without push mirrors have the repositories pushed instead, which is not known until their client is created.
+func validateMode(sourceConfig, targetConfig config.ProviderConfig) error {
+	mode := strings.ToLower(targetConfig.SyncRun.Mode)
+
+	if !slices.Contains(ValidSyncModes, mode) {
+		return fmt.Errorf("%w: must be one of %v, was %s", ErrInvalidMode, ValidSyncModes[1:], targetConfig.SyncRun.Mode)
+	}
+
+	switch mode {
+	case config.ModeProviderMirror:
+		if !gpsprovider.SupportsProviderMirror(targetConfig.ProviderType) {
+			return fmt.Errorf("%w: %s is only supported by gitlab and gitea targets", ErrInvalidMode, mode)
+		}
+
+		switch strings.ToLower(sourceConfig.ProviderType) {
+		case config.GITREMOTE, config.ARCHIVE, config.DIRECTORY:
+			return fmt.Errorf("%w: %s needs a provider source, not %s", ErrInvalidMode, mode, sourceConfig.ProviderType)
+		}
+	case config.ModeSourceMirror:
+		switch strings.ToLower(targetConfig.ProviderType) {
+		case config.GITREMOTE, config.ARCHIVE, config.DIRECTORY:
+			return fmt.Errorf("%w: %s needs a provider target, not %s", ErrInvalidMode, mode, targetConfig.ProviderType)
+		}
+
+		if targetConfig.Project.Disabled {
+			return fmt.Errorf("%w: %s can't be combined with project.disabled", ErrInvalidMode, mode)
+		}
+	}
+
+	return nil
+}
+
 // validateGroupAndUser validates group and user settings.
 func validateGroupAndUser(config config.ProviderConfig) error {
 	if len(config.Group) == 0 && len(config.User) == 0 {
@@ -254,6 +541,100 @@ func validateGroupAndUser(config config.ProviderConfig) error {
 	return nil
 }
 
+// validateProviderSpecific validates the settings some providers need on top of the common ones.
+func validateProviderSpecific(providerConfig config.ProviderConfig, noDomainErr error) error {
+	switch providerConfig.ProviderType {
+	case config.BITBUCKETSERVER:
+		// There is no default domain for a self-hosted server, and a group is a project key
+		if providerConfig.GetDomain() == "" {
+			return noDomainErr
+		}
+
+		if providerConfig.IsGroup() && !bitbucketServerProjectKeyRegex.MatchString(providerConfig.Group) {
+			return fmt.Errorf("%w: bitbucket server group must be a project key: %s", ErrInvalidGroupName, providerConfig.Group)
+		}
+	case config.AZUREDEVOPS:
+		if providerConfig.User != "" {
+			return fmt.Errorf("%w: azure devops has no user repositories, use group: organization/project", ErrInvalidUserName)
+		}
+
+		if _, _, err := azuredevops.SplitGroup(providerConfig.Group); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidGroupName, err)
+		}
+
+		// Azure DevOps can only disable a repository as a whole, which makes it unreadable and unwritable
+		if providerConfig.Project.Disabled {
+			return errors.New("target provider: azure devops does not support project.disabled")
+		}
+	}
+
+	return nil
+}
+
+// validateGitRemote validates a plain git source, which is configured by a list of clone urls.
+func validateGitRemote(providerConfig config.ProviderConfig) error {
+	if providerConfig.Domain != "" || providerConfig.Group != "" || providerConfig.User != "" {
+		return errors.New("source provider: gitremote does not support domain, group or user, use repositories.urls")
+	}
+
+	if providerConfig.Repositories.URLs == "" && providerConfig.Repositories.URLsFile == "" {
+		return ErrNoRepositoryURLs
+	}
+
+	if providerConfig.Repositories.URLs != "" && len(providerConfig.Repositories.URLList()) < 1 {
+		return ErrNoRepositoryURLs
+	}
+
+	if providerConfig.Repositories.URLsFile != "" {
+		if err := validatePathExists(providerConfig.Repositories.URLsFile); err != nil {
+			return fmt.Errorf("repositories.urlsfile is set but is not accessible: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validateRestoreSource validates an archive or directory source, restoring what the corresponding target wrote.
+func validateRestoreSource(providerConfig config.ProviderConfig) error {
+	if providerConfig.Domain != "" || providerConfig.Group != "" || providerConfig.User != "" {
+		return fmt.Errorf("source provider: %s does not support domain, group or user", providerConfig.ProviderType)
+	}
+
+	if providerConfig.Repositories.URLs != "" || providerConfig.Repositories.URLsFile != "" {
+		return ErrRepositoryURLsNotSupported
+	}
+
+	if providerConfig.Git.UseGitBinary || providerConfig.Git.CacheDir != "" {
+		return fmt.Errorf("source provider: %s does not support git.usegitbinary or git.cachedir", providerConfig.ProviderType)
+	}
+
+	if providerConfig.SyncRun.StateFile != "" {
+		return fmt.Errorf("source provider: %s does not support syncrun.statefile", providerConfig.ProviderType)
+	}
+
+	key, missingErr := "archivesourcedir", ErrArchiveMissingSourcePath
+	if providerConfig.ProviderType == config.DIRECTORY {
+		key, missingErr = "directorysourcedir", ErrDirectoryMissingSourcePath
+	}
+
+	for additionalKey := range providerConfig.Additional {
+		if additionalKey != key {
+			return fmt.Errorf("source provider: additional.%s is not valid for %s", additionalKey, providerConfig.ProviderType)
+		}
+	}
+
+	path, exists := providerConfig.Additional[key]
+	if !exists || path == "" {
+		return missingErr
+	}
+
+	if err := validatePathExists(path); err != nil {
+		return fmt.Errorf("additional.%s is set but is not accessible: %w", key, err)
+	}
+
+	return nil
+}
+
 func validateHTTPClient(config config.ProviderConfig) error {
 	if !isValidSchemeType(config.HTTPClient.Scheme) {
 		return fmt.Errorf("source provider: must be one of %v: %w", ValidSchemeTypes, ErrUnsupportedScheme)
@@ -271,6 +652,51 @@ func validateHTTPClient(config config.ProviderConfig) error {
 		}
 	}
 
+	if config.HTTPClient.MaxAttempts < 0 {
+		return ErrInvalidMaxAttempts
+	}
+
+	if config.HTTPClient.MaxRetryWait != "" {
+		if _, err := time.ParseDuration(config.HTTPClient.MaxRetryWait); err != nil {
+			return fmt.Errorf("httpclient.maxretrywait: %w: %w", ErrInvalidDuration, err)
+		}
+	}
+
+	return nil
+}
+
+// validateTimeouts checks the network and git timeouts are valid durations.
+// The network timeouts can be 0 to disable them, a git operation must have a limit.
+func validateTimeouts(config config.ProviderConfig) error {
+	timeouts := []struct {
+		name  string
+		value string
+	}{
+		{"httpclient.timeout", config.HTTPClient.Timeout},
+		{"httpclient.dialtimeout", config.HTTPClient.DialTimeout},
+		{"httpclient.tlshandshaketimeout", config.HTTPClient.TLSHandshakeTimeout},
+		{"git.timeout", config.Git.Timeout},
+	}
+
+	for _, timeout := range timeouts {
+		if timeout.value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w: %w", timeout.name, ErrInvalidDuration, err)
+		}
+
+		if duration < 0 {
+			return fmt.Errorf("%s: %w: must not be negative", timeout.name, ErrInvalidDuration)
+		}
+	}
+
+	if config.Git.Timeout != "" && config.Git.OperationTimeout() <= 0 {
+		return fmt.Errorf("git.timeout: %w: must be positive", ErrInvalidDuration)
+	}
+
 	return nil
 }
 
@@ -465,3 +891,28 @@ func isValidProtocolType(protocolType string) bool {
 func isValidSchemeType(schemeType string) bool {
 	return slices.Contains(ValidSchemeTypes, schemeType)
 }
+
+// validateReviewRefs checks the namespace the pull and merge request head refs are pushed under,
+// which must be a namespace of its own, like refs/mirror/pr.
+func validateReviewRefs(provider config.ProviderConfig) error {
+	namespace := strings.TrimSuffix(provider.Repositories.ReviewRefs, "/")
+	if namespace == "" {
+		return nil
+	}
+
+	if !model.SupportsReviewRefs(provider.ProviderType) {
+		return fmt.Errorf("source provider: repositories.reviewrefs is not supported for %s, only github, gitlab, gitea and bitbucketserver", provider.ProviderType)
+	}
+
+	if !strings.HasPrefix(namespace, "refs/") || strings.ContainsAny(namespace, "*?[^:~\\ ") {
+		return fmt.Errorf("source provider: %w: %s is not a ref namespace, like refs/mirror/pr", ErrInvalidReviewRefs, namespace)
+	}
+
+	for _, reserved := range reservedRefNamespaces {
+		if namespace == reserved || strings.HasPrefix(namespace, reserved+"/") {
+			return fmt.Errorf("source provider: %w: %s is reserved", ErrInvalidReviewRefs, reserved)
+		}
+	}
+
+	return nil
+}
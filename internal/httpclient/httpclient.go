@@ -0,0 +1,234 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package httpclient creates the HTTP clients and transports requests to the providers are sent with.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/retry"
+)
+
+var (
+	ErrInvalidProxy    = errors.New("invalid proxy configuration")
+	ErrCertificateLoad = errors.New("failed to load certificates")
+)
+
+// ProxyFunc defines the type for proxy configuration functions.
+type ProxyFunc func(req *http.Request) (*url.URL, error)
+
+// New creates an HTTP client for provider API calls, with the transport of NewTransport.
+func New(ctx context.Context, option config.HTTPClientOption) (*http.Client, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering httpclient:New")
+
+	transport, err := NewTransport(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		// Retry rate limited and transient failures. The timeout applies to each attempt,
+		// a client timeout would also cut the wait for a rate limit reset
+		Transport: &retry.Transport{
+			Base:    transport,
+			Policy:  retry.NewPolicy(option),
+			Timeout: option.RequestTimeout(),
+		},
+		// Limit redirect chains to prevent infinite loops
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return http.ErrUseLastResponse
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+// NewGitTransport returns the transport git transfers over https are sent with. It retries like the client of New,
+// but leaves the time limit to git.timeout, as a pack can take much longer to transfer than an API response.
+func NewGitTransport(ctx context.Context, option config.HTTPClientOption) (http.RoundTripper, error) {
+	transport, err := NewTransport(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+
+	return &retry.Transport{Base: transport, Policy: retry.NewPolicy(option)}, nil
+}
+
+// NewTransport returns the transport every request to a provider is sent with, trusting the certificates
+// in httpclient.certdirpath and going through httpclient.proxyurl.
+func NewTransport(ctx context.Context, option config.HTTPClientOption) (*http.Transport, error) {
+	certPool, err := loadCertificates(ctx, option.CertDirPath)
+	if err != nil {
+		return nil, fmt.Errorf("certificate loading error: %w", err)
+	}
+
+	proxyFunc, err := setupProxy(ctx, option.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("proxy setup error: %w", err)
+	}
+
+	return newHTTPTransport(ctx, proxyFunc, newTLSConfig(ctx, certPool), option), nil
+}
+
+// newHTTPTransport returns an http.Transport with production-ready default settings.
+func newHTTPTransport(ctx context.Context, proxyFunc ProxyFunc, tlsConfig *tls.Config, option config.HTTPClientOption) *http.Transport {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering newHTTPTransport")
+
+	return &http.Transport{
+		// Use proxy url setting or system proxy settings (HTTP_PROXY, HTTPS_PROXY)
+		Proxy: proxyFunc,
+
+		// Maximum time for TLS handshake - prevents hanging on SSL/TLS
+		TLSHandshakeTimeout: option.HandshakeTimeout(),
+
+		// Total number of idle connections across all hosts
+		MaxIdleConns: 100,
+
+		// Maximum idle connections per host
+		MaxIdleConnsPerHost: 10,
+
+		// Maximum total connections per host (idle + in-use)
+		MaxConnsPerHost: 100,
+
+		// How long to keep idle connections in pool before closing
+		IdleConnTimeout: 90 * time.Second,
+
+		// Time to wait for server's "100 Continue" response for large requests
+		ExpectContinueTimeout: 1 * time.Second,
+
+		TLSClientConfig: tlsConfig,
+
+		// Connection settings including timeouts and keep-alive
+		DialContext: (&net.Dialer{
+			Timeout:   option.ConnectTimeout(), // Time limit for establishing TCP connection
+			KeepAlive: 30 * time.Second,        // Interval for TCP keepalive packets
+			DualStack: true,                    // Enable both IPv4 and IPv6
+		}).DialContext,
+
+		// Enable HTTP/2 support when available
+		ForceAttemptHTTP2: true,
+
+		// Buffer sizes for reading/writing - 4KB is good balance
+		WriteBufferSize: 4 * 1024,
+		ReadBufferSize:  4 * 1024,
+	}
+}
+
+func newTLSConfig(ctx context.Context, caCertPool *x509.CertPool) *tls.Config {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering newTLSConfig")
+
+	return &tls.Config{
+		RootCAs:                caCertPool,           // Custom CA cert pool for verification
+		MinVersion:             tls.VersionTLS12,     // Minimum TLS version (good security practice)
+		MaxVersion:             tls.VersionTLS13,     // Maximum TLS version
+		ClientAuth:             tls.NoClientCert,     // No client certificate required
+		Renegotiation:          tls.RenegotiateNever, // Disable renegotiation (security best practice)
+		SessionTicketsDisabled: false,                // Disable session tickets for performance
+		InsecureSkipVerify:     false,                // Ensure certificate verification
+	}
+}
+
+// setupProxy configures the proxy.
+func setupProxy(ctx context.Context, proxyURL string) (ProxyFunc, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering setupProxy")
+
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	parsedURL, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxy, err)
+	}
+
+	return http.ProxyURL(parsedURL), nil
+}
+
+// loadCertificates loads certificates.
+func loadCertificates(ctx context.Context, dirPath string) (*x509.CertPool, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering loadCertificates")
+
+	if dirPath == "" {
+		return nil, nil //nolint
+	}
+
+	caCertPool := x509.NewCertPool()
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read directory: %w", ErrCertificateLoad, err)
+	}
+
+	for _, entry := range entries {
+		if err := processCertificateFile(ctx, entry, dirPath, caCertPool); err != nil {
+			return nil, err
+		}
+	}
+
+	return caCertPool, nil
+}
+
+// processCertificateFile handles loading a single certificate file.
+func processCertificateFile(ctx context.Context, entry os.DirEntry, dirPath string, pool *x509.CertPool) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering processCertificateFile")
+
+	if !isCertFile(entry.Name()) {
+		return nil
+	}
+
+	certPath := filepath.Join(dirPath, entry.Name())
+	cert, err := os.ReadFile(certPath)
+
+	if err != nil {
+		return fmt.Errorf("%w: failed to read certificate %s: %w", ErrCertificateLoad, certPath, err)
+	}
+
+	if !pool.AppendCertsFromPEM(cert) {
+		return fmt.Errorf("%w: failed to parse certificate %s", ErrCertificateLoad, certPath)
+	}
+
+	return nil
+}
+
+// isCertFile checks if the filename has a certificate extension.
+func isCertFile(filename string) bool {
+	ext := filepath.Ext(filename)
+
+	return ext == ".crt" || ext == ".pem"
+}
+
+// bitbucketCloudHost is the host of Bitbucket Cloud, whose access tokens only authenticate git with their own username.
+const bitbucketCloudHost = "bitbucket.org"
+
+// GitUsername returns the username a token authenticates git and LFS requests over https to the repository with.
+// Most providers take any username with a token, Bitbucket Cloud requires x-token-auth.
+func GitUsername(repositoryURL string) string {
+	if parsed, err := url.Parse(repositoryURL); err == nil && strings.EqualFold(parsed.Hostname(), bitbucketCloudHost) {
+		return "x-token-auth"
+	}
+
+	return "anyUser"
+}
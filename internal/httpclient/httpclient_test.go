@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package httpclient
+
+import (
+	"context"
+	"testing"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/retry"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitUsername(t *testing.T) {
+	tests := []struct {
+		name          string
+		repositoryURL string
+		want          string
+	}{
+		{name: "bitbucket cloud", repositoryURL: "https://bitbucket.org/workspace/repo.git", want: "x-token-auth"},
+		{name: "bitbucket cloud with user", repositoryURL: "https://someuser@Bitbucket.org/workspace/repo.git/info/lfs", want: "x-token-auth"},
+		{name: "other provider", repositoryURL: "https://gitlab.com/group/repo.git", want: "anyUser"},
+		{name: "bitbucket server", repositoryURL: "https://bitbucket.example.com/scm/proj/repo.git", want: "anyUser"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, GitUsername(tabletest.repositoryURL))
+		})
+	}
+}
+
+func TestNewGitTransport(t *testing.T) {
+	transport, err := NewGitTransport(context.Background(), config.HTTPClientOption{Timeout: "5s", MaxAttempts: 2})
+	require.NoError(t, err)
+
+	retrying, ok := transport.(*retry.Transport)
+	require.True(t, ok)
+	require.Zero(t, retrying.Timeout, "a transfer is limited by git.timeout, not the request timeout")
+	require.Equal(t, 2, retrying.Policy.MaxAttempts)
+}
@@ -6,19 +6,40 @@ package interfaces
 
 import (
 	"context"
+	"errors"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
 )
 
+// ErrNotSupported is returned by GitProvider operations the provider has no means for.
+var ErrNotSupported = errors.New("operation not supported by the provider")
+
 // GitProvider defines the interface for interacting with a Git provider service.
 // This interface encapsulates operations such as creating repositories,
 // fetching repository metadata, and validating repository names.
 type GitProvider interface {
+	ArchiveProject(ctx context.Context, owner string, name string) error
+	CreateIssue(ctx context.Context, owner string, name string, issue model.Issue) error
+	CreateLabel(ctx context.Context, owner string, name string, label model.Label) (model.Label, error)
+	CreateMilestone(ctx context.Context, owner string, name string, milestone model.Milestone) (model.Milestone, error)
 	CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error)
+	DeleteProject(ctx context.Context, owner string, name string) error
+	DownloadReleaseAsset(ctx context.Context, owner string, name string, asset model.ReleaseAsset, path string) error
+	EnableWiki(ctx context.Context, owner string, name string) error
+	FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error)
 	IsValidProjectName(ctx context.Context, name string) bool
+	Metadata(ctx context.Context, owner string, name string, comments bool) (model.Metadata, error)
+	MirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error
 	Name() string
 	ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error)
 	ProtectProject(ctx context.Context, owner string, defaultBranch string, projectIDStr string) error
+	ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error)
+	PushMirror(ctx context.Context, owner string, name string, opt model.PushMirrorOption) error
+	Releases(ctx context.Context, owner string, name string) ([]model.Release, error)
+	RenameProject(ctx context.Context, owner string, name string, newName string) error
+	SaveRelease(ctx context.Context, owner string, name string, release model.Release) (model.Release, error)
 	SetDefaultBranch(ctx context.Context, owner string, name string, branch string) error
+	SupportsPushMirror() bool
 	UnprotectProject(ctx context.Context, defaultBranch string, projectIDStr string) error
+	UploadReleaseAsset(ctx context.Context, owner string, name string, release model.Release, asset model.ReleaseAsset, path string) error
 }
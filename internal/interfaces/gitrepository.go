@@ -33,6 +33,11 @@ type GitRepository interface {
 	// Returns:
 	//   - model.RepositoryMetainfo: A struct containing metadata about the repository.
 	ProjectInfo() model.ProjectInfo
+
+	// Release lets go of what the repository holds on to while it is used,
+	// like the lock of the cached mirror it was cloned into.
+	// It is called once the repository has been synced to all targets.
+	Release()
 }
 
 // Note: The GitRemote interface is not defined in this snippet.
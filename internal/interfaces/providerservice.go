@@ -13,13 +13,19 @@ import (
 )
 
 type ProjectServicer interface {
+	ArchiveProject(ctx context.Context, owner, projectName string) error
 	CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error)
+	DeleteProject(ctx context.Context, owner, projectName string) error
+	EnableWiki(ctx context.Context, owner, projectName string) error
 	GetProjectInfos(ctx context.Context, cfg config.ProviderConfig) ([]model.ProjectInfo, error)
+	MirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error
+	RenameProject(ctx context.Context, owner, projectName, newName string) error
 	SetDefaultBranch(ctx context.Context, owner, projectName, branch string) error
 }
 
 type ProtectionServicer interface {
 	Protect(ctx context.Context, defaultBranch string, projectIDstr string) error
+	ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error)
 	Unprotect(ctx context.Context, defaultBranch string, projectIDStr string) error
 }
 
@@ -39,6 +39,18 @@ type SourceReader interface {
 	// Implementations of this method should be concurrency-safe and respect the
 	// provided context for cancellation and timeouts.
 	Clone(ctx context.Context, option model.CloneOption) (model.Repository, error)
+
+	// RemoteRefs lists the references of a source repository without cloning it, like git ls-remote.
+	//
+	// Parameters:
+	//   - ctx: A context.Context for handling cancellation, timeouts, and passing request-scoped values.
+	//   - option: A model.CloneOption with the url and credentials of the repository, as used by Clone.
+	//
+	// Returns:
+	//   - map[string]string: The reference names, with the hash they point to, or "ref: <target>"
+	//     for symbolic references. An empty repository gives an empty map.
+	//   - error: An error if the references could not be listed, or nil if successful.
+	RemoteRefs(ctx context.Context, option model.CloneOption) (map[string]string, error)
 }
 
 // Example usage:
@@ -127,6 +127,21 @@ func Logger(ctx context.Context) *zerolog.Logger {
 	return zerolog.Ctx(ctx)
 }
 
+// WithRepository returns a context whose logger tags every line with the repository name.
+// Use it when repositories are processed concurrently, so interleaved output can be told apart.
+//
+// Parameters:
+//   - ctx: The context containing the logger
+//   - name: The repository name to tag log lines with
+//
+// Returns:
+//   - context.Context with the tagged logger
+func WithRepository(ctx context.Context, name string) context.Context {
+	logger := Logger(ctx).With().Str("repository", name).Logger()
+
+	return logger.WithContext(ctx)
+}
+
 // getLogLevel determines the log level based on command flags.
 // It checks for the "quiet" flag first, then falls back to the "verbosity" flag.
 //
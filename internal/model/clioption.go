@@ -20,6 +20,10 @@ type CLIOption struct {
 	CleanupName         bool   // Whether to clean up repository names
 	ActiveFromLimit     string // Time limit for considering repositories as active
 	DryRun              bool   // Whether to perform a dry run without making changes
+	ContinueOnError     bool   // Whether to record a failing repository and continue with the next
+	Resume              bool   // Whether to resume an interrupted run with the steps it did not complete
+	JournalFile         string // Path to the run journal, used to resume interrupted runs
+	Prune               bool   // Whether to remove target branches and tags no longer at the source
 	ConfigFilePath      string // Path to the configuration file
 	ConfigFileOnly      bool   // Whether to use only the configuration file
 	Quiet               bool   // Whether to suppress non-essential output
@@ -64,10 +68,10 @@ func WithCLIOption(ctx context.Context, option CLIOption) context.Context {
 // String provides a string representation of CLIOption.
 func (c CLIOption) String() string {
 	return fmt.Sprintf("CLIOption{ForcePush: %v, IgnoreInvalidName: %v, CleanupName: %v, "+
-		"ActiveFromLimit: %s, DryRun: %v, ConfigFilePath: %s, ConfigFileOnly: %v, "+
+		"ActiveFromLimit: %s, DryRun: %v, ContinueOnError: %v, Resume: %v, JournalFile: %s, Prune: %v, ConfigFilePath: %s, ConfigFileOnly: %v, "+
 		"Quiet: %v, OutputFormat: %v}",
 		c.ForcePush, c.IgnoreInvalidName, c.CleanupName, c.ActiveFromLimit,
-		c.DryRun, c.ConfigFilePath, c.ConfigFileOnly, c.Quiet, c.OutputFormat)
+		c.DryRun, c.ContinueOnError, c.Resume, c.JournalFile, c.Prune, c.ConfigFilePath, c.ConfigFileOnly, c.Quiet, c.OutputFormat)
 }
 
 // Example usage:
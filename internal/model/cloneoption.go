@@ -26,11 +26,12 @@ type CloneOption struct {
 	SSHClient   model.SSHClientOption  // SSH client options
 	NonBareRepo bool                   // Whether to clone as a nonbare (regular with worktree) repository
 	Name        string                 // Repository name
+	ReviewRefs  string                 // The refspec fetching the pull and merge request head refs after cloning, if any
 }
 
 // String provides a string representation of CloneOption.
 func (co CloneOption) String() string {
-	return fmt.Sprintf("CloneOption{Name: %s, URL: %s, CleanupName: %t, Mirror: %t, NonBareRepo: %t, Git: %s, HTTPClient: %s, SSHClient: %s}",
+	return fmt.Sprintf("CloneOption{Name: %s, URL: %s, CleanupName: %t, Mirror: %t, NonBareRepo: %t, Git: %s, HTTPClient: %s, SSHClient: %s, ReviewRefs: %s}",
 		co.Name,
 		co.URL,
 		co.CleanupName,
@@ -38,7 +39,8 @@ func (co CloneOption) String() string {
 		co.NonBareRepo,
 		co.Git.String(),
 		co.HTTPClient.String(),
-		co.SSHClient.String())
+		co.SSHClient.String(),
+		co.ReviewRefs)
 }
 
 // DebugLog creates a debug log event with clone options.
@@ -51,23 +53,21 @@ func (co CloneOption) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 				Bool("nonbare_repo", co.NonBareRepo).
 				Str("git", co.Git.String()).
 				Str("http_client", co.HTTPClient.String()).
-				Str("ssh_client", co.SSHClient.String())
+				Str("ssh_client", co.SSHClient.String()).
+				Str("review_refs", co.ReviewRefs)
 }
 
 // NewCloneOption creates a new CloneOption.
 func NewCloneOption(ctx context.Context, metainfo ProjectInfo, mirror bool, providerConfig model.ProviderConfig) CloneOption {
 	logger := log.Logger(ctx)
 
-	cloneURL := metainfo.HTTPSURL
-	if strings.EqualFold(providerConfig.Git.Type, model.SSHAGENT) {
-		cloneURL = metainfo.SSHURL
-	}
+	cloneURL := CloneURL(metainfo, providerConfig)
 
 	logger.Info().
 		Str("url", cloneURL).
 		Msg("Cloning repository:")
 
-	return CloneOption{
+	option := CloneOption{
 		Name:       metainfo.Name(ctx),
 		URL:        cloneURL,
 		Mirror:     mirror,
@@ -75,4 +75,20 @@ func NewCloneOption(ctx context.Context, metainfo ProjectInfo, mirror bool, prov
 		HTTPClient: providerConfig.HTTPClient,
 		SSHClient:  providerConfig.SSHClient,
 	}
+
+	// Wikis have no pull requests
+	if !metainfo.IsWiki() {
+		option.ReviewRefs = ReviewRefsFetchSpec(providerConfig)
+	}
+
+	return option
+}
+
+// CloneURL returns the url a repository is cloned from, depending on the configured git type.
+func CloneURL(metainfo ProjectInfo, providerConfig model.ProviderConfig) string {
+	if strings.EqualFold(providerConfig.Git.Type, model.SSHAGENT) {
+		return metainfo.SSHURL
+	}
+
+	return metainfo.HTTPSURL
 }
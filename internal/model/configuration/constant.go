@@ -5,11 +5,15 @@
 package model
 
 const (
-	GITHUB    string = "github"
-	GITLAB    string = "gitlab"
-	GITEA     string = "gitea"
-	ARCHIVE   string = "archive"
-	DIRECTORY string = "directory"
+	GITHUB          string = "github"
+	GITLAB          string = "gitlab"
+	GITEA           string = "gitea"
+	BITBUCKET       string = "bitbucket"
+	BITBUCKETSERVER string = "bitbucketserver"
+	AZUREDEVOPS     string = "azuredevops"
+	GITREMOTE       string = "gitremote"
+	ARCHIVE         string = "archive"
+	DIRECTORY       string = "directory"
 )
 
 const (
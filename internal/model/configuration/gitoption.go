@@ -4,19 +4,37 @@
 
 package model
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultGitTimeout limits a git clone, fetch or push when git.timeout is not set.
+const DefaultGitTimeout = 3 * time.Minute
 
 // GitOption represents configuration options for Git operations.
 type GitOption struct {
 	Type         string `koanf:"type"`
 	IncludeForks bool   `koanf:"includeforks"`
 	UseGitBinary bool   `koanf:"usegitbinary"`
+	CacheDir     string `koanf:"cachedir"`
+
+	// LFS mirrors the Git LFS objects of the source repositories along with them.
+	LFS bool `koanf:"lfs"`
+
+	// Timeout limits each git clone, fetch or push, as a duration.
+	Timeout string `koanf:"timeout"`
 }
 
 // String returns a string representation of GitOption, masking sensitive information.
 func (p GitOption) String() string {
-	return fmt.Sprintf("GitOption: Type: %s, IncludeForks: %v, UseGitBinary: %v",
-		p.Type, p.IncludeForks, p.UseGitBinary)
+	return fmt.Sprintf("GitOption: Type: %s, IncludeForks: %v, UseGitBinary: %v, CacheDir: %s, LFS: %v, Timeout: %s",
+		p.Type, p.IncludeForks, p.UseGitBinary, p.CacheDir, p.LFS, p.Timeout)
+}
+
+// OperationTimeout returns the limit of each git clone, fetch or push, DefaultGitTimeout when unset.
+func (p GitOption) OperationTimeout() time.Duration {
+	return durationOrDefault(p.Timeout, DefaultGitTimeout)
 }
 
 // NewGitOption creates a new GitOption with default values.
@@ -6,6 +6,14 @@ package model
 
 import (
 	"fmt"
+	"time"
+)
+
+// Default network timeouts, used when httpclient leaves them unset.
+const (
+	DefaultHTTPTimeout         = 30 * time.Second
+	DefaultDialTimeout         = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
 )
 
 type HTTPClientOption struct {
@@ -13,11 +21,51 @@ type HTTPClientOption struct {
 	ProxyURL    string `koanf:"proxyurl"`
 	Token       string `koanf:"token"`
 	CertDirPath string `koanf:"certdirpath"`
+
+	// MaxAttempts is how often a request or git transfer is tried before giving up, 0 for the default.
+	MaxAttempts int `koanf:"maxattempts"`
+
+	// MaxRetryWait is the longest a rate limited request waits to be retried, as a duration.
+	MaxRetryWait string `koanf:"maxretrywait"`
+
+	// Timeout limits each request, from connecting to reading the response, as a duration. 0 disables it.
+	Timeout string `koanf:"timeout"`
+
+	// DialTimeout limits establishing a connection, as a duration. 0 disables it.
+	DialTimeout string `koanf:"dialtimeout"`
+
+	// TLSHandshakeTimeout limits the TLS handshake, as a duration. 0 disables it.
+	TLSHandshakeTimeout string `koanf:"tlshandshaketimeout"`
 }
 
 func (p HTTPClientOption) String() string {
-	return fmt.Sprintf("HTTPClientOption: ProxyURL %s, Token: %s",
-		p.ProxyURL, maskToken())
+	return fmt.Sprintf("HTTPClientOption: ProxyURL %s, Token: %s, MaxAttempts: %d, MaxRetryWait: %s, Timeout: %s, DialTimeout: %s, TLSHandshakeTimeout: %s",
+		p.ProxyURL, maskToken(), p.MaxAttempts, p.MaxRetryWait, p.Timeout, p.DialTimeout, p.TLSHandshakeTimeout)
+}
+
+// RequestTimeout returns the limit of each request, DefaultHTTPTimeout when unset.
+func (p HTTPClientOption) RequestTimeout() time.Duration {
+	return durationOrDefault(p.Timeout, DefaultHTTPTimeout)
+}
+
+// ConnectTimeout returns the limit of establishing a connection, DefaultDialTimeout when unset.
+func (p HTTPClientOption) ConnectTimeout() time.Duration {
+	return durationOrDefault(p.DialTimeout, DefaultDialTimeout)
+}
+
+// HandshakeTimeout returns the limit of the TLS handshake, DefaultTLSHandshakeTimeout when unset.
+func (p HTTPClientOption) HandshakeTimeout() time.Duration {
+	return durationOrDefault(p.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout)
+}
+
+// durationOrDefault parses a duration setting, already validated on load, falling back when it is unset.
+func durationOrDefault(value string, fallback time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+
+	return duration
 }
 
 func maskToken() string {
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeouts(t *testing.T) {
+	tests := []struct {
+		name          string
+		httpClient    HTTPClientOption
+		git           GitOption
+		wantRequest   time.Duration
+		wantDial      time.Duration
+		wantHandshake time.Duration
+		wantGit       time.Duration
+	}{
+		{
+			name:          "defaults",
+			wantRequest:   DefaultHTTPTimeout,
+			wantDial:      DefaultDialTimeout,
+			wantHandshake: DefaultTLSHandshakeTimeout,
+			wantGit:       DefaultGitTimeout,
+		},
+		{
+			name:          "configured",
+			httpClient:    HTTPClientOption{Timeout: "2m", DialTimeout: "5s", TLSHandshakeTimeout: "0"},
+			git:           GitOption{Timeout: "1h"},
+			wantRequest:   2 * time.Minute,
+			wantDial:      5 * time.Second,
+			wantHandshake: 0,
+			wantGit:       time.Hour,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(tabletest.wantRequest, tabletest.httpClient.RequestTimeout())
+			require.Equal(tabletest.wantDial, tabletest.httpClient.ConnectTimeout())
+			require.Equal(tabletest.wantHandshake, tabletest.httpClient.HandshakeTimeout())
+			require.Equal(tabletest.wantGit, tabletest.git.OperationTimeout())
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"maps"
+	"path/filepath"
+	"strings"
+)
+
+// OwnerGroups returns the groups of a source with several owners.
+func (p ProviderConfig) OwnerGroups() []string {
+	return splitAndTrim(p.Groups)
+}
+
+// OwnerUsers returns the users of a source with several owners.
+func (p ProviderConfig) OwnerUsers() []string {
+	return splitAndTrim(p.Users)
+}
+
+// HasOwners returns true if the source lists several owners with groups or users.
+func (p ProviderConfig) HasOwners() bool {
+	return p.Groups != "" || p.Users != ""
+}
+
+// TargetOwner returns the owner the repositories of a source owner are synced to at the target, and whether it is a group.
+// A target configured with a group or user has the owners synced to it, as a group or user like configured,
+// and without one they keep their name and kind. An owner in the owner mapping is synced to the owner it is mapped to instead.
+func (p ProviderConfig) TargetOwner(owner string, isGroup bool) (string, bool) {
+	targetOwner := owner
+	if p.Group != "" || p.User != "" {
+		targetOwner, isGroup = p.Group+p.User, p.IsGroup()
+	}
+
+	for sourceOwner, mappedOwner := range p.OwnerMapping {
+		if strings.EqualFold(sourceOwner, owner) {
+			return mappedOwner, isGroup
+		}
+	}
+
+	return targetOwner, isGroup
+}
+
+// PerOwner splits a configuration of a source with several owners into one configuration per owner, named <name>/<owner>.
+// Each target gets the owner the source owner is synced to there. Archive and directory targets
+// get a directory per owner, so repositories with the same name in two owners don't overwrite each other.
+func (c ProvidersConfig) PerOwner(name string) map[string]ProvidersConfig {
+	configurations := map[string]ProvidersConfig{}
+
+	for _, group := range c.SourceProvider.OwnerGroups() {
+		configurations[name+"/"+group] = c.forOwner(group, true)
+	}
+
+	for _, user := range c.SourceProvider.OwnerUsers() {
+		configurations[name+"/"+user] = c.forOwner(user, false)
+	}
+
+	return configurations
+}
+
+func (c ProvidersConfig) forOwner(owner string, isGroup bool) ProvidersConfig {
+	source := c.SourceProvider
+	source.Groups, source.Users = "", ""
+	source.Group, source.User = ownerOf(owner, isGroup)
+
+	targets := make(map[string]ProviderConfig, len(c.ProviderTargets))
+
+	for targetName, target := range c.ProviderTargets {
+		switch strings.ToLower(target.ProviderType) {
+		case ARCHIVE:
+			target.Additional = withOwnerDir(target.Additional, "archivetargetdir", owner)
+		case DIRECTORY:
+			target.Additional = withOwnerDir(target.Additional, "directorytargetdir", owner)
+		default:
+			target.Group, target.User = ownerOf(target.TargetOwner(owner, isGroup))
+		}
+
+		target.OwnerMapping = nil
+		targets[targetName] = target
+	}
+
+	return ProvidersConfig{SourceProvider: source, ProviderTargets: targets}
+}
+
+// ownerOf returns the owner as group or user.
+func ownerOf(owner string, isGroup bool) (string, string) {
+	if isGroup {
+		return owner, ""
+	}
+
+	return "", owner
+}
+
+// withOwnerDir returns a copy of the additional settings with the directory under key extended by the owner.
+func withOwnerDir(additional map[string]string, key, owner string) map[string]string {
+	additional = maps.Clone(additional)
+	if dir := additional[key]; dir != "" {
+		additional[key] = filepath.Join(dir, owner)
+	}
+
+	return additional
+}
+
+// ExpandOwners replaces each configuration of a source with several owners by one configuration per owner.
+func (a *AppConfiguration) ExpandOwners() {
+	configurations := make(map[string]ProvidersConfig, len(a.Configurations))
+
+	for name, configuration := range a.Configurations {
+		if !configuration.SourceProvider.HasOwners() {
+			configurations[name] = configuration
+
+			continue
+		}
+
+		maps.Copy(configurations, configuration.PerOwner(name))
+	}
+
+	a.Configurations = configurations
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandOwners(t *testing.T) {
+	require := require.New(t)
+
+	appConfig := &AppConfiguration{Configurations: map[string]ProvidersConfig{
+		"orgs": {
+			SourceProvider: ProviderConfig{ProviderType: GITHUB, Groups: "alpha, beta", Users: "carol"},
+			ProviderTargets: map[string]ProviderConfig{
+				"gitea":   {ProviderType: GITEA, OwnerMapping: map[string]string{"Alpha": "alpha-mirror"}},
+				"archive": {ProviderType: ARCHIVE, Additional: map[string]string{"archivetargetdir": "/backups"}},
+				"shared":  {ProviderType: GITLAB, Group: "mirrors", OwnerMapping: map[string]string{"carol": "carol-org"}},
+			},
+		},
+		"single": {
+			SourceProvider:  ProviderConfig{ProviderType: GITLAB, Group: "team"},
+			ProviderTargets: map[string]ProviderConfig{"gitea": {ProviderType: GITEA, Group: "team"}},
+		},
+	}}
+
+	appConfig.ExpandOwners()
+
+	require.Len(appConfig.Configurations, 4)
+	require.Equal("team", appConfig.Configurations["single"].SourceProvider.Group)
+
+	alpha := appConfig.Configurations["orgs/alpha"]
+	require.Equal("alpha", alpha.SourceProvider.Group)
+	require.Empty(alpha.SourceProvider.Groups)
+	require.Equal("alpha-mirror", alpha.ProviderTargets["gitea"].Group)
+	require.Nil(alpha.ProviderTargets["gitea"].OwnerMapping)
+	require.Equal("/backups/alpha", alpha.ProviderTargets["archive"].ArchiveTargetDir())
+
+	beta := appConfig.Configurations["orgs/beta"]
+	require.Equal("beta", beta.ProviderTargets["gitea"].Group)
+	require.Equal("/backups/beta", beta.ProviderTargets["archive"].ArchiveTargetDir())
+
+	carol := appConfig.Configurations["orgs/carol"]
+	require.Equal("carol", carol.SourceProvider.User)
+	require.Empty(carol.SourceProvider.Group)
+	require.Equal("carol", carol.ProviderTargets["gitea"].User)
+	require.Empty(carol.ProviderTargets["gitea"].Group)
+
+	// A target group takes the owners without a mapping, and makes the mapped ones groups
+	require.Equal("mirrors", alpha.ProviderTargets["shared"].Group)
+	require.Equal("mirrors", beta.ProviderTargets["shared"].Group)
+	require.Equal("carol-org", carol.ProviderTargets["shared"].Group)
+	require.Empty(carol.ProviderTargets["shared"].User)
+}
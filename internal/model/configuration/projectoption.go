@@ -6,14 +6,23 @@ package model
 
 import "strconv"
 
+// DefaultSubgroupSeparator joins the subgroup path and name of a repository at targets without subgroups,
+// when project.subgroupseparator is not set.
+const DefaultSubgroupSeparator = "_"
+
 type ProjectOption struct {
-	Description string `koanf:"description"`
-	Disabled    bool   `koanf:"disabled"`
-	Visibility  string `koanf:"visibility"`
+	Description       string `koanf:"description"`
+	Disabled          bool   `koanf:"disabled"`
+	Visibility        string `koanf:"visibility"`
+	SubgroupSeparator string `koanf:"subgroupseparator"`
+
+	// NameTemplate is a text/template the repositories are named with at the target, like mirror-{{.Owner}}-{{.Name}}.
+	NameTemplate string `koanf:"nametemplate"`
 }
 
 func (p ProjectOption) String() string {
-	return "ProjectOption: Type: " + p.Description + ", Disabled: " + strconv.FormatBool(p.Disabled) + ", Visibility: " + p.Visibility
+	return "ProjectOption: Type: " + p.Description + ", Disabled: " + strconv.FormatBool(p.Disabled) + ", Visibility: " + p.Visibility +
+		", SubgroupSeparator: " + p.SubgroupSeparator + ", NameTemplate: " + p.NameTemplate
 }
 
 func NewProjectOption() *ProjectOption {
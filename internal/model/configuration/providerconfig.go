@@ -18,6 +18,9 @@ type ProviderConfig struct {
 	UploadDomain string             `koanf:"uploaddomain"`
 	Group        string             `koanf:"group"`
 	User         string             `koanf:"user"`
+	Groups       string             `koanf:"groups"`
+	Users        string             `koanf:"users"`
+	OwnerMapping map[string]string  `koanf:"ownermapping"`
 	Repositories RepositoriesOption `koanf:"repositories"`
 	Git          GitOption          `koanf:"git"`
 	Project      ProjectOption      `koanf:"project"`
@@ -29,8 +32,8 @@ type ProviderConfig struct {
 
 // String returns a string representation of ProviderConfig, masking the token.
 func (p ProviderConfig) String() string {
-	return fmt.Sprintf("ProviderConfig: ProviderType: %s, Domain: %s, UploadDomain: %s, User: %s, Group: %s, Repositories: %v, Git: %v, Project: %v, HTTPClient: %v, SSHClient: %v, SyncRun: %v, Additional: %v",
-		p.ProviderType, p.Domain, p.UploadDomain, p.User, p.Group, p.Repositories, p.Git, p.Project, p.HTTPClient.String(), p.SSHClient, p.SyncRun, p.Additional)
+	return fmt.Sprintf("ProviderConfig: ProviderType: %s, Domain: %s, UploadDomain: %s, User: %s, Group: %s, Users: %s, Groups: %s, OwnerMapping: %v, Repositories: %v, Git: %v, Project: %v, HTTPClient: %v, SSHClient: %v, SyncRun: %v, Additional: %v",
+		p.ProviderType, p.Domain, p.UploadDomain, p.User, p.Group, p.Users, p.Groups, p.OwnerMapping, p.Repositories, p.Git, p.Project, p.HTTPClient.String(), p.SSHClient, p.SyncRun, p.Additional)
 }
 
 // DebugLog logs the ProviderConfig details at debug level.
@@ -49,9 +52,9 @@ func (p ProviderConfig) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 
 	switch strings.ToLower(p.ProviderType) {
 	case DIRECTORY:
-		event.Str("target_directory", p.DirectoryTargetDir())
+		event.Str("target_directory", p.DirectoryTargetDir()).Str("source_directory", p.DirectorySourceDir())
 	case ARCHIVE:
-		event.Str("target_directory", p.ArchiveTargetDir())
+		event.Str("target_directory", p.ArchiveTargetDir()).Str("source_directory", p.ArchiveSourceDir())
 	default:
 		event.Strs("user_group", []string{p.User, p.Group})
 	}
@@ -80,6 +83,16 @@ func (p ProviderConfig) DirectoryTargetDir() string {
 	return p.Additional["directorytargetdir"]
 }
 
+// ArchiveSourceDir returns the directory with archives to restore from.
+func (p ProviderConfig) ArchiveSourceDir() string {
+	return p.Additional["archivesourcedir"]
+}
+
+// DirectorySourceDir returns the directory with repositories to restore from.
+func (p ProviderConfig) DirectorySourceDir() string {
+	return p.Additional["directorysourcedir"]
+}
+
 // GitHubUploadURL returns the special GitHubUploadURL.
 func (p ProviderConfig) GitHubUploadURL() string {
 	return p.Additional["githubuploadurl"]
@@ -94,6 +107,10 @@ func (p ProviderConfig) GetDomain() string {
 			return "github.com"
 		case "gitlab":
 			return "gitlab.com"
+		case "bitbucket":
+			return "bitbucket.org"
+		case "azuredevops":
+			return "dev.azure.com"
 		default:
 			return ""
 		}
@@ -102,6 +119,11 @@ func (p ProviderConfig) GetDomain() string {
 	return p.Domain
 }
 
+// BitbucketProjectKey returns the Bitbucket project key new repositories are created in.
+func (p ProviderConfig) BitbucketProjectKey() string {
+	return p.Additional["bitbucketprojectkey"]
+}
+
 // IsGroup returns true if the configuration is for a group.
 func (p ProviderConfig) IsGroup() bool {
 	return p.Group != ""
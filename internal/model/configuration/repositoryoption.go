@@ -10,13 +10,32 @@ import (
 )
 
 type RepositoriesOption struct {
-	Exclude string `koanf:"exclude"`
-	Include string `koanf:"include"`
+	Exclude  string `koanf:"exclude"`
+	Include  string `koanf:"include"`
+	URLs     string `koanf:"urls"`
+	URLsFile string `koanf:"urlsfile"`
+
+	// IncludeWikis syncs the wiki repository of each repository along with it.
+	IncludeWikis bool `koanf:"includewikis"`
+
+	// IncludeReleases syncs the releases of each repository, with their assets, after pushing it.
+	IncludeReleases bool `koanf:"includereleases"`
+
+	// IncludeMetadata syncs the labels, milestones, issues, and merge requests of each repository after pushing it.
+	IncludeMetadata bool `koanf:"includemetadata"`
+
+	// ReviewRefs is the namespace the head refs of pull and merge requests are pushed under at the target,
+	// like refs/mirror/pr. Empty leaves them out.
+	ReviewRefs string `koanf:"reviewrefs"`
+
+	// IncludeSubgroups also lists the repositories of the subgroups of the group, named by their path in it,
+	// like team/backend/api.
+	IncludeSubgroups bool `koanf:"includesubgroups"`
 }
 
 func (r RepositoriesOption) String() string {
-	return fmt.Sprintf("RepositoryOption: Exclude %v, Include: %v",
-		r.Exclude, r.Include)
+	return fmt.Sprintf("RepositoryOption: Exclude %v, Include: %v, URLs: %v, URLsFile: %v, IncludeWikis: %v, IncludeReleases: %v, IncludeMetadata: %v, ReviewRefs: %v, IncludeSubgroups: %v",
+		r.Exclude, r.Include, r.URLs, r.URLsFile, r.IncludeWikis, r.IncludeReleases, r.IncludeMetadata, r.ReviewRefs, r.IncludeSubgroups)
 }
 
 // IncludedRepositories returns a slice of included repository names.
@@ -29,6 +48,11 @@ func (r RepositoriesOption) ExcludedRepositories() []string {
 	return splitAndTrim(r.Exclude)
 }
 
+// URLList returns a slice of the inline configured clone URLs.
+func (r RepositoriesOption) URLList() []string {
+	return splitAndTrim(r.URLs)
+}
+
 func splitAndTrim(s string) []string {
 	if s == "" {
 		return []string{}
@@ -8,11 +8,85 @@ import (
 	"strings"
 )
 
+// Policies for target repositories whose source repository is gone, set with syncrun.onmissing.
+const (
+	OnMissingIgnore  = "ignore"
+	OnMissingArchive = "archive"
+	OnMissingRename  = "rename"
+	OnMissingDelete  = "delete"
+)
+
+// Modes of syncing a target, set with syncrun.mode.
+const (
+	ModePush           = "push"
+	ModeProviderMirror = "provider-mirror"
+	ModeSourceMirror   = "source-mirror"
+)
+
+// Policies for repositories of several configurations with the same name at a target, set with syncrun.oncollision.
+const (
+	OnCollisionFail         = "fail"
+	OnCollisionPrefixOwner  = "prefix-owner"
+	OnCollisionPrefixDomain = "prefix-domain"
+	OnCollisionSuffixHash   = "suffix-hash"
+)
+
+// DefaultMissingSuffix is appended to the name of a repository renamed by the rename policy, when syncrun.missingsuffix is not set.
+const DefaultMissingSuffix = "-removed"
+
 type SyncRunOption struct {
 	ForcePush          bool   `koanf:"forcepush"`
 	IgnoreInvalidName  bool   `koanf:"ignoreinvalidname"`
 	CleanupInvalidName bool   `koanf:"cleanupinvalidname"`
 	ActiveFromLimit    string `koanf:"activefromlimit"`
+	Concurrency        int    `koanf:"concurrency"`
+	ContinueOnError    bool   `koanf:"continueonerror"`
+	StateFile          string `koanf:"statefile"`
+	Prune              bool   `koanf:"prune"`
+	OnMissing          string `koanf:"onmissing"`
+	MissingSuffix      string `koanf:"missingsuffix"`
+	Mode               string `koanf:"mode"`
+	OnCollision        string `koanf:"oncollision"`
+}
+
+// ProviderMirror reports whether the target mirrors the source repositories itself,
+// instead of having them cloned and pushed to it.
+func (p SyncRunOption) ProviderMirror() bool {
+	return strings.EqualFold(p.Mode, ModeProviderMirror)
+}
+
+// SourceMirror reports whether the source provider is to push mirror the repositories to the target,
+// instead of having them cloned and pushed to it.
+func (p SyncRunOption) SourceMirror() bool {
+	return strings.EqualFold(p.Mode, ModeSourceMirror)
+}
+
+// MissingPolicy returns what to do with a target repository whose source repository is gone, ignore when not set.
+func (p SyncRunOption) MissingPolicy() string {
+	if p.OnMissing == "" {
+		return OnMissingIgnore
+	}
+
+	return strings.ToLower(p.OnMissing)
+}
+
+// CollisionPolicy returns what to do with a repository named like a repository of another configuration at the target,
+// fail when not set.
+func (p SyncRunOption) CollisionPolicy() string {
+	if p.OnCollision == "" {
+		return OnCollisionFail
+	}
+
+	return strings.ToLower(p.OnCollision)
+}
+
+// RemovedSuffix returns the suffix the rename policy appends to the name of a target repository.
+func (p SyncRunOption) RemovedSuffix() string {
+	if p.MissingSuffix == "" {
+		return DefaultMissingSuffix
+	}
+
+	return p.MissingSuffix
 }
 
 func (p SyncRunOption) String() string {
@@ -21,11 +95,37 @@ func (p SyncRunOption) String() string {
 	parts = append(parts, "ForcePush: "+strconv.FormatBool(p.ForcePush))
 	parts = append(parts, "IgnoreInvalidName: "+strconv.FormatBool(p.IgnoreInvalidName))
 	parts = append(parts, "CleanupInvalidName: "+strconv.FormatBool(p.CleanupInvalidName))
+	parts = append(parts, "ContinueOnError: "+strconv.FormatBool(p.ContinueOnError))
+	parts = append(parts, "Prune: "+strconv.FormatBool(p.Prune))
 
 	if p.ActiveFromLimit != "" {
 		parts = append(parts, "ActiveFromLimit: "+p.ActiveFromLimit)
 	}
 
+	if p.Concurrency != 0 {
+		parts = append(parts, "Concurrency: "+strconv.Itoa(p.Concurrency))
+	}
+
+	if p.StateFile != "" {
+		parts = append(parts, "StateFile: "+p.StateFile)
+	}
+
+	if p.OnMissing != "" {
+		parts = append(parts, "OnMissing: "+p.OnMissing)
+	}
+
+	if p.MissingSuffix != "" {
+		parts = append(parts, "MissingSuffix: "+p.MissingSuffix)
+	}
+
+	if p.Mode != "" {
+		parts = append(parts, "Mode: "+p.Mode)
+	}
+
+	if p.OnCollision != "" {
+		parts = append(parts, "OnCollision: "+p.OnCollision)
+	}
+
 	parts = append(parts, "}")
 
 	return strings.Join(parts, " ")
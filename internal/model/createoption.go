@@ -19,16 +19,27 @@ type CreateProjectOption struct {
 	Description    string // A description of the repository
 	DefaultBranch  string // The name of the default branch (e.g., "main", "master")
 	Disabled       bool
+	Wiki           bool   // Whether to enable the wiki, also of a disabled repository
+	Releases       bool   // Whether to keep releases enabled, also of a disabled repository
+	Issues         bool   // Whether to keep issues enabled, also of a disabled repository
+	MirrorURL      string // The URL of the source repository a provider mirror pulls from
+	MirrorToken    string // The token the provider mirror authenticates to the source with
+	LFS            bool   // Whether the provider mirror fetches the Git LFS objects
 }
 
 // String provides a string representation of CreateOption.
 func (co CreateProjectOption) String() string {
-	return fmt.Sprintf("CreateOption{RepositoryName: %s, Visibility: %s, Description: %s, DefaultBranch: %s, Disabled: %t}",
+	return fmt.Sprintf("CreateOption{RepositoryName: %s, Visibility: %s, Description: %s, DefaultBranch: %s, Disabled: %t, Wiki: %t, Releases: %t, Issues: %t, MirrorURL: %s, LFS: %t}",
 		co.RepositoryName,
 		co.Visibility,
 		co.Description,
 		co.DefaultBranch,
-		co.Disabled)
+		co.Disabled,
+		co.Wiki,
+		co.Releases,
+		co.Issues,
+		co.MirrorURL,
+		co.LFS)
 }
 
 // DebugLog creates a debug log event with repository creation options.
@@ -38,7 +49,12 @@ func (co CreateProjectOption) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 				Str("visibility", co.Visibility).
 				Str("description", co.Description).
 				Str("default_branch", co.DefaultBranch).
-				Bool("Disabled", co.Disabled)
+				Bool("Disabled", co.Disabled).
+				Bool("Wiki", co.Wiki).
+				Bool("Releases", co.Releases).
+				Bool("Issues", co.Issues).
+				Str("MirrorURL", co.MirrorURL).
+				Bool("LFS", co.LFS)
 }
 
 // NewCreateOption creates a new CreateOption.
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"time"
+)
+
+// IncompleteImportHeader starts the body of an issue while it is imported, until its comments are added and it is closed.
+// An issue still starting with it is not recognized as imported, and is imported anew.
+const IncompleteImportHeader = "*Import incomplete, imported anew by the next sync*\n\n"
+
+// Metadata is what a repository has at its provider besides its git data: its labels, milestones, and issues.
+type Metadata struct {
+	Labels     []Label
+	Milestones []Milestone
+	Issues     []Issue // Issues and merge requests, with their comments when asked for
+}
+
+// Label is a label of issues and merge requests.
+type Label struct {
+	ID          string `json:"-"` // The provider identifier
+	Name        string `json:"name"`
+	Color       string `json:"color"` // Hex color, without a leading #
+	Description string `json:"description,omitempty"`
+}
+
+// Milestone groups issues and merge requests, usually by the release they are planned for.
+type Milestone struct {
+	ID          string     `json:"-"` // The provider identifier
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Closed      bool       `json:"closed"`
+	DueOn       *time.Time `json:"dueOn,omitempty"`
+}
+
+// Issue is an issue, or a merge request when MergeRequest is set.
+type Issue struct {
+	Number       int            `json:"number"` // The number of the issue in its repository, like #12
+	Title        string         `json:"title"`
+	Body         string         `json:"body"`
+	Author       string         `json:"author"`
+	CreatedAt    *time.Time     `json:"createdAt,omitempty"`
+	Closed       bool           `json:"closed"`
+	Labels       []Label        `json:"labels,omitempty"`
+	Milestone    *Milestone     `json:"milestone,omitempty"`
+	Comments     []IssueComment `json:"comments,omitempty"`
+	MergeRequest *MergeRequest  `json:"mergeRequest,omitempty"`
+}
+
+// MergeRequest holds what a merge request, or pull request, has besides what an issue has.
+type MergeRequest struct {
+	SourceBranch string `json:"sourceBranch"`
+	TargetBranch string `json:"targetBranch"`
+	Merged       bool   `json:"merged"`
+}
+
+// IssueComment is a comment on an issue or merge request.
+type IssueComment struct {
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// NameTemplateKey is the context key of the template repositories are named with at a target.
+type NameTemplateKey struct{}
+
+// nameTemplateFuncs are the functions a name template can use. The string to change comes last, so they can be piped to,
+// like {{.Name | replace "_" "-"}}.
+var nameTemplateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"replace": func(old, replacement, s string) string {
+		return strings.ReplaceAll(s, old, replacement)
+	},
+	"trimprefix": func(prefix, s string) string {
+		return strings.TrimPrefix(s, prefix)
+	},
+}
+
+// NameTemplateData is what a name template can name a repository by.
+type NameTemplateData struct {
+	// Name is the repository name, as it would be named without the template.
+	Name string
+
+	// Owner is the group or user of the repository at the source.
+	Owner string
+
+	// SourceDomain is the domain of the source provider.
+	SourceDomain string
+
+	// ProviderType is the type of the source provider, like github.
+	ProviderType string
+}
+
+// NameTemplate names the repositories of a source at a target.
+type NameTemplate struct {
+	Template     *template.Template
+	Owner        string
+	SourceDomain string
+	ProviderType string
+}
+
+// ParseNameTemplate parses the text of a project.nametemplate.
+func ParseNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("nametemplate").Funcs(nameTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse name template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// WithNameTemplate returns a new context where the repositories are named with the template.
+func WithNameTemplate(ctx context.Context, nameTemplate NameTemplate) context.Context {
+	return context.WithValue(ctx, NameTemplateKey{}, nameTemplate)
+}
+
+// Render returns the name the template gives the repository with the name.
+func (n NameTemplate) Render(name string) (string, error) {
+	var builder strings.Builder
+
+	data := NameTemplateData{Name: name, Owner: n.Owner, SourceDomain: n.SourceDomain, ProviderType: n.ProviderType}
+	if err := n.Template.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("render name template: %w", err)
+	}
+
+	return builder.String(), nil
+}
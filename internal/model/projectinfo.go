@@ -6,6 +6,8 @@ package model
 
 import (
 	"context"
+	"path"
+	"strings"
 	"time"
 
 	"itiquette/git-provider-sync/internal/provider/stringconvert"
@@ -13,6 +15,28 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// WikiSuffix ends the name of the wiki repository of a repository, as GitHub, GitLab and Gitea name them.
+const WikiSuffix = ".wiki"
+
+// SubgroupSeparatorKey is the context key of the separator a repository in a subgroup of the source
+// is named with at a target without subgroups.
+type SubgroupSeparatorKey struct{}
+
+// WithSubgroupSeparator returns a new context where a repository in a subgroup is named by its path joined with the separator,
+// like team_backend_api.
+func WithSubgroupSeparator(ctx context.Context, separator string) context.Context {
+	return context.WithValue(ctx, SubgroupSeparatorKey{}, separator)
+}
+
+// TargetNamesKey is the context key of the names repositories are given at a target, by original name,
+// when the name they would have is taken by a repository of another configuration.
+type TargetNamesKey struct{}
+
+// WithTargetNames returns a new context where the repositories with an original name in names are named after it.
+func WithTargetNames(ctx context.Context, names map[string]string) context.Context {
+	return context.WithValue(ctx, TargetNamesKey{}, names)
+}
+
 // ProjectInfo holds metadata about a repository.
 // It encapsulates various attributes that describe a repository's
 // properties and state.
@@ -41,12 +65,23 @@ type ProjectInfo struct {
 
 	ProjectID string
 
+	// Archived tells whether the repository is archived, read-only at the provider.
+	Archived bool
+
+	// HasWiki tells whether the repository has its wiki enabled at the provider.
+	HasWiki bool
+
+	// WikiOf is the original name of the repository this is the wiki of, empty for other repositories.
+	WikiOf string
+
 	CleanupName bool
 }
 
 // Name returns the repository name, optionally cleaned up based on CLI options.
 // If the CleanupName option is set in the context, it removes non-alphanumeric
-// characters from the original name.
+// characters from the original name. With a subgroup separator in the context,
+// the path of a repository in a subgroup is joined with it. A name template in the context names the repository
+// in its subgroup after that. A target name in the context replaces it all.
 //
 // Parameters:
 //   - ctx: A context.Context that may contain CLI options.
@@ -54,11 +89,68 @@ type ProjectInfo struct {
 // Returns:
 //   - A string representing the (possibly cleaned) repository name.
 func (rm ProjectInfo) Name(ctx context.Context) string {
+	if rm.IsWiki() {
+		return ProjectInfo{OriginalName: rm.WikiOf}.Name(ctx) + WikiSuffix
+	}
+
+	names, _ := ctx.Value(TargetNamesKey{}).(map[string]string)
+	if name, ok := names[rm.OriginalName]; ok {
+		return name
+	}
+
+	// A repository in a subgroup is named by its path, like team/backend/api, the names in it are cleaned up one by one
+	name := rm.OriginalName
 	if CLIOptions(ctx).CleanupName {
-		return stringconvert.RemoveNonAlphaNumericChars(ctx, rm.OriginalName)
+		segments := strings.Split(name, "/")
+		for index, segment := range segments {
+			segments[index] = stringconvert.RemoveNonAlphaNumericChars(ctx, segment)
+		}
+
+		name = strings.Join(segments, "/")
+	}
+
+	if separator, ok := ctx.Value(SubgroupSeparatorKey{}).(string); ok && separator != "" {
+		name = strings.ReplaceAll(name, "/", separator)
+	}
+
+	// The template is validated with the configuration, a name it can't render is left as it is
+	if nameTemplate, ok := ctx.Value(NameTemplateKey{}).(NameTemplate); ok {
+		dir, base := path.Split(name)
+		if rendered, err := nameTemplate.Render(base); err == nil {
+			name = dir + rendered
+		}
+	}
+
+	return name
+}
+
+// IsWiki reports whether this is the wiki repository of another repository.
+func (rm ProjectInfo) IsWiki() bool {
+	return rm.WikiOf != ""
+}
+
+// Wiki returns the wiki repository of the repository, named and cloned from after it.
+// Its default branch is not known until it is cloned.
+func (rm ProjectInfo) Wiki() ProjectInfo {
+	wiki := rm
+	wiki.OriginalName = rm.OriginalName + WikiSuffix
+	wiki.HTTPSURL = wikiURL(rm.HTTPSURL)
+	wiki.SSHURL = wikiURL(rm.SSHURL)
+	wiki.DefaultBranch = ""
+	wiki.ProjectID = ""
+	wiki.HasWiki = false
+	wiki.WikiOf = rm.OriginalName
+
+	return wiki
+}
+
+// wikiURL returns the clone URL of the wiki of the repository with the clone URL, <repository>.wiki.git.
+func wikiURL(cloneURL string) string {
+	if cloneURL == "" {
+		return ""
 	}
 
-	return rm.OriginalName
+	return strings.TrimSuffix(strings.TrimSuffix(cloneURL, "/"), ".git") + WikiSuffix + ".git"
 }
 
 // DebugLog creates a debug log event with repository metadata.
@@ -0,0 +1,46 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// PushMirrorOption represents options for a push mirror, set up at a source provider to push a repository to a target.
+type PushMirrorOption struct {
+	URL   string // The https URL of the target repository, without credentials
+	Token string // The token the source provider pushes to the target with
+}
+
+// String provides a string representation of PushMirrorOption, leaving out the token.
+func (po PushMirrorOption) String() string {
+	return fmt.Sprintf("PushMirrorOption{URL: %s}", po.URL)
+}
+
+// DebugLog creates a debug log event with push mirror options.
+func (po PushMirrorOption) DebugLog(logger *zerolog.Logger) *zerolog.Event {
+	return logger.Debug(). //nolint:zerologlint
+				Str("url", po.URL)
+}
+
+// Targets reports whether a push mirror url, with or without credentials, points at the repository of the option.
+func (po PushMirrorOption) Targets(mirrorURL string) bool {
+	mirror, mirrorErr := url.Parse(mirrorURL)
+	target, targetErr := url.Parse(po.URL)
+
+	if mirrorErr != nil || targetErr != nil {
+		return false
+	}
+
+	trimPath := func(path string) string {
+		return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	}
+
+	return strings.EqualFold(mirror.Host, target.Host) && strings.EqualFold(trimPath(mirror.Path), trimPath(target.Path))
+}
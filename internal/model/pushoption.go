@@ -7,6 +7,7 @@ package model
 import (
 	"fmt"
 	model "itiquette/git-provider-sync/internal/model/configuration"
+	"regexp"
 	"strings"
 
 	"github.com/rs/zerolog"
@@ -19,6 +20,7 @@ type PushOption struct {
 	Target     string   // The URL of the target repository
 	RefSpecs   []string // The reference specifications to push
 	Prune      bool     // Whether to prune remote branches that no longer exist locally
+	KeepRefs   []string // Patterns of the references at the target that are protected there, and kept when pruning
 	Force      bool     // Whether to force push (overwrite remote history)
 	HTTPClient model.HTTPClientOption
 	SSHClient  model.SSHClientOption
@@ -41,6 +43,7 @@ func (po PushOption) DebugLog(logger *zerolog.Logger) *zerolog.Event {
 				Str("target", po.Target).
 				Strs("refspecs", po.RefSpecs).
 				Bool("prune", po.Prune).
+				Strs("keeprefs", po.KeepRefs).
 				Bool("dryrun", po.DryRun).
 				Bool("force", po.Force).
 				Str("http_client", po.HTTPClient.String()).
@@ -76,3 +79,16 @@ func NewPushOption(target string, prune, force bool, httpClient model.HTTPClient
 		HTTPClient: httpClient,
 	}
 }
+
+// Keeps reports whether a reference at the target is kept when pruning, as it matches a pattern of a protected reference.
+// A * in a pattern matches any characters, also slashes, like the branch patterns of the providers.
+func (po PushOption) Keeps(refName string) bool {
+	for _, pattern := range po.KeepRefs {
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if matched, err := regexp.MatchString(expr, refName); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"time"
+)
+
+// Release is a release of a repository, built on one of its tags.
+type Release struct {
+	ID         string         `json:"-"` // The provider identifier, empty for a release not created yet
+	TagName    string         `json:"tag"`
+	Name       string         `json:"name"`
+	Notes      string         `json:"notes"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	CreatedAt  *time.Time     `json:"createdAt,omitempty"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a binary file attached to a release.
+type ReleaseAsset struct {
+	ID          string `json:"-"` // The provider identifier
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`            // Where the asset is downloaded from
+	File        string `json:"file,omitempty"` // Where an exported asset is stored, relative to the exported releases
+}
+
+// HasAsset reports whether the release has an asset with the name.
+func (r Release) HasAsset(name string) bool {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,6 +7,7 @@ package model
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
@@ -25,6 +26,7 @@ var (
 type Repository struct {
 	goGitRepository *git.Repository
 	ProjectMetaInfo ProjectInfo
+	release         func()
 }
 
 // NewGitGoRemoteOption creates a new RemoteConfig for go-git.
@@ -51,6 +53,21 @@ func (r Repository) GoGitRepository() *git.Repository {
 	return r.goGitRepository
 }
 
+// Release lets go of what the repository holds on to while it is used, like the lock of the cached mirror it was cloned into.
+// The repository is not to be used after it is released.
+func (r Repository) Release() {
+	if r.release != nil {
+		r.release()
+	}
+}
+
+// WithRelease returns the repository releasing what it holds on to with release, which is called once at most.
+func (r Repository) WithRelease(release func()) Repository {
+	r.release = sync.OnceFunc(release)
+
+	return r
+}
+
 // ProjectInfo returns the repository metadata.
 // This includes information such as the repository name, description, and URLs.
 func (r Repository) ProjectInfo() ProjectInfo {
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package model
+
+import (
+	"strings"
+
+	model "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// reviewHeads are the refs holding the head commit of each pull or merge request, by the providers serving them.
+var reviewHeads = map[string]string{
+	model.GITHUB:          "refs/pull/*/head",
+	model.GITEA:           "refs/pull/*/head",
+	model.GITLAB:          "refs/merge-requests/*/head",
+	model.BITBUCKETSERVER: "refs/pull-requests/*/from",
+}
+
+// ReviewRefsFetchSpec returns the refspec fetching the head refs of the pull or merge requests of a source
+// repository into the repositories.reviewrefs namespace, like refs/mirror/pr/<number>.
+// It is empty when no namespace is set, or the provider has no such refs.
+func ReviewRefsFetchSpec(providerConfig model.ProviderConfig) string {
+	namespace := reviewRefsNamespace(providerConfig)
+	head, found := reviewHeads[strings.ToLower(providerConfig.ProviderType)]
+
+	if namespace == "" || !found {
+		return ""
+	}
+
+	return "+" + head + ":" + namespace + "/*"
+}
+
+// ReviewRefsPushSpec returns the refspec pushing the refs fetched by ReviewRefsFetchSpec to the same namespace at the target.
+// The refs are force pushed, as pull requests are rebased and amended. It is empty when nothing is fetched.
+func ReviewRefsPushSpec(sourceProviderConfig model.ProviderConfig) string {
+	if ReviewRefsFetchSpec(sourceProviderConfig) == "" {
+		return ""
+	}
+
+	namespace := reviewRefsNamespace(sourceProviderConfig)
+
+	return "+" + namespace + "/*:" + namespace + "/*"
+}
+
+// SupportsReviewRefs reports whether the provider type serves the head refs of its pull or merge requests.
+func SupportsReviewRefs(providerType string) bool {
+	_, found := reviewHeads[strings.ToLower(providerType)]
+
+	return found
+}
+
+func reviewRefsNamespace(providerConfig model.ProviderConfig) string {
+	return strings.TrimSuffix(providerConfig.Repositories.ReviewRefs, "/")
+}
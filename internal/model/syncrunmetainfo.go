@@ -5,10 +5,34 @@
 package model
 
 import (
+	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// Failure categories of repositories that could not be synced.
+// They are used as keys in SyncRunMetainfo.Fail, next to the informational
+// keys for skipped (invalid), unchanged (uptodate) and already completed (resumed) repositories.
+const (
+	FailClone         = "clone"
+	FailName          = "name"
+	FailCreate        = "create"
+	FailPush          = "push"
+	FailMirror        = "mirror"
+	FailProtect       = "protect"
+	FailDefaultBranch = "default-branch"
+	FailRelease       = "release"
+	FailMetadata      = "metadata"
 )
 
+// FailureCategories lists the failure categories in the order a repository passes through them.
+var FailureCategories = []string{FailClone, FailName, FailCreate, FailPush, FailMirror, FailProtect, FailDefaultBranch, FailRelease, FailMetadata}
+
 // SyncRunMetainfoKey is used as a key for context values.
 // It allows SyncRunMetainfo to be stored and retrieved from a context.Context.
 type SyncRunMetainfoKey struct{}
@@ -17,7 +41,11 @@ type SyncRunMetainfoKey struct{}
 // It captures essential information about the synchronization process,
 // including source and target identifiers, total items processed,
 // and any failures encountered during the process.
+// Repositories may be processed concurrently, so use the methods rather than
+// the fields while a run is in progress.
 type SyncRunMetainfo struct {
+	mu sync.Mutex
+
 	// CtxID is a unique identifier for the synchronization context.
 	CtxID int
 
@@ -30,6 +58,9 @@ type SyncRunMetainfo struct {
 	// Total is the total number of items processed during the synchronization.
 	Total int
 
+	// Synced is the number of repositories successfully pushed to the target.
+	Synced int
+
 	// Fail is a map that stores any failures encountered during synchronization.
 	// The key is typically an identifier for the failure type or location,
 	// and the value is a slice of strings providing details about the failures.
@@ -39,16 +70,20 @@ type SyncRunMetainfo struct {
 // String provides a string representation of SyncRunMetainfo.
 // It formats all the fields of SyncRunMetainfo into a human-readable string,
 // including a detailed representation of any failures.
+// Failure keys and values are sorted, so the output doesn't depend on processing order.
 //
 // Returns:
 //   - A string representation of the SyncRunMetainfo instance.
-func (s SyncRunMetainfo) String() string {
+func (s *SyncRunMetainfo) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	var failInfo string
 
 	if len(s.Fail) > 0 {
 		var failures []string
-		for key, values := range s.Fail {
-			failures = append(failures, fmt.Sprintf("%s: %s", key, strings.Join(values, ", ")))
+		for _, key := range slices.Sorted(maps.Keys(s.Fail)) {
+			failures = append(failures, fmt.Sprintf("%s: %s", key, strings.Join(slices.Sorted(slices.Values(s.Fail[key])), ", ")))
 		}
 
 		failInfo = fmt.Sprintf("Failures: {%s}", strings.Join(failures, "; "))
@@ -56,8 +91,8 @@ func (s SyncRunMetainfo) String() string {
 		failInfo = "No failures"
 	}
 
-	return fmt.Sprintf("SyncRunMetainfo{CtxID: %d, Source: %s, Target: %s, Total: %d, %s}",
-		s.CtxID, s.Source, s.Target, s.Total, failInfo)
+	return fmt.Sprintf("SyncRunMetainfo{CtxID: %d, Source: %s, Target: %s, Total: %d, Synced: %d, %s}",
+		s.CtxID, s.Source, s.Target, s.Total, s.Synced, failInfo)
 }
 
 // NewSyncRunMetainfo creates a new SyncRunMetainfo instance.
@@ -91,10 +126,68 @@ func NewSyncRunMetainfo(ctxID int, source, target string, total int) *SyncRunMet
 //
 // Note: This method modifies the Fail map of the SyncRunMetainfo instance.
 // If an entry for the given key already exists, the new value is appended to the existing slice.
+// It is safe for concurrent use.
 func (s *SyncRunMetainfo) AddFailure(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.Fail[key] = append(s.Fail[key], value)
 }
 
+// Failures returns a sorted copy of the failure entries recorded for the given key.
+// Sorting keeps summaries stable no matter in which order repositories finished.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) Failures(key string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Sorted(slices.Values(s.Fail[key]))
+}
+
+// FailureCount returns the number of repositories recorded in any of the FailureCategories.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) FailureCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, category := range FailureCategories {
+		count += len(s.Fail[category])
+	}
+
+	return count
+}
+
+// IncrementSynced counts one more repository as successfully synced.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) IncrementSynced() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Synced++
+}
+
+// SyncedCount returns the number of repositories successfully synced so far.
+// It is safe for concurrent use.
+func (s *SyncRunMetainfo) SyncedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Synced
+}
+
+// AddRepositoryFailure logs why a repository failed and records it under the failure category
+// in the SyncRunMetainfo of the context, if there is one.
+// It is used when a run continues past failing repositories.
+func AddRepositoryFailure(ctx context.Context, category, repository string, err error) {
+	logger := log.Logger(ctx)
+	logger.Error().Err(err).Str("category", category).Str("name", repository).Msg("failed to sync repository, continuing")
+
+	if meta, ok := ctx.Value(SyncRunMetainfoKey{}).(*SyncRunMetainfo); ok {
+		meta.AddFailure(category, repository)
+	}
+}
+
 // Example usage:
 //
 //	metainfo := NewSyncRunMetainfo(1, "database_a", "database_b", 1000)
@@ -6,17 +6,36 @@ package archive
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/directory"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+	targetarchive "itiquette/git-provider-sync/internal/target/archive"
 )
 
 type Client struct{}
 
+func (Client) ArchiveProject(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
 func (Client) CreateProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption) (string, error) {
 	return "", nil
 }
 
+func (Client) DeleteProject(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
 func (Client) Name() string {
 	return config.ARCHIVE
 }
@@ -29,14 +48,154 @@ func (Client) IsValidProjectName(_ context.Context, _ string) bool {
 	return true
 }
 
-func (Client) ProjectInfos(_ context.Context, _ config.ProviderConfig, _ bool) ([]model.ProjectInfo, error) {
-	return nil, nil
+// ProjectInfos lists the repositories to restore when the archive provider is used as a source.
+// The latest archive of each repository is extracted to the temporary directory of the run.
+// FilterProjectInfos keeps the repositories the include/exclude rules of the configuration sync.
+func (Client) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return targetfilter.FilterIncludedExcludedGen()(ctx, cfg, projectinfos) //nolint:wrapcheck
+}
+
+func (Client) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Archive:ProjectInfos")
+
+	if cfg.ArchiveSourceDir() == "" {
+		return nil, nil
+	}
+
+	archives, err := latestArchives(cfg.ArchiveSourceDir())
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDirPath, err := model.GetTmpDirPath(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get temporary directory: %w", err)
+	}
+
+	handler := targetarchive.NewHandler()
+	projectinfos := make([]model.ProjectInfo, 0, len(archives))
+
+	for _, name := range slices.Sorted(maps.Keys(archives)) {
+		archivePath := archives[name]
+		extractDir := filepath.Join(tmpDirPath, "archivesource", strings.TrimSuffix(filepath.Base(archivePath), ".tar.gz"))
+
+		logger.Debug().Str("archive", archivePath).Str("extractDir", extractDir).Msg("Extracting archive")
+
+		// A resumed run reuses the temporary directory, possibly with a partial extraction
+		if err := os.RemoveAll(extractDir); err != nil {
+			return nil, fmt.Errorf("failed to clear %s: %w", extractDir, err)
+		}
+
+		if err := handler.ExtractArchive(ctx, archivePath, extractDir); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		projectinfo, err := directory.LocalProjectInfo(ctx, name, filepath.Join(extractDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read restored repository %s: %w", name, err)
+		}
+
+		projectinfos = append(projectinfos, projectinfo)
+	}
+
+	if filtering {
+		return Client{}.FilterProjectInfos(ctx, cfg, projectinfos)
+	}
+
+	return projectinfos, nil
 }
 
 func (Client) ProtectProject(_ context.Context, _, _, _ string) error {
 	return nil
 }
 
+func (Client) ProtectedRefs(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+
+func (Client) EnableWiki(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) RenameProject(_ context.Context, _ string, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
 func (Client) UnprotectProject(_ context.Context, _, _ string) error {
 	return nil
 }
+
+// latestArchives maps each repository name to the path of its most recent archive in sourceDir.
+func latestArchives(sourceDir string) (map[string]string, error) {
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive source directory %s: %w", sourceDir, err)
+	}
+
+	latest := map[string]string{}
+	timestamps := map[string]string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name, timestamp, ok := targetarchive.ArchiveName(entry.Name())
+		if !ok {
+			continue
+		}
+
+		// The timestamp format sorts chronologically as a string
+		if timestamp > timestamps[name] {
+			timestamps[name] = timestamp
+			latest[name] = filepath.Join(sourceDir, entry.Name())
+		}
+	}
+
+	return latest, nil
+}
+
+func (Client) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	return nil, interfaces.ErrNotSupported
+}
+
+func (Client) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	return model.Release{}, interfaces.ErrNotSupported
+}
+
+func (Client) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) SupportsPushMirror() bool {
+	return false
+}
+
+func (Client) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	return model.Metadata{}, interfaces.ErrNotSupported
+}
+
+func (Client) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	return model.Label{}, interfaces.ErrNotSupported
+}
+
+func (Client) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	return model.Milestone{}, interfaces.ErrNotSupported
+}
+
+func (Client) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	return interfaces.ErrNotSupported
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	targetarchive "itiquette/git-provider-sync/internal/target/archive"
+	"itiquette/git-provider-sync/internal/target/gitlib"
+)
+
+// createArchive archives a repository with one commit on the given default branch, as the archive target does.
+func createArchive(t *testing.T, archivePath string, name string, defaultBranch string, description string) {
+	t.Helper()
+
+	repoDir := filepath.Join(t.TempDir(), name)
+
+	repo, err := git.PlainInitWithOptions(repoDir, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName(defaultBranch)},
+	})
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("initial", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, gitlib.NewOperation().SetDescription(context.Background(), repoDir, description))
+	require.NoError(t, targetarchive.NewHandler().CreateArchive(context.Background(), repoDir, archivePath, name))
+}
+
+func TestClient_ProjectInfos(t *testing.T) {
+	require := require.New(t)
+	sourceDir := t.TempDir()
+
+	createArchive(t, filepath.Join(sourceDir, "repo_20240101_120000_1704110400000.tar.gz"), "repo", "old", "older")
+	createArchive(t, filepath.Join(sourceDir, "repo_20240601_120000_1717243200000.tar.gz"), "repo", "main", "newer")
+	createArchive(t, filepath.Join(sourceDir, "other_20240301_120000_1709294400000.tar.gz"), "other", "trunk", "")
+	require.NoError(os.WriteFile(filepath.Join(sourceDir, "notes.txt"), []byte("not an archive"), 0o600))
+
+	ctx, err := model.CreateTmpDir(context.Background(), t.TempDir(), "test")
+	require.NoError(err)
+
+	tmpDir, err := model.GetTmpDirPath(ctx)
+	require.NoError(err)
+
+	cfg := config.ProviderConfig{
+		ProviderType: config.ARCHIVE,
+		Additional:   map[string]string{"archivesourcedir": sourceDir},
+	}
+
+	infos, err := Client{}.ProjectInfos(ctx, cfg, false)
+	require.NoError(err)
+	require.Len(infos, 2)
+
+	require.Equal("other", infos[0].OriginalName)
+	require.Equal("trunk", infos[0].DefaultBranch)
+
+	require.Equal("repo", infos[1].OriginalName)
+	require.Equal("main", infos[1].DefaultBranch)
+	require.Equal("newer", infos[1].Description)
+	require.Equal(filepath.Join(tmpDir, "archivesource", "repo_20240601_120000_1717243200000", "repo"), infos[1].HTTPSURL)
+
+	cfg.Repositories.Exclude = "other"
+	infos, err = Client{}.ProjectInfos(ctx, cfg, true)
+	require.NoError(err)
+	require.Len(infos, 1)
+}
+
+func TestArchiveName(t *testing.T) {
+	tests := []struct {
+		filename  string
+		name      string
+		timestamp string
+		ok        bool
+	}{
+		{"repo_20240601_120000_1717243200000.tar.gz", "repo", "_20240601_120000_1717243200000", true},
+		{"/backups/my_repo_20240601_120000_1717243200000.tar.gz", "my_repo", "_20240601_120000_1717243200000", true},
+		{"repo.tar.gz", "", "", false},
+		{"repo_20240601_120000_1717243200000", "", "", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.filename, func(t *testing.T) {
+			name, timestamp, ok := targetarchive.ArchiveName(tabletest.filename)
+			require.Equal(t, tabletest.ok, ok)
+			require.Equal(t, tabletest.name, name)
+			require.Equal(t, tabletest.timestamp, timestamp)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package assetfile moves release assets between providers and local files.
+package assetfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+var ErrDownload = errors.New("failed to download release asset")
+
+// Download fetches the file at the URL to path, sending the headers along, like the authorization of the provider.
+func Download(ctx context.Context, client *http.Client, url string, header http.Header, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDownload, err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDownload, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: GET %s: %s", ErrDownload, url, resp.Status)
+	}
+
+	return Write(resp.Body, path)
+}
+
+// Write writes the content to the file at path, replacing any file there.
+func Write(content io.Reader, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDownload, err)
+	}
+
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrDownload, path, err)
+	}
+
+	return nil
+}
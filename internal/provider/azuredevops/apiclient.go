@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+type APIClient struct {
+	raw               *restClient
+	projectService    *ProjectService
+	protectionService *ProtectionService
+	filterService     *FilterService
+}
+
+func (api APIClient) ArchiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:ArchiveProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("AzureDevOps:ArchiveProject")
+
+	err := api.projectService.archiveProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive AzureDevOps project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:CreateProject")
+	opt.DebugLog(logger).Msg("AzureDevOps:CreateOption")
+
+	projectID, err := api.projectService.createProject(ctx, cfg, opt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Azure DevOps project. err: %w", err)
+	}
+
+	return projectID, nil
+}
+
+func (api APIClient) DeleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:DeleteProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("AzureDevOps:DeleteProject")
+
+	err := api.projectService.deleteProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete AzureDevOps project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:IsValidProjectName")
+	logger.Debug().Str("name", name).Msg("AzureDevOps:IsValidProjectName")
+
+	if !IsValidAzureDevOpsRepositoryName(name) {
+		logger.Debug().Str("name", name).Msg("Invalid Azure DevOps repository name")
+
+		return false
+	}
+
+	return true
+}
+
+func (api APIClient) Name() string {
+	return config.AZUREDEVOPS
+}
+
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return api.filterService.FilterProjectinfos(ctx, cfg, projectinfos)
+}
+
+func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:ProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("AzureDevOps:ProjectInfos")
+
+	projectinfos, err := api.projectService.getProjectInfos(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository infos. err: %w", err)
+	}
+
+	if filtering {
+		return api.FilterProjectInfos(ctx, cfg, projectinfos)
+	}
+
+	return projectinfos, nil
+}
+
+func (api APIClient) ProtectProject(ctx context.Context, _ string, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:Protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("AzureDevOps:Protect")
+
+	err := api.protectionService.protect(ctx, branch, projectIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to to protect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("AzureDevOps:ProtectedRefs")
+
+	refs, err := api.protectionService.protectedRefs(ctx, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected refs. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return refs, nil
+}
+
+// Azure DevOps wikis are not synced.
+func (api APIClient) EnableWiki(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) RenameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:RenameProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("newName", newName).Msg("AzureDevOps:RenameProject")
+
+	err := api.projectService.renameProject(ctx, owner, projectName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename AzureDevOps project: %w", err)
+	}
+
+	return nil
+}
+
+// SetDefaultBranch sets the default branch, owner is expected in organization/project format.
+func (api APIClient) SetDefaultBranch(ctx context.Context, owner string, projectName string, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:SetDefaultBranch")
+	logger.Debug().Str("branch", branch).Str("owner", owner).Str("projectName", projectName).Msg("AzureDevOps:SetDefaultBranch")
+
+	err := api.projectService.setDefaultBranch(ctx, owner, projectName, branch)
+	if err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) UnprotectProject(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:UnprotectProject")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("AzureDevOps:UnprotectProject")
+
+	err := api.protectionService.unprotect(ctx, branch, projectIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to to unprotect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+// Azure DevOps has no releases of repositories, its releases are pipeline deployments.
+func (api APIClient) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	return nil, interfaces.ErrNotSupported
+}
+
+func (api APIClient) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	return model.Release{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// MirrorProject is not supported, Azure DevOps has no pull mirrors.
+func (api APIClient) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// SupportsPushMirror is false, Azure DevOps has no push mirrors.
+func (api APIClient) SupportsPushMirror() bool {
+	return false
+}
+
+func (api APIClient) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+// Azure DevOps keeps work items in Azure Boards, not with the repositories.
+func (api APIClient) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	return model.Metadata{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	return model.Label{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	return model.Milestone{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	return interfaces.ErrNotSupported
+}
+
+func NewAzureDevOpsAPIClient(ctx context.Context, opt model.GitProviderClientOption, httpClient *http.Client) (APIClient, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:NewAzureDevOpsAPIClient")
+
+	baseURL := "https://dev.azure.com"
+
+	// A custom domain is an Azure DevOps Server, including the collection path, e.g. devops.example.com/tfs
+	if opt.Domain != "" {
+		baseURL = opt.DomainWithScheme(opt.HTTPClient.Scheme)
+	}
+
+	rawClient := newRestClient(httpClient, baseURL, opt.HTTPClient.Token)
+
+	return APIClient{
+		raw:               rawClient,
+		projectService:    NewProjectService(rawClient),
+		protectionService: NewProtectionService(rawClient),
+		filterService:     NewFilter(),
+	}, nil
+}
@@ -0,0 +1,338 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// fakeAzureDevOps is a minimal in-memory fake of the Azure DevOps REST API for one organization/project.
+type fakeAzureDevOps struct {
+	mu           sync.Mutex
+	repositories []repository
+	policies     []policyConfiguration
+	server       *httptest.Server
+}
+
+func newFakeAzureDevOps(t *testing.T) *fakeAzureDevOps {
+	t.Helper()
+
+	fake := &fakeAzureDevOps{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /myorg/_apis/projects/{project}", fake.getProject)
+	mux.HandleFunc("GET /myorg/{project}/_apis/git/repositories", fake.listRepositories)
+	mux.HandleFunc("POST /myorg/{project}/_apis/git/repositories", fake.createRepository)
+	mux.HandleFunc("GET /myorg/{project}/_apis/git/repositories/{repo}", fake.getRepository)
+	mux.HandleFunc("PATCH /myorg/{project}/_apis/git/repositories/{repo}", fake.updateRepository)
+	mux.HandleFunc("DELETE /myorg/{project}/_apis/git/repositories/{repo}", fake.deleteRepository)
+	mux.HandleFunc("GET /myorg/{project}/_apis/git/policy/configurations", fake.listPolicies)
+	mux.HandleFunc("POST /myorg/{project}/_apis/policy/configurations", fake.createPolicy)
+	mux.HandleFunc("DELETE /myorg/{project}/_apis/policy/configurations/{id}", fake.deletePolicy)
+
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != apiVersion {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		if _, password, ok := r.BasicAuth(); !ok || password != "pat" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (f *fakeAzureDevOps) getProject(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(teamProject{ID: "project-id", Name: r.PathValue("project"), Visibility: "private"})
+}
+
+func (f *fakeAzureDevOps) listRepositories(w http.ResponseWriter, _ *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(list[repository]{Count: len(f.repositories), Value: f.repositories})
+}
+
+func (f *fakeAzureDevOps) find(nameOrID string) int {
+	for index, repo := range f.repositories {
+		if repo.ID == nameOrID || repo.Name == nameOrID {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func (f *fakeAzureDevOps) createRepository(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var opt createRepositoryOption
+	_ = json.NewDecoder(r.Body).Decode(&opt)
+
+	if opt.Project.ID != "project-id" {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	repo := repository{ID: "id-" + opt.Name, Name: opt.Name}
+	f.repositories = append(f.repositories, repo)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(repo)
+}
+
+func (f *fakeAzureDevOps) getRepository(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index := f.find(r.PathValue("repo"))
+	if index < 0 {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(f.repositories[index])
+}
+
+func (f *fakeAzureDevOps) updateRepository(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Updates are only accepted by repository id
+	index := f.find(r.PathValue("repo"))
+	if index < 0 || f.repositories[index].ID != r.PathValue("repo") {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	var body struct {
+		Name          *string `json:"name"`
+		DefaultBranch *string `json:"defaultBranch"`
+		IsDisabled    *bool   `json:"isDisabled"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	if body.Name != nil {
+		f.repositories[index].Name = *body.Name
+	}
+
+	if body.DefaultBranch != nil {
+		f.repositories[index].DefaultBranch = *body.DefaultBranch
+	}
+
+	if body.IsDisabled != nil {
+		f.repositories[index].IsDisabled = *body.IsDisabled
+	}
+
+	_ = json.NewEncoder(w).Encode(f.repositories[index])
+}
+
+func (f *fakeAzureDevOps) deleteRepository(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Repositories are only deleted by repository id
+	index := f.find(r.PathValue("repo"))
+	if index < 0 || f.repositories[index].ID != r.PathValue("repo") {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	f.repositories = append(f.repositories[:index], f.repositories[index+1:]...)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeAzureDevOps) listPolicies(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var matching []policyConfiguration
+
+	for _, policy := range f.policies {
+		scope, _ := json.Marshal(policy.Settings["scope"])
+		if strings.Contains(string(scope), r.URL.Query().Get("repositoryId")) && strings.Contains(string(scope), r.URL.Query().Get("refName")) {
+			matching = append(matching, policy)
+		}
+	}
+
+	_ = json.NewEncoder(w).Encode(list[policyConfiguration]{Count: len(matching), Value: matching})
+}
+
+func (f *fakeAzureDevOps) createPolicy(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var policy policyConfiguration
+	_ = json.NewDecoder(r.Body).Decode(&policy)
+	policy.ID = len(f.policies) + 1
+	f.policies = append(f.policies, policy)
+
+	_ = json.NewEncoder(w).Encode(policy)
+}
+
+func (f *fakeAzureDevOps) deletePolicy(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var kept []policyConfiguration
+
+	for _, policy := range f.policies {
+		if strconv.Itoa(policy.ID) != r.PathValue("id") {
+			kept = append(kept, policy)
+		}
+	}
+
+	f.policies = kept
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeAzureDevOps) client(t *testing.T) APIClient {
+	t.Helper()
+
+	opt := model.GitProviderClientOption{
+		ProviderType: config.AZUREDEVOPS,
+		Domain:       strings.TrimPrefix(f.server.URL, "http://"),
+		HTTPClient:   config.HTTPClientOption{Scheme: "http", Token: "pat"},
+	}
+
+	client, err := NewAzureDevOpsAPIClient(context.Background(), opt, f.server.Client())
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestAPIClient_ProjectInfos(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeAzureDevOps(t)
+
+	fake.repositories = []repository{
+		{
+			ID: "1", Name: "repo1", DefaultBranch: "refs/heads/main",
+			RemoteURL: "https://myorg@dev.azure.com/myorg/proj/_git/repo1",
+			SSHURL:    "git@ssh.dev.azure.com:v3/myorg/proj/repo1",
+			Project:   teamProject{Visibility: "private"},
+		},
+		{ID: "2", Name: "fork", IsFork: true},
+		{ID: "3", Name: "disabled", IsDisabled: true},
+	}
+
+	cfg := config.ProviderConfig{ProviderType: config.AZUREDEVOPS, Group: "myorg/proj"}
+
+	infos, err := fake.client(t).ProjectInfos(context.Background(), cfg, true)
+	require.NoError(err)
+	require.Equal([]model.ProjectInfo{{
+		OriginalName:  "repo1",
+		HTTPSURL:      "https://dev.azure.com/myorg/proj/_git/repo1",
+		SSHURL:        "git@ssh.dev.azure.com:v3/myorg/proj/repo1",
+		DefaultBranch: "main",
+		Visibility:    "private",
+		ProjectID:     "myorg/proj/1",
+	}}, infos)
+
+	cfg.Git.IncludeForks = true
+	infos, err = fake.client(t).ProjectInfos(context.Background(), cfg, false)
+	require.NoError(err)
+	require.Len(infos, 2)
+
+	_, err = fake.client(t).ProjectInfos(context.Background(), config.ProviderConfig{Group: "myorg"}, false)
+	require.ErrorIs(err, ErrInvalidGroup)
+}
+
+func TestAPIClient_CreateProject(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeAzureDevOps(t)
+	client := fake.client(t)
+
+	cfg := config.ProviderConfig{ProviderType: config.AZUREDEVOPS, Group: "myorg/proj"}
+	projectID, err := client.CreateProject(context.Background(), cfg, model.NewCreateOption("mirror", "private", "desc", "main", false))
+	require.NoError(err)
+	require.Equal("myorg/proj/id-mirror", projectID)
+
+	require.NoError(client.SetDefaultBranch(context.Background(), "myorg/proj", "mirror", "develop"))
+	require.Equal("refs/heads/develop", fake.repositories[0].DefaultBranch)
+
+	require.Error(client.SetDefaultBranch(context.Background(), "myorg/proj", "missing", "develop"))
+}
+
+func TestAPIClient_ArchiveRenameDelete(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeAzureDevOps(t)
+	fake.repositories = []repository{{ID: "id-first", Name: "first"}, {ID: "id-second", Name: "second"}}
+	client := fake.client(t)
+	ctx := context.Background()
+
+	require.NoError(client.RenameProject(ctx, "myorg/proj", "first", "first-removed"))
+	require.Equal("first-removed", fake.repositories[0].Name)
+
+	require.NoError(client.ArchiveProject(ctx, "myorg/proj", "first-removed"))
+	require.True(fake.repositories[0].IsDisabled)
+
+	require.NoError(client.DeleteProject(ctx, "myorg/proj", "second"))
+	require.Len(fake.repositories, 1)
+
+	require.Error(client.DeleteProject(ctx, "myorg/proj", "missing"))
+	require.Error(client.RenameProject(ctx, "invalid", "first-removed", "first"))
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeAzureDevOps(t)
+	client := fake.client(t)
+
+	require.NoError(client.ProtectProject(context.Background(), "myorg/proj", "main", "myorg/proj/repo-id"))
+	require.Len(fake.policies, 1)
+	require.Equal(minimumReviewersPolicyType, fake.policies[0].Type.ID)
+
+	require.NoError(client.UnprotectProject(context.Background(), "main", "myorg/proj/repo-id"))
+	require.Empty(fake.policies)
+
+	require.Error(client.UnprotectProject(context.Background(), "main", "repo-id"))
+}
+
+func TestIsValidAzureDevOpsRepositoryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"my-repo", true},
+		{"My_Repo.v2", true},
+		{"_hidden", false},
+		{"trailing.", false},
+		{"CON", false},
+		{strings.Repeat("a", 65), false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsValidAzureDevOpsRepositoryName(tabletest.name))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const apiVersion = "7.1"
+
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
+// restClient is a minimal client for the Azure DevOps REST API.
+type restClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// list is the generic collection envelope of the Azure DevOps API.
+type list[T any] struct {
+	Count int `json:"count"`
+	Value []T `json:"value"`
+}
+
+type teamProject struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Visibility     string     `json:"visibility"`
+	LastUpdateTime *time.Time `json:"lastUpdateTime"`
+}
+
+type repository struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	DefaultBranch string      `json:"defaultBranch"`
+	RemoteURL     string      `json:"remoteUrl"`
+	SSHURL        string      `json:"sshUrl"`
+	IsDisabled    bool        `json:"isDisabled"`
+	IsFork        bool        `json:"isFork"`
+	Project       teamProject `json:"project"`
+}
+
+type policyScope struct {
+	RepositoryID string `json:"repositoryId"`
+	RefName      string `json:"refName"`
+	MatchKind    string `json:"matchKind"`
+}
+
+type policyConfiguration struct {
+	ID         int  `json:"id,omitempty"`
+	IsEnabled  bool `json:"isEnabled"`
+	IsBlocking bool `json:"isBlocking"`
+	Type       struct {
+		ID string `json:"id"`
+	} `json:"type"`
+	Settings map[string]any `json:"settings"`
+}
+
+func newRestClient(httpClient *http.Client, baseURL, token string) *restClient {
+	return &restClient{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/"), token: token}
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		reader = bytes.NewReader(payload)
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	requestURL := c.baseURL + "/" + strings.TrimLeft(path, "/") + separator + "api-version=" + apiVersion
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Personal access tokens are sent as basic auth password with an empty username
+	if c.token != "" {
+		req.SetBasicAuth("", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to do %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s %s: %d %s", ErrUnexpectedStatus, method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// validNameRegex defines the allowed characters in an Azure DevOps repository name.
+	// It allows alphanumeric characters, hyphens, underscores and dots, and must not start with an underscore or dot.
+	validNameRegex = regexp.MustCompile(`^[A-Za-z0-9-][A-Za-z0-9-_\.]*$`)
+
+	// reservedNames are names Azure DevOps does not allow, compared case-insensitively.
+	reservedNames = map[string]bool{
+		"con": true, "prn": true, "aux": true, "nul": true,
+		"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+		"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+	}
+
+	// maxNameLength is the maximum allowed length for an Azure DevOps repository name.
+	maxNameLength = 64
+)
+
+// IsValidAzureDevOpsRepositoryName checks if the given name is a valid Azure DevOps repository name.
+// It applies the following rules:
+//  1. The name must not be a reserved name (e.g., "CON" or "LPT1").
+//  2. The name must only contain alphanumeric characters, hyphens, underscores or dots,
+//     not start with an underscore or dot and not end with a dot.
+//  3. The name must not exceed the maximum allowed length (64 characters).
+//
+// Parameters:
+//   - name: The repository name to validate.
+//
+// Returns:
+//   - bool: true if the name is valid, false otherwise.
+func IsValidAzureDevOpsRepositoryName(name string) bool {
+	return !reservedNames[strings.ToLower(name)] &&
+		validNameRegex.MatchString(name) &&
+		!strings.HasSuffix(name, ".") &&
+		len(name) <= maxNameLength
+}
@@ -0,0 +1,250 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrInvalidGroup = errors.New("azure devops group must be in organization/project format")
+
+type ProjectService struct {
+	client *restClient
+}
+
+func NewProjectService(client *restClient) *ProjectService {
+	return &ProjectService{client: client}
+}
+
+type createRepositoryOption struct {
+	Name    string `json:"name"`
+	Project struct {
+		ID string `json:"id"`
+	} `json:"project"`
+}
+
+func (p ProjectService) createProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:createProject")
+	opt.DebugLog(logger).Msg("AzureDevOps:CreateOption")
+
+	organization, project, err := SplitGroup(cfg.Group)
+	if err != nil {
+		return "", err
+	}
+
+	var teamProj teamProject
+	if err := p.client.do(ctx, http.MethodGet, url.PathEscape(organization)+"/_apis/projects/"+url.PathEscape(project), nil, &teamProj); err != nil {
+		return "", fmt.Errorf("create: failed to get project. project: %s, err: %w", project, err)
+	}
+
+	// Azure DevOps repositories have neither description nor visibility, both are set on the project
+	logger.Debug().Str("description", opt.Description).Str("visibility", opt.Visibility).Msg("AzureDevOps: ignoring repository description and visibility")
+
+	createOpt := createRepositoryOption{Name: opt.RepositoryName}
+	createOpt.Project.ID = teamProj.ID
+
+	var createdRepo repository
+
+	err = p.client.do(ctx, http.MethodPost, reposPath(organization, project), createOpt, &createdRepo)
+	if err != nil {
+		return "", fmt.Errorf("create: failed to create project. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Trace().Str("name", opt.RepositoryName).Msg("Project created successfully")
+
+	return projectID(organization, project, createdRepo.ID), nil
+}
+
+func (p ProjectService) getProjectInfos(ctx context.Context, cfg config.ProviderConfig) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:getProjectInfos")
+
+	organization, project, err := SplitGroup(cfg.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos list[repository]
+	if err := p.client.do(ctx, http.MethodGet, reposPath(organization, project), nil, &repos); err != nil {
+		return nil, fmt.Errorf("failed to list project repositories. project: %s, err: %w", cfg.Group, err)
+	}
+
+	logger.Debug().Int("total_repositories", len(repos.Value)).Msg("Total fetched repositories projectinfo")
+
+	projectinfos := make([]model.ProjectInfo, 0, len(repos.Value))
+
+	for _, repo := range repos.Value {
+		if repo.IsDisabled {
+			logger.Debug().Str("repo", repo.Name).Msg("skipping disabled repository")
+
+			continue
+		}
+
+		if !cfg.Git.IncludeForks && repo.IsFork {
+			continue
+		}
+
+		projectinfos = append(projectinfos, newProjectInfo(organization, project, repo))
+	}
+
+	return projectinfos, nil
+}
+
+func (p ProjectService) setDefaultBranch(ctx context.Context, owner string, projectName string, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:setDefaultBranch")
+
+	organization, project, err := SplitGroup(owner)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{"defaultBranch": "refs/heads/" + branch}
+
+	if err := p.updateRepository(ctx, organization, project, projectName, body); err != nil {
+		return fmt.Errorf("failed to set default branch. err: %w", err)
+	}
+
+	return nil
+}
+
+// archiveProject disables the repository, the closest Azure DevOps has to archiving it.
+// A disabled repository can't be read or written until it is enabled again.
+func (p ProjectService) archiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:archiveProject")
+
+	organization, project, err := SplitGroup(owner)
+	if err != nil {
+		return err
+	}
+
+	if err := p.updateRepository(ctx, organization, project, projectName, map[string]bool{"isDisabled": true}); err != nil {
+		return fmt.Errorf("failed to archive project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) renameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:renameProject")
+
+	organization, project, err := SplitGroup(owner)
+	if err != nil {
+		return err
+	}
+
+	if err := p.updateRepository(ctx, organization, project, projectName, map[string]string{"name": newName}); err != nil {
+		return fmt.Errorf("failed to rename project. name: %s, newName: %s, err: %w", projectName, newName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) deleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:deleteProject")
+
+	organization, project, err := SplitGroup(owner)
+	if err != nil {
+		return err
+	}
+
+	repo, err := p.repository(ctx, organization, project, projectName)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.do(ctx, http.MethodDelete, reposPath(organization, project)+"/"+repo.ID, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+// updateRepository patches the repository with the name, which Azure DevOps only addresses by its ID.
+func (p ProjectService) updateRepository(ctx context.Context, organization, project, projectName string, body any) error {
+	repo, err := p.repository(ctx, organization, project, projectName)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.do(ctx, http.MethodPatch, reposPath(organization, project)+"/"+repo.ID, body, nil); err != nil {
+		return fmt.Errorf("failed to update repository. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) repository(ctx context.Context, organization, project, projectName string) (repository, error) {
+	var repo repository
+	if err := p.client.do(ctx, http.MethodGet, reposPath(organization, project)+"/"+url.PathEscape(projectName), nil, &repo); err != nil {
+		return repository{}, fmt.Errorf("failed to get repository. name: %s, err: %w", projectName, err)
+	}
+
+	return repo, nil
+}
+
+func newProjectInfo(organization, project string, repo repository) model.ProjectInfo {
+	return model.ProjectInfo{
+		OriginalName:  repo.Name,
+		HTTPSURL:      withoutUser(repo.RemoteURL),
+		SSHURL:        repo.SSHURL,
+		DefaultBranch: strings.TrimPrefix(repo.DefaultBranch, "refs/heads/"),
+		Visibility:    strings.ToLower(repo.Project.Visibility),
+		ProjectID:     projectID(organization, project, repo.ID),
+	}
+}
+
+// withoutUser strips the organization name Azure DevOps embeds as user in https remote URLs.
+func withoutUser(remoteURL string) string {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return remoteURL
+	}
+
+	parsed.User = nil
+
+	return parsed.String()
+}
+
+// SplitGroup splits an Azure DevOps group in organization/project format.
+func SplitGroup(group string) (string, string, error) {
+	organization, project, found := strings.Cut(group, "/")
+	if !found || organization == "" || project == "" || strings.Contains(project, "/") {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidGroup, group)
+	}
+
+	return organization, project, nil
+}
+
+// projectID is the organization/project/repositoryID identifier of a repository.
+func projectID(organization, project, repositoryID string) string {
+	return organization + "/" + project + "/" + repositoryID
+}
+
+func splitProjectID(projectIDStr string) (string, string, string, error) {
+	parts := strings.Split(projectIDStr, "/")
+	if len(parts) != 3 {
+		return "", "", "", fmt.Errorf("string was not in a/b/c format, failed to split: path: %s", projectIDStr)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
+
+func reposPath(organization, project string) string {
+	return url.PathEscape(organization) + "/" + url.PathEscape(project) + "/_apis/git/repositories"
+}
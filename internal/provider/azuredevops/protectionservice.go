@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// minimumReviewersPolicyType is the id of the built-in "Minimum number of reviewers" policy type,
+// which blocks direct pushes to the branch it is scoped to.
+const minimumReviewersPolicyType = "fa4e907d-c16b-4a4c-9dfa-4906e5d171dd"
+
+type ProtectionService struct {
+	client *restClient
+}
+
+func NewProtectionService(client *restClient) *ProtectionService {
+	return &ProtectionService{client: client}
+}
+
+func (p ProtectionService) protect(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("AzureDevOps:protect")
+
+	organization, project, repositoryID, err := splitProjectID(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	policy := policyConfiguration{
+		IsEnabled:  true,
+		IsBlocking: true,
+		Settings: map[string]any{
+			"minimumApproverCount": 1,
+			"creatorVoteCounts":    false,
+			"resetOnSourcePush":    true,
+			"scope": []policyScope{
+				{RepositoryID: repositoryID, RefName: "refs/heads/" + branch, MatchKind: "exact"},
+			},
+		},
+	}
+	policy.Type.ID = minimumReviewersPolicyType
+
+	if err := p.client.do(ctx, http.MethodPost, policyPath(organization, project), policy, nil); err != nil {
+		return fmt.Errorf("failed to create branch policy. branch: %s. err: %w", branch, err)
+	}
+
+	return nil
+}
+
+func (p ProtectionService) unprotect(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:unprotect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("AzureDevOps:unprotect")
+
+	organization, project, repositoryID, err := splitProjectID(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"repositoryId": {repositoryID}, "refName": {"refs/heads/" + branch}}
+
+	var policies list[policyConfiguration]
+
+	err = p.client.do(ctx, http.MethodGet, url.PathEscape(organization)+"/"+url.PathEscape(project)+"/_apis/git/policy/configurations?"+query.Encode(), nil, &policies)
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return fmt.Errorf("failed to list branch policies. branch: %s. err: %w", branch, err)
+		}
+
+		return nil
+	}
+
+	for _, policy := range policies.Value {
+		err := p.client.do(ctx, http.MethodDelete, policyPath(organization, project)+"/"+strconv.Itoa(policy.ID), nil, nil)
+		if err != nil && !strings.Contains(err.Error(), "404") {
+			return fmt.Errorf("failed to delete branch policy. id: %d. err: %w", policy.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// protectedRefs returns the branches with a blocking policy of the repository as reference patterns,
+// a branch with a required policy can't be deleted by a push.
+func (p ProtectionService) protectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering AzureDevOps:protectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("AzureDevOps:protectedRefs")
+
+	organization, project, repositoryID, err := splitProjectID(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"repositoryId": {repositoryID}}
+
+	var policies list[policyConfiguration]
+
+	err = p.client.do(ctx, http.MethodGet, url.PathEscape(organization)+"/"+url.PathEscape(project)+"/_apis/git/policy/configurations?"+query.Encode(), nil, &policies)
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return nil, fmt.Errorf("failed to list branch policies. err: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	var refs []string
+
+	for _, policy := range policies.Value {
+		if !policy.IsEnabled || !policy.IsBlocking {
+			continue
+		}
+
+		scopes, _ := policy.Settings["scope"].([]any)
+		for _, scope := range scopes {
+			scopeSettings, _ := scope.(map[string]any)
+			refName, _ := scopeSettings["refName"].(string)
+			matchKind, _ := scopeSettings["matchKind"].(string)
+
+			switch {
+			case refName == "":
+				refs = append(refs, "refs/heads/*")
+			case strings.EqualFold(matchKind, "prefix"):
+				refs = append(refs, refName+"*")
+			default:
+				refs = append(refs, refName)
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+func policyPath(organization, project string) string {
+	return url.PathEscape(organization) + "/" + url.PathEscape(project) + "/_apis/policy/configurations"
+}
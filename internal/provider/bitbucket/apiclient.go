@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const defaultDomain = "bitbucket.org"
+
+type APIClient struct {
+	raw               *restClient
+	projectService    *ProjectService
+	protectionService *ProtectionService
+	filterService     *FilterService
+}
+
+func (api APIClient) ArchiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:ArchiveProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("Bitbucket:ArchiveProject")
+
+	err := api.projectService.archiveProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive Bitbucket project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:CreateProject")
+	opt.DebugLog(logger).Msg("Bitbucket:CreateOption")
+
+	projectID, err := api.projectService.createProject(ctx, cfg, opt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Bitbucket project. err: %w", err)
+	}
+
+	return projectID, nil
+}
+
+func (api APIClient) DeleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:DeleteProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("Bitbucket:DeleteProject")
+
+	err := api.projectService.deleteProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete Bitbucket project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:IsValidProjectName")
+	logger.Debug().Str("name", name).Msg("Bitbucket:IsValidProjectName")
+
+	if !IsValidBitbucketRepositoryName(name) {
+		logger.Debug().Str("name", name).Msg("Invalid Bitbucket repository name")
+
+		return false
+	}
+
+	return true
+}
+
+func (api APIClient) Name() string {
+	return config.BITBUCKET
+}
+
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return api.filterService.FilterProjectinfos(ctx, cfg, projectinfos)
+}
+
+func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:ProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("Bitbucket:ProjectInfos")
+
+	projectinfos, err := api.projectService.getProjectInfos(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository infos. err: %w", err)
+	}
+
+	if filtering {
+		return api.FilterProjectInfos(ctx, cfg, projectinfos)
+	}
+
+	return projectinfos, nil
+}
+
+func (api APIClient) ProtectProject(ctx context.Context, _ string, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:Protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("Bitbucket:Protect")
+
+	err := api.protectionService.protect(ctx, projectIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to to protect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("Bitbucket:ProtectedRefs")
+
+	refs, err := api.protectionService.protectedRefs(ctx, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected refs. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return refs, nil
+}
+
+// Bitbucket Cloud wikis are not synced.
+func (api APIClient) EnableWiki(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) RenameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:RenameProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("newName", newName).Msg("Bitbucket:RenameProject")
+
+	err := api.projectService.renameProject(ctx, owner, projectName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename Bitbucket project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) SetDefaultBranch(ctx context.Context, owner string, projectName string, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:SetDefaultBranch")
+	logger.Debug().Str("branch", branch).Str("owner", owner).Str("projectName", projectName).Msg("Bitbucket:SetDefaultBranch")
+
+	err := api.projectService.setDefaultBranch(ctx, owner, projectName, branch)
+	if err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) UnprotectProject(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:UnprotectProject")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("Bitbucket:UnprotectProject")
+
+	err := api.protectionService.unprotect(ctx, projectIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to to unprotect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+// Bitbucket Cloud has repository downloads, but no releases.
+func (api APIClient) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	return nil, interfaces.ErrNotSupported
+}
+
+func (api APIClient) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	return model.Release{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// MirrorProject is not supported, Bitbucket has no pull mirrors.
+func (api APIClient) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// SupportsPushMirror is false, Bitbucket has no push mirrors.
+func (api APIClient) SupportsPushMirror() bool {
+	return false
+}
+
+func (api APIClient) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+// Issues of the Bitbucket Cloud issue tracker are not synced.
+func (api APIClient) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	return model.Metadata{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	return model.Label{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	return model.Milestone{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	return interfaces.ErrNotSupported
+}
+
+func NewBitbucketAPIClient(ctx context.Context, opt model.GitProviderClientOption, httpClient *http.Client) (APIClient, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:NewBitbucketAPIClient")
+
+	baseURL := "https://api.bitbucket.org/2.0"
+
+	// Any other domain than bitbucket.org is expected to serve the 2.0 API itself, e.g. a proxy
+	if opt.Domain != "" && opt.Domain != defaultDomain {
+		baseURL = opt.DomainWithScheme(opt.HTTPClient.Scheme) + "/2.0"
+	}
+
+	rawClient := newRestClient(httpClient, baseURL, opt.HTTPClient.Token)
+
+	return APIClient{
+		raw:               rawClient,
+		projectService:    NewProjectService(rawClient),
+		protectionService: NewProtectionService(rawClient),
+		filterService:     NewFilter(),
+	}, nil
+}
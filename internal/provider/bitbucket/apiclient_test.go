@@ -0,0 +1,316 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// fakeBitbucket is a minimal in-memory fake of the Bitbucket Cloud 2.0 REST API.
+type fakeBitbucket struct {
+	mu           sync.Mutex
+	repositories map[string]repository
+	restrictions map[string][]branchRestriction
+	nextID       int
+	server       *httptest.Server
+}
+
+func newFakeBitbucket(t *testing.T) *fakeBitbucket {
+	t.Helper()
+
+	fake := &fakeBitbucket{repositories: map[string]repository{}, restrictions: map[string][]branchRestriction{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /2.0/repositories/{workspace}", fake.listRepositories)
+	mux.HandleFunc("POST /2.0/repositories/{workspace}/{slug}", fake.createRepository)
+	mux.HandleFunc("PUT /2.0/repositories/{workspace}/{slug}", fake.updateRepository)
+	mux.HandleFunc("GET /2.0/repositories/{workspace}/{slug}/branch-restrictions", fake.listRestrictions)
+	mux.HandleFunc("POST /2.0/repositories/{workspace}/{slug}/branch-restrictions", fake.createRestriction)
+	mux.HandleFunc("DELETE /2.0/repositories/{workspace}/{slug}/branch-restrictions/{id}", fake.deleteRestriction)
+
+	fake.server = httptest.NewServer(mux)
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (f *fakeBitbucket) add(repo repository) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.repositories[repo.FullName] = repo
+}
+
+func (f *fakeBitbucket) listRepositories(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var values []repository
+
+	for fullName, repo := range f.repositories {
+		if strings.HasPrefix(fullName, r.PathValue("workspace")+"/") {
+			values = append(values, repo)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].FullName < values[j].FullName })
+
+	// Serve one repository per page to exercise pagination
+	index := 0
+	if r.URL.Query().Get("page") != "" {
+		fmt.Sscanf(r.URL.Query().Get("page"), "%d", &index)
+	}
+
+	result := page[repository]{}
+
+	if index < len(values) {
+		result.Values = values[index : index+1]
+	}
+
+	if index+1 < len(values) {
+		result.Next = fmt.Sprintf("%s%s?page=%d", f.server.URL, r.URL.Path, index+1)
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeBitbucket) createRepository(w http.ResponseWriter, r *http.Request) {
+	var opt createRepositoryOption
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	repo := repository{
+		Name:        opt.Name,
+		Slug:        r.PathValue("slug"),
+		FullName:    r.PathValue("workspace") + "/" + r.PathValue("slug"),
+		Description: opt.Description,
+		IsPrivate:   opt.IsPrivate,
+		MainBranch:  opt.MainBranch,
+	}
+	f.add(repo)
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(repo)
+}
+
+func (f *fakeBitbucket) updateRepository(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fullName := r.PathValue("workspace") + "/" + r.PathValue("slug")
+
+	repo, ok := f.repositories[fullName]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	var body struct {
+		MainBranch *branch `json:"mainbranch"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	repo.MainBranch = body.MainBranch
+	f.repositories[fullName] = repo
+
+	_ = json.NewEncoder(w).Encode(repo)
+}
+
+func (f *fakeBitbucket) listRestrictions(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(page[branchRestriction]{Values: f.restrictions[r.PathValue("workspace")+"/"+r.PathValue("slug")]})
+}
+
+func (f *fakeBitbucket) createRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var restriction branchRestriction
+	_ = json.NewDecoder(r.Body).Decode(&restriction)
+	f.nextID++
+	restriction.ID = f.nextID
+
+	fullName := r.PathValue("workspace") + "/" + r.PathValue("slug")
+	f.restrictions[fullName] = append(f.restrictions[fullName], restriction)
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(restriction)
+}
+
+func (f *fakeBitbucket) deleteRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fullName := r.PathValue("workspace") + "/" + r.PathValue("slug")
+	kept := []branchRestriction{}
+
+	for _, restriction := range f.restrictions[fullName] {
+		if fmt.Sprint(restriction.ID) != r.PathValue("id") {
+			kept = append(kept, restriction)
+		}
+	}
+
+	f.restrictions[fullName] = kept
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeBitbucket) client(t *testing.T) APIClient {
+	t.Helper()
+
+	domain := strings.TrimPrefix(f.server.URL, "http://")
+	opt := model.GitProviderClientOption{
+		ProviderType: config.BITBUCKET,
+		Domain:       domain,
+		HTTPClient:   config.HTTPClientOption{Scheme: "http", Token: "token"},
+	}
+
+	client, err := NewBitbucketAPIClient(context.Background(), opt, f.server.Client())
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestAPIClient_ProjectInfos(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeBitbucket(t)
+
+	repo := repository{Slug: "repo1", FullName: "workspace/repo1", Description: "first", IsPrivate: true, MainBranch: &branch{Name: "main"}}
+	repo.Links.Clone = []link{
+		{Name: "https", Href: "https://someuser@bitbucket.org/workspace/repo1.git"},
+		{Name: "ssh", Href: "git@bitbucket.org:workspace/repo1.git"},
+	}
+	fake.add(repo)
+	fake.add(repository{Slug: "repo2", FullName: "workspace/repo2"})
+
+	fork := repository{Slug: "forked", FullName: "workspace/forked"}
+	fork.Parent = &struct {
+		FullName string `json:"full_name"`
+	}{FullName: "other/forked"}
+	fake.add(fork)
+	fake.add(repository{Slug: "elsewhere", FullName: "otherworkspace/elsewhere"})
+
+	tests := []struct {
+		name  string
+		cfg   config.ProviderConfig
+		names []string
+	}{
+		{
+			name:  "workspace without forks",
+			cfg:   config.ProviderConfig{ProviderType: config.BITBUCKET, Group: "workspace"},
+			names: []string{"repo1", "repo2"},
+		},
+		{
+			name:  "workspace with forks",
+			cfg:   config.ProviderConfig{ProviderType: config.BITBUCKET, Group: "workspace", Git: config.GitOption{IncludeForks: true}},
+			names: []string{"forked", "repo1", "repo2"},
+		},
+		{
+			name:  "user workspace",
+			cfg:   config.ProviderConfig{ProviderType: config.BITBUCKET, User: "otherworkspace"},
+			names: []string{"elsewhere"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(_ *testing.T) {
+			infos, err := fake.client(t).ProjectInfos(context.Background(), tabletest.cfg, false)
+			require.NoError(err)
+
+			names := []string{}
+			for _, info := range infos {
+				names = append(names, info.OriginalName)
+			}
+
+			require.ElementsMatch(tabletest.names, names)
+		})
+	}
+
+	infos, err := fake.client(t).ProjectInfos(context.Background(), config.ProviderConfig{Group: "workspace", Repositories: config.RepositoriesOption{Include: "repo1"}}, true)
+	require.NoError(err)
+	require.Len(infos, 1)
+	require.Equal(model.ProjectInfo{
+		OriginalName:  "repo1",
+		Description:   "first",
+		HTTPSURL:      "https://bitbucket.org/workspace/repo1.git",
+		SSHURL:        "git@bitbucket.org:workspace/repo1.git",
+		DefaultBranch: "main",
+		Visibility:    "private",
+		ProjectID:     "workspace/repo1",
+	}, infos[0])
+}
+
+func TestAPIClient_CreateProject(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeBitbucket(t)
+	client := fake.client(t)
+
+	cfg := config.ProviderConfig{ProviderType: config.BITBUCKET, Group: "workspace"}
+	projectID, err := client.CreateProject(context.Background(), cfg, model.NewCreateOption("NewRepo", "public", "a description", "main", false))
+	require.NoError(err)
+	require.Equal("workspace/newrepo", projectID)
+
+	created := fake.repositories[projectID]
+	require.Equal("NewRepo", created.Name)
+	require.Equal("a description", created.Description)
+	require.False(created.IsPrivate)
+
+	require.NoError(client.SetDefaultBranch(context.Background(), "workspace", "newrepo", "develop"))
+	require.Equal("develop", fake.repositories[projectID].MainBranch.Name)
+
+	require.Error(client.SetDefaultBranch(context.Background(), "workspace", "missing", "develop"))
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeBitbucket(t)
+	client := fake.client(t)
+
+	fake.add(repository{Slug: "repo1", FullName: "workspace/repo1"})
+
+	require.NoError(client.ProtectProject(context.Background(), "workspace", "main", "workspace/repo1"))
+	require.Len(fake.restrictions["workspace/repo1"], len(protectedKinds))
+
+	require.NoError(client.UnprotectProject(context.Background(), "main", "workspace/repo1"))
+	require.Empty(fake.restrictions["workspace/repo1"])
+
+	require.Error(client.ProtectProject(context.Background(), "workspace", "main", "invalid"))
+}
+
+func TestIsValidBitbucketRepositoryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"my-repo", true},
+		{"my_repo.v2", true},
+		{"..", false},
+		{"with space", false},
+		{strings.Repeat("a", 63), false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsValidBitbucketRepositoryName(tabletest.name))
+		})
+	}
+}
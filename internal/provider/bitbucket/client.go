@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
+// restClient is a minimal client for the Bitbucket Cloud 2.0 REST API.
+type restClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// page is the generic paginated response envelope of the 2.0 API.
+type page[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type repository struct {
+	UUID        string     `json:"uuid"`
+	Name        string     `json:"name"`
+	Slug        string     `json:"slug"`
+	FullName    string     `json:"full_name"`
+	Description string     `json:"description"`
+	IsPrivate   bool       `json:"is_private"`
+	UpdatedOn   *time.Time `json:"updated_on"`
+	MainBranch  *branch    `json:"mainbranch,omitempty"`
+	Parent      *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent,omitempty"`
+	Links struct {
+		Clone []link `json:"clone"`
+	} `json:"links"`
+}
+
+type branch struct {
+	Name string `json:"name"`
+}
+
+type branchRestriction struct {
+	ID              int    `json:"id,omitempty"`
+	Kind            string `json:"kind"`
+	BranchMatchKind string `json:"branch_match_kind"`
+	Pattern         string `json:"pattern"`
+	Users           []any  `json:"users"`
+	Groups          []any  `json:"groups"`
+}
+
+func newRestClient(httpClient *http.Client, baseURL, token string) *restClient {
+	return &restClient{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/"), token: token}
+}
+
+// url returns the absolute API URL for path, leaving already absolute URLs (like pagination links) untouched.
+func (c *restClient) url(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	return c.baseURL + "/" + strings.TrimLeft(path, "/")
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url(path), reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to do %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s %s: %d %s", ErrUnexpectedStatus, method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// getAll follows the next links of a paginated endpoint and returns all values.
+func getAll[T any](ctx context.Context, client *restClient, path string) ([]T, error) {
+	var all []T
+
+	next := path
+	for next != "" {
+		var result page[T]
+		if err := client.do(ctx, http.MethodGet, next, nil, &result); err != nil {
+			return nil, err
+		}
+
+		all = append(all, result.Values...)
+		next = result.Next
+	}
+
+	return all, nil
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"regexp"
+)
+
+var (
+	// validNameRegex defines the allowed characters in a Bitbucket repository slug.
+	// It allows alphanumeric characters, hyphens, underscores and dots.
+	validNameRegex = regexp.MustCompile(`^[A-Za-z0-9-_\.]+$`)
+
+	// invalidNames is a map of repository names that are not allowed by Bitbucket.
+	invalidNames = map[string]bool{
+		".":  true,
+		"..": true,
+	}
+
+	// maxNameLength is the maximum allowed length for a Bitbucket repository slug.
+	maxNameLength = 62
+)
+
+// IsValidBitbucketRepositoryName checks if the given name is a valid Bitbucket Cloud repository name.
+// It applies the following rules:
+//  1. The name must not be in the list of invalid names (e.g., "." or "..").
+//  2. The name must only contain alphanumeric characters, hyphens, underscores or dots.
+//  3. The name must not exceed the maximum allowed length (62 characters).
+//
+// Parameters:
+//   - name: The repository name to validate.
+//
+// Returns:
+//   - bool: true if the name is valid, false otherwise.
+func IsValidBitbucketRepositoryName(name string) bool {
+	return !invalidNames[name] &&
+		validNameRegex.MatchString(name) &&
+		len(name) <= maxNameLength
+}
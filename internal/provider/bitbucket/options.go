@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"strings"
+)
+
+type projectKey struct {
+	Key string `json:"key"`
+}
+
+type createRepositoryOption struct {
+	SCM         string      `json:"scm"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	IsPrivate   bool        `json:"is_private"`
+	ForkPolicy  string      `json:"fork_policy"`
+	HasIssues   bool        `json:"has_issues"`
+	HasWiki     bool        `json:"has_wiki"`
+	MainBranch  *branch     `json:"mainbranch,omitempty"`
+	Project     *projectKey `json:"project,omitempty"`
+}
+
+type ProjectOptionsBuilder struct {
+	opts *createRepositoryOption
+}
+
+func NewProjectOptionsBuilder() *ProjectOptionsBuilder {
+	builder := &ProjectOptionsBuilder{
+		opts: &createRepositoryOption{SCM: "git", ForkPolicy: "allow_forks", HasIssues: true, HasWiki: true},
+	}
+
+	return builder
+}
+
+func (p *ProjectOptionsBuilder) basicOpts(visibility, name, description, defaultBranch string) {
+	p.opts.Name = name
+	p.opts.Description = description
+	p.opts.IsPrivate = !strings.EqualFold(visibility, "public")
+
+	// Bitbucket rejects a public fork policy for private repositories
+	if p.opts.IsPrivate {
+		p.opts.ForkPolicy = "no_public_forks"
+	}
+
+	if defaultBranch != "" {
+		p.opts.MainBranch = &branch{Name: defaultBranch}
+	}
+}
+
+func (p *ProjectOptionsBuilder) projectKey(key string) {
+	if key != "" {
+		p.opts.Project = &projectKey{Key: key}
+	}
+}
+
+func (p *ProjectOptionsBuilder) disableFeatures() {
+	p.opts.HasIssues = false
+	p.opts.HasWiki = false
+	p.opts.ForkPolicy = "no_forks"
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+type ProjectService struct {
+	client *restClient
+}
+
+func NewProjectService(client *restClient) *ProjectService {
+	return &ProjectService{client: client}
+}
+
+func (p ProjectService) createProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:createProject")
+	opt.DebugLog(logger).Msg("Bitbucket:CreateOption")
+
+	optBuilder := NewProjectOptionsBuilder()
+	optBuilder.basicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch)
+	optBuilder.projectKey(cfg.BitbucketProjectKey())
+
+	if opt.Disabled {
+		optBuilder.disableFeatures()
+	}
+
+	var createdRepo repository
+
+	err := p.client.do(ctx, http.MethodPost, repositoryPath(workspace(cfg), opt.RepositoryName), optBuilder.opts, &createdRepo)
+	if err != nil {
+		return "", fmt.Errorf("create: failed to create project. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Trace().Str("name", opt.RepositoryName).Msg("Project created successfully")
+
+	return createdRepo.FullName, nil
+}
+
+func (p ProjectService) getProjectInfos(ctx context.Context, cfg config.ProviderConfig) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:getProjectInfos")
+
+	allRepos, err := getAll[repository](ctx, p.client, "repositories/"+url.PathEscape(workspace(cfg))+"?pagelen=100")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace repositories. workspace: %s, err: %w", workspace(cfg), err)
+	}
+
+	logger.Debug().Int("total_repositories", len(allRepos)).Msg("Total fetched repositories projectinfo")
+
+	projectinfos := make([]model.ProjectInfo, 0, len(allRepos))
+
+	for _, repo := range allRepos {
+		if !cfg.Git.IncludeForks && repo.Parent != nil {
+			continue
+		}
+
+		projectinfos = append(projectinfos, newProjectInfo(repo))
+	}
+
+	return projectinfos, nil
+}
+
+func (p ProjectService) setDefaultBranch(ctx context.Context, owner string, projectName string, branchName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:setDefaultBranch")
+
+	body := map[string]any{"mainbranch": branch{Name: branchName}}
+
+	err := p.client.do(ctx, http.MethodPut, repositoryPath(owner, projectName), body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set default branch. err: %w", err)
+	}
+
+	return nil
+}
+
+// archiveProject fails, Bitbucket Cloud has no archived repositories.
+func (p ProjectService) archiveProject(ctx context.Context, _ string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:archiveProject")
+
+	return fmt.Errorf("%w: archive repository %s", interfaces.ErrNotSupported, projectName)
+}
+
+func (p ProjectService) renameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:renameProject")
+
+	body := map[string]string{"name": newName}
+
+	err := p.client.do(ctx, http.MethodPut, repositoryPath(owner, projectName), body, nil)
+	if err != nil {
+		return fmt.Errorf("failed to rename project. name: %s, newName: %s, err: %w", projectName, newName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) deleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:deleteProject")
+
+	err := p.client.do(ctx, http.MethodDelete, repositoryPath(owner, projectName), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func newProjectInfo(repo repository) model.ProjectInfo {
+	visibility := "public"
+	if repo.IsPrivate {
+		visibility = "private"
+	}
+
+	defaultBranch := ""
+	if repo.MainBranch != nil {
+		defaultBranch = repo.MainBranch.Name
+	}
+
+	return model.ProjectInfo{
+		OriginalName:   repo.Slug,
+		Description:    repo.Description,
+		HTTPSURL:       cloneLink(repo, "https"),
+		SSHURL:         cloneLink(repo, "ssh"),
+		DefaultBranch:  defaultBranch,
+		LastActivityAt: repo.UpdatedOn,
+		Visibility:     visibility,
+		ProjectID:      repo.FullName,
+	}
+}
+
+// cloneLink returns the named clone link of a repository, stripped of the username Bitbucket embeds in https links.
+func cloneLink(repo repository, name string) string {
+	for _, cloneLink := range repo.Links.Clone {
+		if cloneLink.Name != name {
+			continue
+		}
+
+		if name == "https" {
+			if parsed, err := url.Parse(cloneLink.Href); err == nil {
+				parsed.User = nil
+
+				return parsed.String()
+			}
+		}
+
+		return cloneLink.Href
+	}
+
+	return ""
+}
+
+// workspace returns the workspace of the configuration, a user's personal workspace is named after the user.
+func workspace(cfg config.ProviderConfig) string {
+	if cfg.IsGroup() {
+		return cfg.Group
+	}
+
+	return cfg.User
+}
+
+func repositoryPath(workspace, slug string) string {
+	return "repositories/" + url.PathEscape(workspace) + "/" + url.PathEscape(strings.ToLower(slug))
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// protectedKinds are the branch restriction kinds that together make a branch read-only.
+var protectedKinds = []string{"push", "force", "delete"}
+
+type ProtectionService struct {
+	client *restClient
+}
+
+func NewProtectionService(client *restClient) *ProtectionService {
+	return &ProtectionService{client: client}
+}
+
+func (p ProtectionService) protect(ctx context.Context, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("Bitbucket:protect")
+
+	owner, projectName, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range protectedKinds {
+		restriction := branchRestriction{
+			Kind:            kind,
+			BranchMatchKind: "glob",
+			Pattern:         "*",
+			Users:           []any{},
+			Groups:          []any{},
+		}
+
+		err := p.client.do(ctx, http.MethodPost, repositoryPath(owner, projectName)+"/branch-restrictions", restriction, nil)
+		if err != nil {
+			return fmt.Errorf("failed to add branch restriction. kind: %s, projectName: %s. err: %w", kind, projectName, err)
+		}
+	}
+
+	return nil
+}
+
+func (p ProtectionService) unprotect(ctx context.Context, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:unprotect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("Bitbucket:unprotect")
+
+	owner, projectName, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	restrictionsPath := repositoryPath(owner, projectName) + "/branch-restrictions"
+
+	restrictions, err := getAll[branchRestriction](ctx, p.client, restrictionsPath+"?pagelen=100")
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return fmt.Errorf("failed to list branch restrictions. projectName: %s. err: %w", projectName, err)
+		}
+
+		return nil
+	}
+
+	for _, restriction := range restrictions {
+		err := p.client.do(ctx, http.MethodDelete, restrictionsPath+"/"+strconv.Itoa(restriction.ID), nil, nil)
+		if err != nil && !strings.Contains(err.Error(), "404") {
+			return fmt.Errorf("failed to remove branch restriction. id: %d, projectName: %s. err: %w", restriction.ID, projectName, err)
+		}
+	}
+
+	return nil
+}
+
+// protectedRefs returns the branches the branch restrictions of the repository keep from being deleted, as reference patterns.
+// Restrictions by branching model are left out, as they name no branch.
+func (p ProtectionService) protectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Bitbucket:protectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("Bitbucket:protectedRefs")
+
+	owner, projectName, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	restrictions, err := getAll[branchRestriction](ctx, p.client, repositoryPath(owner, projectName)+"/branch-restrictions?kind=delete&pagelen=100")
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return nil, fmt.Errorf("failed to list branch restrictions. projectName: %s. err: %w", projectName, err)
+		}
+
+		return nil, nil
+	}
+
+	var refs []string
+
+	for _, restriction := range restrictions {
+		if restriction.BranchMatchKind == "glob" {
+			refs = append(refs, "refs/heads/"+restriction.Pattern)
+		}
+	}
+
+	return refs, nil
+}
+
+func splitProjectPath(path string) (string, string, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("string was not in a/b format, failed to split: path: %s", path)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,242 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrNoDomain = errors.New("bitbucket server requires a domain")
+
+type APIClient struct {
+	raw               *restClient
+	projectService    *ProjectService
+	protectionService *ProtectionService
+	filterService     *FilterService
+}
+
+func (api APIClient) ArchiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:ArchiveProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("BitbucketServer:ArchiveProject")
+
+	err := api.projectService.archiveProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive BitbucketServer project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:CreateProject")
+	opt.DebugLog(logger).Msg("BitbucketServer:CreateOption")
+
+	projectID, err := api.projectService.createProject(ctx, cfg, opt)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Bitbucket Server project. err: %w", err)
+	}
+
+	return projectID, nil
+}
+
+func (api APIClient) DeleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:DeleteProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("BitbucketServer:DeleteProject")
+
+	err := api.projectService.deleteProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete BitbucketServer project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:IsValidProjectName")
+	logger.Debug().Str("name", name).Msg("BitbucketServer:IsValidProjectName")
+
+	if !IsValidBitbucketServerRepositoryName(name) {
+		logger.Debug().Str("name", name).Msg("Invalid Bitbucket Server repository name")
+
+		return false
+	}
+
+	return true
+}
+
+func (api APIClient) Name() string {
+	return config.BITBUCKETSERVER
+}
+
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return api.filterService.FilterProjectinfos(ctx, cfg, projectinfos)
+}
+
+func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:ProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("BitbucketServer:ProjectInfos")
+
+	projectinfos, err := api.projectService.getProjectInfos(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository infos. err: %w", err)
+	}
+
+	if filtering {
+		return api.FilterProjectInfos(ctx, cfg, projectinfos)
+	}
+
+	return projectinfos, nil
+}
+
+func (api APIClient) ProtectProject(ctx context.Context, _ string, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:Protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("BitbucketServer:Protect")
+
+	err := api.protectionService.protect(ctx, projectIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to to protect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+func (api APIClient) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("BitbucketServer:ProtectedRefs")
+
+	refs, err := api.protectionService.protectedRefs(ctx, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected refs. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return refs, nil
+}
+
+// Bitbucket Server has no wikis.
+func (api APIClient) EnableWiki(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) RenameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:RenameProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("newName", newName).Msg("BitbucketServer:RenameProject")
+
+	err := api.projectService.renameProject(ctx, owner, projectName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename BitbucketServer project: %w", err)
+	}
+
+	return nil
+}
+
+// SetDefaultBranch sets the default branch, owner is expected to be a project key (~user for personal repositories).
+func (api APIClient) SetDefaultBranch(ctx context.Context, owner string, projectName string, branch string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:SetDefaultBranch")
+	logger.Debug().Str("branch", branch).Str("owner", owner).Str("projectName", projectName).Msg("BitbucketServer:SetDefaultBranch")
+
+	err := api.projectService.setDefaultBranch(ctx, owner, projectName, branch)
+	if err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) UnprotectProject(ctx context.Context, branch string, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:UnprotectProject")
+	logger.Debug().Str("projectIDStr", projectIDStr).Str("branch", branch).Msg("BitbucketServer:UnprotectProject")
+
+	err := api.protectionService.unprotect(ctx, projectIDStr)
+	if err != nil {
+		return fmt.Errorf("failed to to unprotect project. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return nil
+}
+
+// Bitbucket Server has no releases.
+func (api APIClient) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	return nil, interfaces.ErrNotSupported
+}
+
+func (api APIClient) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	return model.Release{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// MirrorProject is not supported, Bitbucket Server mirrors are a server of their own, not a repository.
+func (api APIClient) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// SupportsPushMirror is false, Bitbucket Server has no push mirrors.
+func (api APIClient) SupportsPushMirror() bool {
+	return false
+}
+
+func (api APIClient) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+// Bitbucket Server has no issues, they are kept in Jira.
+func (api APIClient) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	return model.Metadata{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	return model.Label{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	return model.Milestone{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	return interfaces.ErrNotSupported
+}
+
+func NewBitbucketServerAPIClient(ctx context.Context, opt model.GitProviderClientOption, httpClient *http.Client) (APIClient, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:NewBitbucketServerAPIClient")
+
+	if opt.Domain == "" {
+		return APIClient{}, ErrNoDomain
+	}
+
+	rawClient := newRestClient(httpClient, opt.DomainWithScheme(opt.HTTPClient.Scheme), opt.HTTPClient.Token)
+
+	return APIClient{
+		raw:               rawClient,
+		projectService:    NewProjectService(rawClient),
+		protectionService: NewProtectionService(rawClient),
+		filterService:     NewFilter(),
+	}, nil
+}
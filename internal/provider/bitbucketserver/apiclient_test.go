@@ -0,0 +1,330 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+package bitbucketserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// fakeBitbucketServer is a minimal in-memory fake of the Bitbucket Server REST API.
+type fakeBitbucketServer struct {
+	mu             sync.Mutex
+	repositories   map[string]repository
+	defaultBranch  map[string]string
+	restrictions   map[string][]restriction
+	nextID         int
+	server         *httptest.Server
+	requestedPaths []string
+}
+
+func newFakeBitbucketServer(t *testing.T) *fakeBitbucketServer {
+	t.Helper()
+
+	fake := &fakeBitbucketServer{
+		repositories:  map[string]repository{},
+		defaultBranch: map[string]string{},
+		restrictions:  map[string][]restriction{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/{key}/repos", fake.listRepositories)
+	mux.HandleFunc("POST /rest/api/1.0/projects/{key}/repos", fake.createRepository)
+	mux.HandleFunc("GET /rest/api/1.0/projects/{key}/repos/{slug}/branches/default", fake.getDefaultBranch)
+	mux.HandleFunc("PUT /rest/api/1.0/projects/{key}/repos/{slug}/branches/default", fake.setDefaultBranch)
+	mux.HandleFunc("GET /rest/branch-permissions/2.0/projects/{key}/repos/{slug}/restrictions", fake.listRestrictions)
+	mux.HandleFunc("POST /rest/branch-permissions/2.0/projects/{key}/repos/{slug}/restrictions", fake.createRestriction)
+	mux.HandleFunc("DELETE /rest/branch-permissions/2.0/projects/{key}/repos/{slug}/restrictions/{id}", fake.deleteRestriction)
+
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		fake.requestedPaths = append(fake.requestedPaths, r.URL.Path)
+		fake.mu.Unlock()
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (f *fakeBitbucketServer) add(repo repository, defaultBranch string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.repositories[repo.Project.Key+"/"+repo.Slug] = repo
+	if defaultBranch != "" {
+		f.defaultBranch[repo.Project.Key+"/"+repo.Slug] = defaultBranch
+	}
+}
+
+func (f *fakeBitbucketServer) listRepositories(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var values []repository
+
+	for fullName, repo := range f.repositories {
+		if strings.HasPrefix(fullName, r.PathValue("key")+"/") {
+			values = append(values, repo)
+		}
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i].Slug < values[j].Slug })
+
+	// Serve one repository per page to exercise paging
+	start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+	result := page[repository]{IsLastPage: start+1 >= len(values), NextPageStart: start + 1}
+
+	if start < len(values) {
+		result.Values = values[start : start+1]
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeBitbucketServer) createRepository(w http.ResponseWriter, r *http.Request) {
+	var opt createRepositoryOption
+	if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	repo := repository{Slug: strings.ToLower(opt.Name), Name: opt.Name, Description: opt.Description, Public: opt.Public}
+	repo.Project.Key = r.PathValue("key")
+	f.add(repo, "")
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(repo)
+}
+
+func (f *fakeBitbucketServer) getDefaultBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name, ok := f.defaultBranch[r.PathValue("key")+"/"+r.PathValue("slug")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(branch{ID: "refs/heads/" + name, DisplayID: name})
+}
+
+func (f *fakeBitbucketServer) setDefaultBranch(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fullName := r.PathValue("key") + "/" + r.PathValue("slug")
+	if _, ok := f.repositories[fullName]; !ok {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	var body branch
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.defaultBranch[fullName] = strings.TrimPrefix(body.ID, "refs/heads/")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeBitbucketServer) listRestrictions(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_ = json.NewEncoder(w).Encode(page[restriction]{Values: f.restrictions[r.PathValue("key")+"/"+r.PathValue("slug")], IsLastPage: true})
+}
+
+func (f *fakeBitbucketServer) createRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var newRestriction restriction
+	_ = json.NewDecoder(r.Body).Decode(&newRestriction)
+	f.nextID++
+	newRestriction.ID = f.nextID
+
+	fullName := r.PathValue("key") + "/" + r.PathValue("slug")
+	f.restrictions[fullName] = append(f.restrictions[fullName], newRestriction)
+
+	_ = json.NewEncoder(w).Encode(newRestriction)
+}
+
+func (f *fakeBitbucketServer) deleteRestriction(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fullName := r.PathValue("key") + "/" + r.PathValue("slug")
+	kept := []restriction{}
+
+	for _, existing := range f.restrictions[fullName] {
+		if fmt.Sprint(existing.ID) != r.PathValue("id") {
+			kept = append(kept, existing)
+		}
+	}
+
+	f.restrictions[fullName] = kept
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (f *fakeBitbucketServer) client(t *testing.T) APIClient {
+	t.Helper()
+
+	opt := model.GitProviderClientOption{
+		ProviderType: config.BITBUCKETSERVER,
+		Domain:       strings.TrimPrefix(f.server.URL, "http://"),
+		HTTPClient:   config.HTTPClientOption{Scheme: "http", Token: "token"},
+	}
+
+	client, err := NewBitbucketServerAPIClient(context.Background(), opt, f.server.Client())
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestNewBitbucketServerAPIClient_NoDomain(t *testing.T) {
+	_, err := NewBitbucketServerAPIClient(context.Background(), model.GitProviderClientOption{}, http.DefaultClient)
+	require.ErrorIs(t, err, ErrNoDomain)
+}
+
+func TestAPIClient_ProjectInfos(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeBitbucketServer(t)
+
+	repo := repository{Slug: "repo1", Description: "first", Public: true}
+	repo.Project.Key = "PROJ"
+	repo.Links.Clone = []link{
+		{Name: "http", Href: "https://admin@bitbucket.example.com/scm/proj/repo1.git"},
+		{Name: "ssh", Href: "ssh://git@bitbucket.example.com:7999/proj/repo1.git"},
+	}
+	fake.add(repo, "main")
+
+	empty := repository{Slug: "empty"}
+	empty.Project.Key = "PROJ"
+	fake.add(empty, "")
+
+	fork := repository{Slug: "fork", Origin: &struct {
+		Slug string `json:"slug"`
+	}{Slug: "upstream"}}
+	fork.Project.Key = "PROJ"
+	fake.add(fork, "main")
+
+	personal := repository{Slug: "dotfiles"}
+	personal.Project.Key = "~JDOE"
+	fake.add(personal, "master")
+
+	tests := []struct {
+		name  string
+		cfg   config.ProviderConfig
+		names []string
+	}{
+		{
+			name:  "project key",
+			cfg:   config.ProviderConfig{ProviderType: config.BITBUCKETSERVER, Group: "PROJ"},
+			names: []string{"empty", "repo1"},
+		},
+		{
+			name:  "project key with forks",
+			cfg:   config.ProviderConfig{ProviderType: config.BITBUCKETSERVER, Group: "PROJ", Git: config.GitOption{IncludeForks: true}},
+			names: []string{"empty", "fork", "repo1"},
+		},
+		{
+			name:  "personal space",
+			cfg:   config.ProviderConfig{ProviderType: config.BITBUCKETSERVER, User: "JDOE"},
+			names: []string{"dotfiles"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(_ *testing.T) {
+			infos, err := fake.client(t).ProjectInfos(context.Background(), tabletest.cfg, false)
+			require.NoError(err)
+
+			names := []string{}
+			for _, info := range infos {
+				names = append(names, info.OriginalName)
+			}
+
+			require.ElementsMatch(tabletest.names, names)
+		})
+	}
+
+	infos, err := fake.client(t).ProjectInfos(context.Background(), config.ProviderConfig{Group: "PROJ", Repositories: config.RepositoriesOption{Include: "repo1"}}, true)
+	require.NoError(err)
+	require.Len(infos, 1)
+	require.Equal(model.ProjectInfo{
+		OriginalName:  "repo1",
+		Description:   "first",
+		HTTPSURL:      "https://bitbucket.example.com/scm/proj/repo1.git",
+		SSHURL:        "ssh://git@bitbucket.example.com:7999/proj/repo1.git",
+		DefaultBranch: "main",
+		Visibility:    "public",
+		ProjectID:     "PROJ/repo1",
+	}, infos[0])
+}
+
+func TestAPIClient_CreateProject(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeBitbucketServer(t)
+	client := fake.client(t)
+
+	cfg := config.ProviderConfig{ProviderType: config.BITBUCKETSERVER, User: "jdoe"}
+	projectID, err := client.CreateProject(context.Background(), cfg, model.NewCreateOption("Mirror", "private", "desc", "main", true))
+	require.NoError(err)
+	require.Equal("~jdoe/mirror", projectID)
+	require.False(fake.repositories[projectID].Public)
+
+	require.NoError(client.SetDefaultBranch(context.Background(), "~jdoe", "Mirror", "develop"))
+	require.Equal("develop", fake.defaultBranch[projectID])
+	require.Contains(fake.requestedPaths, "/rest/api/1.0/projects/~jdoe/repos/mirror/branches/default")
+}
+
+func TestAPIClient_ProtectUnprotect(t *testing.T) {
+	require := require.New(t)
+	fake := newFakeBitbucketServer(t)
+	client := fake.client(t)
+
+	require.NoError(client.ProtectProject(context.Background(), "PROJ", "main", "PROJ/repo1"))
+	require.Len(fake.restrictions["PROJ/repo1"], len(protectedTypes))
+	require.Equal("PATTERN", fake.restrictions["PROJ/repo1"][0].Matcher.Type.ID)
+
+	require.NoError(client.UnprotectProject(context.Background(), "main", "PROJ/repo1"))
+	require.Empty(fake.restrictions["PROJ/repo1"])
+}
+
+func TestIsValidBitbucketServerRepositoryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"my-repo", true},
+		{"My_Repo.v2", true},
+		{"-leading", false},
+		{"with space", false},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsValidBitbucketServerRepositoryName(tabletest.name))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var ErrUnexpectedStatus = errors.New("unexpected response status")
+
+// restClient is a minimal client for the Bitbucket Server / Data Center REST API.
+type restClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// page is the generic paged response envelope of the 1.0 API.
+type page[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+type repository struct {
+	ID          int    `json:"id"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Public      bool   `json:"public"`
+	Archived    bool   `json:"archived"`
+	Project     struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Origin *struct {
+		Slug string `json:"slug"`
+	} `json:"origin,omitempty"`
+	Links struct {
+		Clone []link `json:"clone"`
+	} `json:"links"`
+}
+
+type branch struct {
+	ID        string `json:"id"`
+	DisplayID string `json:"displayId,omitempty"`
+}
+
+type restrictionMatcher struct {
+	ID   string `json:"id"`
+	Type struct {
+		ID string `json:"id"`
+	} `json:"type"`
+}
+
+type restriction struct {
+	ID      int                `json:"id,omitempty"`
+	Type    string             `json:"type"`
+	Matcher restrictionMatcher `json:"matcher"`
+}
+
+func newRestClient(httpClient *http.Client, baseURL, token string) *restClient {
+	return &restClient{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/"), token: token}
+}
+
+func (c *restClient) do(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/"+strings.TrimLeft(path, "/"), reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to do %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s %s: %d %s", ErrUnexpectedStatus, method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// getAll pages through a paged endpoint and returns all values.
+func getAll[T any](ctx context.Context, client *restClient, path string) ([]T, error) {
+	var all []T
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	start := 0
+
+	for {
+		var result page[T]
+
+		query := url.Values{"limit": {"100"}, "start": {strconv.Itoa(start)}}
+		if err := client.do(ctx, http.MethodGet, path+separator+query.Encode(), nil, &result); err != nil {
+			return nil, err
+		}
+
+		all = append(all, result.Values...)
+
+		if result.IsLastPage || len(result.Values) == 0 {
+			break
+		}
+
+		start = result.NextPageStart
+	}
+
+	return all, nil
+}
@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"fmt"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+)
+
+// FilterService provides methods for filtering Bitbucket Server repositories.
+type FilterService struct{}
+
+func NewFilter() *FilterService {
+	return &FilterService{}
+}
+
+// FilterProjectinfos filters repository metadata based on inclusion/exclusion rules and activity date.
+func (f FilterService) FilterProjectinfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:FilterProjectinfos")
+
+	filteredByRules, err := targetfilter.FilterIncludedExcludedGen()(ctx, cfg, projectinfos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter repositories by inclusion/exclusion rules: %w", err)
+	}
+
+	var filtered []model.ProjectInfo
+
+	for _, projectInfo := range filteredByRules {
+		// Repositories without an activity time can't be excluded by date
+		if projectInfo.LastActivityAt == nil {
+			filtered = append(filtered, projectInfo)
+
+			continue
+		}
+
+		inInterval, err := targetfilter.IsInInterval(ctx, *projectInfo.LastActivityAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check activity time for repository %s: %w", projectInfo.OriginalName, err)
+		}
+
+		if inInterval {
+			filtered = append(filtered, projectInfo)
+		}
+	}
+
+	return filtered, nil
+}
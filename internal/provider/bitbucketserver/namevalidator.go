@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"regexp"
+)
+
+var (
+	// validNameRegex defines the allowed characters in a Bitbucket Server repository name.
+	// The name must start with an alphanumeric character, followed by alphanumeric characters, hyphens, underscores or dots.
+	// Bitbucket Server also allows spaces, but those do not survive the slug that is used in clone URLs.
+	validNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-_\.]*$`)
+
+	// maxNameLength is the maximum allowed length for a Bitbucket Server repository name.
+	maxNameLength = 128
+)
+
+// IsValidBitbucketServerRepositoryName checks if the given name is a valid Bitbucket Server repository name.
+// It applies the following rules:
+//  1. The name must start with an alphanumeric character.
+//  2. The name must only contain alphanumeric characters, hyphens, underscores or dots.
+//  3. The name must not exceed the maximum allowed length (128 characters).
+//
+// Parameters:
+//   - name: The repository name to validate.
+//
+// Returns:
+//   - bool: true if the name is valid, false otherwise.
+func IsValidBitbucketServerRepositoryName(name string) bool {
+	return validNameRegex.MatchString(name) &&
+		len(name) <= maxNameLength
+}
@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+type ProjectService struct {
+	client *restClient
+}
+
+func NewProjectService(client *restClient) *ProjectService {
+	return &ProjectService{client: client}
+}
+
+type createRepositoryOption struct {
+	Name          string `json:"name"`
+	ScmID         string `json:"scmId"`
+	Description   string `json:"description,omitempty"`
+	Forkable      bool   `json:"forkable"`
+	Public        bool   `json:"public"`
+	DefaultBranch string `json:"defaultBranch,omitempty"`
+}
+
+func (p ProjectService) createProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:createProject")
+	opt.DebugLog(logger).Msg("BitbucketServer:CreateOption")
+
+	createOpt := createRepositoryOption{
+		Name:          opt.RepositoryName,
+		ScmID:         "git",
+		Description:   opt.Description,
+		Forkable:      !opt.Disabled,
+		Public:        strings.EqualFold(opt.Visibility, "public"),
+		DefaultBranch: opt.DefaultBranch,
+	}
+
+	var createdRepo repository
+
+	err := p.client.do(ctx, http.MethodPost, reposPath(ProjectKey(cfg)), createOpt, &createdRepo)
+	if err != nil {
+		return "", fmt.Errorf("create: failed to create project. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Trace().Str("name", opt.RepositoryName).Msg("Project created successfully")
+
+	return createdRepo.Project.Key + "/" + createdRepo.Slug, nil
+}
+
+func (p ProjectService) getProjectInfos(ctx context.Context, cfg config.ProviderConfig) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:getProjectInfos")
+
+	key := ProjectKey(cfg)
+
+	allRepos, err := getAll[repository](ctx, p.client, reposPath(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project repositories. project: %s, err: %w", key, err)
+	}
+
+	logger.Debug().Int("total_repositories", len(allRepos)).Msg("Total fetched repositories projectinfo")
+
+	projectinfos := make([]model.ProjectInfo, 0, len(allRepos))
+
+	for _, repo := range allRepos {
+		if !cfg.Git.IncludeForks && repo.Origin != nil {
+			continue
+		}
+
+		defaultBranch, err := p.defaultBranch(ctx, key, repo.Slug)
+		if err != nil {
+			// Empty repositories have no default branch
+			logger.Debug().Err(err).Str("repo", repo.Slug).Msg("failed to get default branch")
+		}
+
+		projectinfos = append(projectinfos, newProjectInfo(repo, defaultBranch))
+	}
+
+	return projectinfos, nil
+}
+
+func (p ProjectService) defaultBranch(ctx context.Context, key, slug string) (string, error) {
+	var defaultBranch branch
+
+	if err := p.client.do(ctx, http.MethodGet, repoPath(key, slug)+"/branches/default", nil, &defaultBranch); err != nil {
+		return "", fmt.Errorf("failed to get default branch. err: %w", err)
+	}
+
+	return defaultBranch.DisplayID, nil
+}
+
+func (p ProjectService) setDefaultBranch(ctx context.Context, key string, projectName string, branchName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:setDefaultBranch")
+
+	err := p.client.do(ctx, http.MethodPut, repoPath(key, projectName)+"/branches/default", branch{ID: "refs/heads/" + branchName}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set default branch. err: %w", err)
+	}
+
+	return nil
+}
+
+// archiveProject needs Bitbucket Server 8.0 or later, earlier versions have no archived repositories.
+func (p ProjectService) archiveProject(ctx context.Context, key string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:archiveProject")
+
+	err := p.client.do(ctx, http.MethodPut, repoPath(key, projectName), map[string]bool{"archived": true}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to archive project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) renameProject(ctx context.Context, key string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:renameProject")
+
+	err := p.client.do(ctx, http.MethodPut, repoPath(key, projectName), map[string]string{"name": newName}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to rename project. name: %s, newName: %s, err: %w", projectName, newName, err)
+	}
+
+	return nil
+}
+
+// deleteProject schedules the repository for deletion, Bitbucket Server removes it in the background.
+func (p ProjectService) deleteProject(ctx context.Context, key string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:deleteProject")
+
+	err := p.client.do(ctx, http.MethodDelete, repoPath(key, projectName), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func newProjectInfo(repo repository, defaultBranch string) model.ProjectInfo {
+	visibility := "private"
+	if repo.Public {
+		visibility = "public"
+	}
+
+	return model.ProjectInfo{
+		OriginalName:  repo.Slug,
+		Description:   repo.Description,
+		HTTPSURL:      cloneLink(repo, "http"),
+		SSHURL:        cloneLink(repo, "ssh"),
+		DefaultBranch: defaultBranch,
+		Visibility:    visibility,
+		ProjectID:     repo.Project.Key + "/" + repo.Slug,
+		Archived:      repo.Archived,
+	}
+}
+
+// cloneLink returns the named clone link of a repository, stripped of the username Bitbucket embeds in http links.
+func cloneLink(repo repository, name string) string {
+	for _, cloneLink := range repo.Links.Clone {
+		if cloneLink.Name != name {
+			continue
+		}
+
+		if name == "http" {
+			if parsed, err := url.Parse(cloneLink.Href); err == nil {
+				parsed.User = nil
+
+				return parsed.String()
+			}
+		}
+
+		return cloneLink.Href
+	}
+
+	return ""
+}
+
+// ProjectKey returns the Bitbucket Server project key of the configuration.
+// Personal repositories of a user live in the special ~user project.
+func ProjectKey(cfg config.ProviderConfig) string {
+	if cfg.IsGroup() {
+		return cfg.Group
+	}
+
+	return "~" + cfg.User
+}
+
+func reposPath(key string) string {
+	return "rest/api/1.0/projects/" + url.PathEscape(key) + "/repos"
+}
+
+func repoPath(key, slug string) string {
+	return reposPath(key) + "/" + url.PathEscape(strings.ToLower(slug))
+}
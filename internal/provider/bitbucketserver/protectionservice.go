@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package bitbucketserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// protectedTypes are the branch permission types that together make all branches read-only.
+var protectedTypes = []string{"read-only", "no-deletes", "fast-forward-only"}
+
+type ProtectionService struct {
+	client *restClient
+}
+
+func NewProtectionService(client *restClient) *ProtectionService {
+	return &ProtectionService{client: client}
+}
+
+func (p ProtectionService) protect(ctx context.Context, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:protect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("BitbucketServer:protect")
+
+	key, projectName, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	for _, restrictionType := range protectedTypes {
+		newRestriction := restriction{Type: restrictionType, Matcher: restrictionMatcher{ID: "*"}}
+		newRestriction.Matcher.Type.ID = "PATTERN"
+
+		err := p.client.do(ctx, http.MethodPost, restrictionsPath(key, projectName), newRestriction, nil)
+		if err != nil {
+			return fmt.Errorf("failed to add branch restriction. type: %s, projectName: %s. err: %w", restrictionType, projectName, err)
+		}
+	}
+
+	return nil
+}
+
+func (p ProtectionService) unprotect(ctx context.Context, projectIDStr string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:unprotect")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("BitbucketServer:unprotect")
+
+	key, projectName, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return err
+	}
+
+	restrictions, err := getAll[restriction](ctx, p.client, restrictionsPath(key, projectName))
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return fmt.Errorf("failed to list branch restrictions. projectName: %s. err: %w", projectName, err)
+		}
+
+		return nil
+	}
+
+	for _, existing := range restrictions {
+		err := p.client.do(ctx, http.MethodDelete, restrictionsPath(key, projectName)+"/"+strconv.Itoa(existing.ID), nil, nil)
+		if err != nil && !strings.Contains(err.Error(), "404") {
+			return fmt.Errorf("failed to remove branch restriction. id: %d, projectName: %s. err: %w", existing.ID, projectName, err)
+		}
+	}
+
+	return nil
+}
+
+// protectedRefs returns the references the branch permissions of the repository keep from being deleted, as reference patterns.
+// A pattern permission applies to branches and tags alike, permissions by branching model are left out, as they name no reference.
+func (p ProtectionService) protectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering BitbucketServer:protectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("BitbucketServer:protectedRefs")
+
+	key, projectName, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	restrictions, err := getAll[restriction](ctx, p.client, restrictionsPath(key, projectName))
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return nil, fmt.Errorf("failed to list branch restrictions. projectName: %s. err: %w", projectName, err)
+		}
+
+		return nil, nil
+	}
+
+	var refs []string
+
+	for _, existing := range restrictions {
+		if existing.Type != "read-only" && existing.Type != "no-deletes" {
+			continue
+		}
+
+		switch existing.Matcher.Type.ID {
+		case "BRANCH":
+			refs = append(refs, existing.Matcher.ID)
+		case "PATTERN":
+			refs = append(refs, "refs/heads/"+existing.Matcher.ID, "refs/tags/"+existing.Matcher.ID)
+		}
+	}
+
+	return refs, nil
+}
+
+func restrictionsPath(key, slug string) string {
+	return "rest/branch-permissions/2.0/projects/" + url.PathEscape(key) + "/repos/" + url.PathEscape(strings.ToLower(slug)) + "/restrictions"
+}
+
+func splitProjectPath(path string) (string, string, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("string was not in a/b format, failed to split: path: %s", path)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -6,52 +6,67 @@ package provider
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"errors"
 	"fmt"
-	"net"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
-	"time"
+	"strings"
+	"sync"
 
+	"itiquette/git-provider-sync/internal/httpclient"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
 	"itiquette/git-provider-sync/internal/provider/archive"
+	"itiquette/git-provider-sync/internal/provider/azuredevops"
+	"itiquette/git-provider-sync/internal/provider/bitbucket"
+	"itiquette/git-provider-sync/internal/provider/bitbucketserver"
 	"itiquette/git-provider-sync/internal/provider/directory"
 	"itiquette/git-provider-sync/internal/provider/gitea"
 	"itiquette/git-provider-sync/internal/provider/github"
 	"itiquette/git-provider-sync/internal/provider/gitlab"
+	"itiquette/git-provider-sync/internal/provider/gitremote"
+	"itiquette/git-provider-sync/internal/retry"
 
 	"github.com/go-git/go-git/v5/plumbing/transport/client"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
-var (
-	ErrNonSupportedProvider = errors.New("unsupported provider")
-	ErrInvalidProxy         = errors.New("invalid proxy configuration")
-	ErrCertificateLoad      = errors.New("failed to load certificates")
-)
+var ErrNonSupportedProvider = errors.New("unsupported provider")
+
+// gitTransports holds the git transport of the provider clients created for each host.
+// Go Git has one https protocol handler for all of them, which sends each request through the transport of its host.
+var gitTransports = newHostTransport()
 
-// ProxyFunc defines the type for proxy configuration functions.
-type ProxyFunc func(req *http.Request) (*url.URL, error)
+var installGitTransports sync.Once
 
 // NewGitProviderClient creates a new git provider client with improved error handling.
 func NewGitProviderClient(ctx context.Context, option model.GitProviderClientOption) (interfaces.GitProvider, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering NewGitProviderClient")
 
-	httpClient, err := newHTTPClient(ctx, option)
+	httpClient, err := httpclient.New(ctx, option.HTTPClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 
-	// Install git protocol handler
-	client.InstallProtocol("https", githttp.NewClient(httpClient))
+	gitTransport, err := httpclient.NewGitTransport(ctx, option.HTTPClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create git transport: %w", err)
+	}
+
+	// Git over https to the provider goes through the same proxy, certificates and retries as its API calls
+	installGitTransports.Do(func() {
+		client.InstallProtocol("https", githttp.NewClient(&http.Client{
+			Transport:     gitTransports,
+			CheckRedirect: httpClient.CheckRedirect,
+		}))
+	})
+
+	for _, host := range gitHosts(option) {
+		gitTransports.set(host, gitTransport)
+	}
 
 	return createProvider(ctx, option, httpClient)
 }
@@ -84,6 +99,29 @@ func createProvider(ctx context.Context, option model.GitProviderClientOption, h
 		}
 
 		return provider, nil
+	case config.BITBUCKET:
+		provider, err := bitbucket.NewBitbucketAPIClient(ctx, option, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Bitbucket client: %w", err)
+		}
+
+		return provider, nil
+	case config.BITBUCKETSERVER:
+		provider, err := bitbucketserver.NewBitbucketServerAPIClient(ctx, option, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Bitbucket Server client: %w", err)
+		}
+
+		return provider, nil
+	case config.AZUREDEVOPS:
+		provider, err := azuredevops.NewAzureDevOpsAPIClient(ctx, option, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure DevOps client: %w", err)
+		}
+
+		return provider, nil
+	case config.GITREMOTE:
+		return gitremote.NewGitRemoteAPIClient(ctx), nil
 	case config.ARCHIVE:
 		return archive.Client{}, nil
 	case config.DIRECTORY:
@@ -93,167 +131,59 @@ func createProvider(ctx context.Context, option model.GitProviderClientOption, h
 	}
 }
 
-// newHTTPClient creates a new HTTP client with proper error handling.
-func newHTTPClient(ctx context.Context, option model.GitProviderClientOption) (*http.Client, error) {
-	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering newHTTPClient")
-
-	certPool, err := loadCertificates(ctx, option.HTTPClient.CertDirPath)
-	if err != nil {
-		return nil, fmt.Errorf("certificate loading error: %w", err)
+// gitHosts returns the hosts git is talked to over for a provider, the hosts of the repository urls of a plain git source.
+func gitHosts(option model.GitProviderClientOption) []string {
+	if option.ProviderType != config.GITREMOTE {
+		return []string{option.Domain}
 	}
 
-	proxyFunc, err := setupProxy(ctx, option.HTTPClient.ProxyURL)
-	if err != nil {
-		return nil, fmt.Errorf("proxy setup error: %w", err)
-	}
+	// A urls file that can't be read fails the listing of the source
+	urls, _ := gitremote.RepositoryURLs(option.Repositories)
 
-	tlsConfig := newTLSConfig(ctx, certPool)
-	transport := newHTTPTransport(ctx, proxyFunc, tlsConfig)
-
-	return &http.Client{
-		Transport: transport,
-		// Total timeout for entire request/response cycle
-		Timeout: 30 * time.Second,
-		// Limit redirect chains to prevent infinite loops
-		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-
-			return nil
-		},
-	}, nil
+	return urls
 }
 
-// newHTTPTransport returns an http.Transport with production-ready default settings.
-func newHTTPTransport(ctx context.Context, proxyFunc ProxyFunc, tlsConfig *tls.Config) *http.Transport {
-	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering newHTTPTransport")
-
-	return &http.Transport{
-		// Use proxy url setting or system proxy settings (HTTP_PROXY, HTTPS_PROXY)
-		Proxy: proxyFunc,
-
-		// Maximum time for TLS handshake - prevents hanging on SSL/TLS
-		TLSHandshakeTimeout: 10 * time.Second,
-
-		// Total number of idle connections across all hosts
-		MaxIdleConns: 100,
-
-		// Maximum idle connections per host
-		MaxIdleConnsPerHost: 10,
-
-		// Maximum total connections per host (idle + in-use)
-		MaxConnsPerHost: 100,
-
-		// How long to keep idle connections in pool before closing
-		IdleConnTimeout: 90 * time.Second,
-
-		// Time to wait for server's "100 Continue" response for large requests
-		ExpectContinueTimeout: 1 * time.Second,
-
-		TLSClientConfig: tlsConfig,
-
-		// Connection settings including timeouts and keep-alive
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second, // Time limit for establishing TCP connection
-			KeepAlive: 30 * time.Second, // Interval for TCP keepalive packets
-			DualStack: true,             // Enable both IPv4 and IPv6
-		}).DialContext,
-
-		// Enable HTTP/2 support when available
-		ForceAttemptHTTP2: true,
-
-		// Buffer sizes for reading/writing - 4KB is good balance
-		WriteBufferSize: 4 * 1024,
-		ReadBufferSize:  4 * 1024,
-	}
+// hostTransport sends requests through the transport set for their host.
+// A host without one, like that of a target url outside the configured providers, gets a plain retrying transport.
+type hostTransport struct {
+	mu       sync.RWMutex
+	byHost   map[string]http.RoundTripper
+	fallback http.RoundTripper
 }
 
-func newTLSConfig(ctx context.Context, caCertPool *x509.CertPool) *tls.Config {
-	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering newTLSConfig")
-
-	return &tls.Config{
-		RootCAs:                caCertPool,           // Custom CA cert pool for verification
-		MinVersion:             tls.VersionTLS12,     // Minimum TLS version (good security practice)
-		MaxVersion:             tls.VersionTLS13,     // Maximum TLS version
-		ClientAuth:             tls.NoClientCert,     // No client certificate required
-		Renegotiation:          tls.RenegotiateNever, // Disable renegotiation (security best practice)
-		SessionTicketsDisabled: false,                // Disable session tickets for performance
-		InsecureSkipVerify:     false,                // Ensure certificate verification
+func newHostTransport() *hostTransport {
+	return &hostTransport{
+		byHost:   map[string]http.RoundTripper{},
+		fallback: &retry.Transport{Policy: retry.NewPolicy(config.HTTPClientOption{})},
 	}
 }
 
-// setupProxy configures the proxy.
-func setupProxy(ctx context.Context, proxyURL string) (ProxyFunc, error) {
-	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering setupProxy")
-
-	if proxyURL == "" {
-		return http.ProxyFromEnvironment, nil
-	}
+func (t *hostTransport) set(domain string, transport http.RoundTripper) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
-	parsedURL, err := url.Parse(proxyURL)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrInvalidProxy, err)
+	if host := hostOf(domain); host != "" {
+		t.byHost[host] = transport
 	}
-
-	return http.ProxyURL(parsedURL), nil
 }
 
-// loadCertificates loads certificates.
-func loadCertificates(ctx context.Context, dirPath string) (*x509.CertPool, error) {
-	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering loadCertificates")
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	transport, found := t.byHost[strings.ToLower(req.URL.Host)]
+	t.mu.RUnlock()
 
-	if dirPath == "" {
-		return nil, nil //nolint
+	if !found {
+		transport = t.fallback
 	}
 
-	caCertPool := x509.NewCertPool()
-
-	entries, err := os.ReadDir(dirPath)
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to read directory: %w", ErrCertificateLoad, err)
-	}
-
-	for _, entry := range entries {
-		if err := processCertificateFile(ctx, entry, dirPath, caCertPool); err != nil {
-			return nil, err
-		}
-	}
-
-	return caCertPool, nil
+	return transport.RoundTrip(req) //nolint:wrapcheck
 }
 
-// processCertificateFile handles loading a single certificate file.
-func processCertificateFile(ctx context.Context, entry os.DirEntry, dirPath string, pool *x509.CertPool) error {
-	logger := log.Logger(ctx)
-	logger.Trace().Msg("Entering processCertificateFile")
-
-	if !isCertFile(entry.Name()) {
-		return nil
+// hostOf returns the host of a configured domain, which may also be given as a URL.
+func hostOf(domain string) string {
+	if parsed, err := url.Parse(domain); err == nil && parsed.Host != "" {
+		return strings.ToLower(parsed.Host)
 	}
 
-	certPath := filepath.Join(dirPath, entry.Name())
-	cert, err := os.ReadFile(certPath)
-
-	if err != nil {
-		return fmt.Errorf("%w: failed to read certificate %s: %w", ErrCertificateLoad, certPath, err)
-	}
-
-	if !pool.AppendCertsFromPEM(cert) {
-		return fmt.Errorf("%w: failed to parse certificate %s", ErrCertificateLoad, certPath)
-	}
-
-	return nil
-}
-
-// isCertFile checks if the filename has a certificate extension.
-func isCertFile(filename string) bool {
-	ext := filepath.Ext(filename)
-
-	return ext == ".crt" || ext == ".pem"
+	return strings.ToLower(strings.TrimSuffix(domain, "/"))
 }
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"net/http"
+	"testing"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTransport struct {
+	name string
+	used *string
+}
+
+func (r recordingTransport) RoundTrip(_ *http.Request) (*http.Response, error) {
+	*r.used = r.name
+
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestHostTransport(t *testing.T) {
+	var used string
+
+	transport := newHostTransport()
+	transport.fallback = recordingTransport{name: "fallback", used: &used}
+	transport.set("GitLab.example.com", recordingTransport{name: "gitlab", used: &used})
+	transport.set("https://gitea.example.com:3000/", recordingTransport{name: "gitea", used: &used})
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://gitlab.example.com/group/repo.git/info/refs", want: "gitlab"},
+		{url: "https://gitea.example.com:3000/owner/repo.git/info/refs", want: "gitea"},
+		{url: "https://git.example.com/repo.git/info/refs", want: "fallback"},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.url, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tabletest.url, nil) //nolint:noctx
+			require.NoError(t, err)
+
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.Equal(t, tabletest.want, used)
+		})
+	}
+}
+
+func TestGitHosts(t *testing.T) {
+	require.Equal(t, []string{"gitlab.example.com"}, gitHosts(model.GitProviderClientOption{ProviderType: config.GITLAB, Domain: "gitlab.example.com"}))
+	require.Equal(t, []string{"https://git.example.com/a.git", "https://cgit.example.com/b.git"}, gitHosts(model.GitProviderClientOption{
+		ProviderType: config.GITREMOTE,
+		Repositories: config.RepositoriesOption{URLs: "https://git.example.com/a.git,https://cgit.example.com/b.git"},
+	}))
+}
@@ -6,17 +6,29 @@ package directory
 
 import (
 	"context"
+	"fmt"
 
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
 )
 
 type Client struct{}
 
+func (Client) ArchiveProject(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
 func (Client) CreateProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption) (string, error) {
 	return "", nil
 }
 
+func (Client) DeleteProject(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
 func (Client) Name() string {
 	return config.DIRECTORY
 }
@@ -29,14 +41,92 @@ func (Client) IsValidProjectName(_ context.Context, _ string) bool {
 	return true
 }
 
-func (Client) ProjectInfos(_ context.Context, _ config.ProviderConfig, _ bool) ([]model.ProjectInfo, error) {
-	return nil, nil
+// ProjectInfos lists the repositories to restore when the directory provider is used as a source.
+// FilterProjectInfos keeps the repositories the include/exclude rules of the configuration sync.
+func (Client) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return targetfilter.FilterIncludedExcludedGen()(ctx, cfg, projectinfos) //nolint:wrapcheck
+}
+
+func (Client) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Directory:ProjectInfos")
+
+	if cfg.DirectorySourceDir() == "" {
+		return nil, nil
+	}
+
+	projectinfos, err := LocalProjectInfos(ctx, cfg.DirectorySourceDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository infos. err: %w", err)
+	}
+
+	if filtering {
+		return Client{}.FilterProjectInfos(ctx, cfg, projectinfos)
+	}
+
+	return projectinfos, nil
 }
 
 func (Client) ProtectProject(_ context.Context, _, _, _ string) error {
 	return nil
 }
 
+func (Client) ProtectedRefs(_ context.Context, _ string) ([]string, error) {
+	return nil, nil
+}
+
+func (Client) EnableWiki(_ context.Context, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) RenameProject(_ context.Context, _ string, _ string, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
 func (Client) UnprotectProject(_ context.Context, _, _ string) error {
 	return nil
 }
+
+func (Client) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	return nil, interfaces.ErrNotSupported
+}
+
+func (Client) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	return model.Release{}, interfaces.ErrNotSupported
+}
+
+func (Client) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) SupportsPushMirror() bool {
+	return false
+}
+
+func (Client) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+func (Client) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	return model.Metadata{}, interfaces.ErrNotSupported
+}
+
+func (Client) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	return model.Label{}, interfaces.ErrNotSupported
+}
+
+func (Client) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	return model.Milestone{}, interfaces.ErrNotSupported
+}
+
+func (Client) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	return interfaces.ErrNotSupported
+}
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package directory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	"itiquette/git-provider-sync/internal/target/gitlib"
+)
+
+var ErrReadSourceDir = errors.New("failed to read source directory")
+
+// LocalProjectInfos lists the repositories in sourceDir, laid out as the directory target writes them,
+// one repository per sub directory.
+func LocalProjectInfos(ctx context.Context, sourceDir string) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Directory:LocalProjectInfos")
+	logger.Debug().Str("sourceDir", sourceDir).Msg("Directory:LocalProjectInfos")
+
+	entries, err := os.ReadDir(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrReadSourceDir, sourceDir, err)
+	}
+
+	var projectinfos []model.ProjectInfo
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		projectinfo, err := LocalProjectInfo(ctx, entry.Name(), filepath.Join(sourceDir, entry.Name()))
+		if err != nil {
+			if errors.Is(err, git.ErrRepositoryNotExists) {
+				logger.Debug().Str("name", entry.Name()).Msg("Not a git repository, skipping")
+
+				continue
+			}
+
+			return nil, err
+		}
+
+		projectinfos = append(projectinfos, projectinfo)
+	}
+
+	return projectinfos, nil
+}
+
+// LocalProjectInfo reads the project info of a repository on disk, restoring the
+// default branch from HEAD and the description from the description file.
+func LocalProjectInfo(ctx context.Context, name string, repoDir string) (model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Directory:LocalProjectInfo")
+	logger.Debug().Str("name", name).Str("repoDir", repoDir).Msg("Directory:LocalProjectInfo")
+
+	absPath, err := filepath.Abs(repoDir)
+	if err != nil {
+		return model.ProjectInfo{}, fmt.Errorf("failed to get absolute path of %s: %w", repoDir, err)
+	}
+
+	repo, err := git.PlainOpen(absPath)
+	if err != nil {
+		return model.ProjectInfo{}, fmt.Errorf("failed to open repository %s: %w", absPath, err)
+	}
+
+	defaultBranch := ""
+
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err == nil && head.Type() == plumbing.SymbolicReference {
+		defaultBranch = head.Target().Short()
+	}
+
+	return model.ProjectInfo{
+		OriginalName:  name,
+		HTTPSURL:      absPath,
+		SSHURL:        absPath,
+		DefaultBranch: defaultBranch,
+		Description:   gitlib.NewOperation().Description(ctx, absPath),
+		// Visibility isn't part of a backup, restore conservatively
+		Visibility: "private",
+		ProjectID:  absPath,
+		// Wikis are kept next to their repositories, and restored to their wikis
+		WikiOf: wikiOf(name),
+	}, nil
+}
+
+// wikiOf returns the name of the repository a repository named <repository>.wiki is the wiki of.
+func wikiOf(name string) string {
+	repository, isWiki := strings.CutSuffix(name, model.WikiSuffix)
+	if !isWiki || repository == "" {
+		return ""
+	}
+
+	return repository
+}
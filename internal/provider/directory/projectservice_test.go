@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package directory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/target/gitlib"
+)
+
+// createRepo initializes a repository with one commit on the given default branch, as the directory target leaves it.
+func createRepo(t *testing.T, path string, defaultBranch string, description string) {
+	t.Helper()
+
+	repo, err := git.PlainInitWithOptions(path, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName(defaultBranch)},
+	})
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("initial", &git.CommitOptions{
+		AllowEmptyCommits: true,
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, gitlib.NewOperation().SetDescription(context.Background(), path, description))
+}
+
+func TestClient_ProjectInfos(t *testing.T) {
+	require := require.New(t)
+	sourceDir := t.TempDir()
+
+	createRepo(t, filepath.Join(sourceDir, "first"), "trunk", "the first one")
+	createRepo(t, filepath.Join(sourceDir, "second"), "main", "")
+	createRepo(t, filepath.Join(sourceDir, "second.wiki"), "master", "")
+	require.NoError(os.Mkdir(filepath.Join(sourceDir, "notarepo"), 0o755))
+	require.NoError(os.WriteFile(filepath.Join(sourceDir, "afile"), []byte("content"), 0o600))
+
+	cfg := config.ProviderConfig{
+		ProviderType: config.DIRECTORY,
+		Additional:   map[string]string{"directorysourcedir": sourceDir},
+	}
+
+	infos, err := Client{}.ProjectInfos(context.Background(), cfg, false)
+	require.NoError(err)
+	require.Equal([]model.ProjectInfo{
+		{
+			OriginalName:  "first",
+			HTTPSURL:      filepath.Join(sourceDir, "first"),
+			SSHURL:        filepath.Join(sourceDir, "first"),
+			DefaultBranch: "trunk",
+			Description:   "the first one",
+			Visibility:    "private",
+			ProjectID:     filepath.Join(sourceDir, "first"),
+		},
+		{
+			OriginalName:  "second",
+			HTTPSURL:      filepath.Join(sourceDir, "second"),
+			SSHURL:        filepath.Join(sourceDir, "second"),
+			DefaultBranch: "main",
+			Visibility:    "private",
+			ProjectID:     filepath.Join(sourceDir, "second"),
+		},
+		{
+			OriginalName:  "second.wiki",
+			HTTPSURL:      filepath.Join(sourceDir, "second.wiki"),
+			SSHURL:        filepath.Join(sourceDir, "second.wiki"),
+			DefaultBranch: "master",
+			Visibility:    "private",
+			ProjectID:     filepath.Join(sourceDir, "second.wiki"),
+			WikiOf:        "second",
+		},
+	}, infos)
+
+	cfg.Repositories.Include = "second"
+	infos, err = Client{}.ProjectInfos(context.Background(), cfg, true)
+	require.NoError(err)
+	require.Len(infos, 1)
+	require.Equal("second", infos[0].OriginalName)
+
+	// Used as a target there is nothing to list
+	infos, err = Client{}.ProjectInfos(context.Background(), config.ProviderConfig{}, false)
+	require.NoError(err)
+	require.Empty(infos)
+}
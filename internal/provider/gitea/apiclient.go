@@ -20,9 +20,64 @@ type APIClient struct {
 	raw               *gitea.Client
 	projectService    *ProjectService
 	protectionService *ProtectionService
+	releaseService    *ReleaseService
+	issueService      *IssueService
+	mirrorService     *MirrorService
 	filterService     *FilterService
 }
 
+func (api APIClient) ArchiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:ArchiveProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("Gitea:ArchiveProject")
+
+	err := api.projectService.archiveProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive Gitea project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateIssue(ctx context.Context, owner string, projectName string, issue model.Issue) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:CreateIssue")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Int("number", issue.Number).Msg("Gitea:CreateIssue")
+
+	err := api.issueService.createIssue(ctx, owner, projectName, issue)
+	if err != nil {
+		return fmt.Errorf("failed to create Gitea issue: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateLabel(ctx context.Context, owner string, projectName string, label model.Label) (model.Label, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:CreateLabel")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("label", label.Name).Msg("Gitea:CreateLabel")
+
+	created, err := api.issueService.createLabel(ctx, owner, projectName, label)
+	if err != nil {
+		return model.Label{}, fmt.Errorf("failed to create Gitea label: %w", err)
+	}
+
+	return created, nil
+}
+
+func (api APIClient) CreateMilestone(ctx context.Context, owner string, projectName string, milestone model.Milestone) (model.Milestone, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:CreateMilestone")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("milestone", milestone.Title).Msg("Gitea:CreateMilestone")
+
+	created, err := api.issueService.createMilestone(ctx, owner, projectName, milestone)
+	if err != nil {
+		return model.Milestone{}, fmt.Errorf("failed to create Gitea milestone: %w", err)
+	}
+
+	return created, nil
+}
+
 func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:CreateProject")
@@ -36,6 +91,32 @@ func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfi
 	return projectID, nil
 }
 
+func (api APIClient) DeleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:DeleteProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("Gitea:DeleteProject")
+
+	err := api.projectService.deleteProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete Gitea project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) DownloadReleaseAsset(ctx context.Context, owner string, projectName string, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:DownloadReleaseAsset")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("asset", asset.Name).Msg("Gitea:DownloadReleaseAsset")
+
+	err := api.releaseService.downloadAsset(ctx, asset, path)
+	if err != nil {
+		return fmt.Errorf("failed to download Gitea release asset: %w", err)
+	}
+
+	return nil
+}
+
 func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:IsValidProjectName")
@@ -44,10 +125,40 @@ func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	return IsValidGiteaRepositoryName(name)
 }
 
+func (api APIClient) MirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:MirrorProject")
+	opt.DebugLog(logger).Str("projectID", projectID).Msg("Gitea:MirrorProject")
+
+	if err := api.projectService.mirrorProject(ctx, cfg, opt, projectID); err != nil {
+		return fmt.Errorf("failed to mirror to gitea project: err: %w", err)
+	}
+
+	return nil
+}
+
 func (APIClient) Name() string {
 	return config.GITEA
 }
 
+func (api APIClient) Metadata(ctx context.Context, owner string, projectName string, comments bool) (model.Metadata, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:Metadata")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Bool("comments", comments).Msg("Gitea:Metadata")
+
+	metadata, err := api.issueService.metadata(ctx, owner, projectName, comments)
+	if err != nil {
+		return model.Metadata{}, fmt.Errorf("failed to get Gitea metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return api.filterService.FilterProjectinfos(ctx, cfg, projectinfos)
+}
+
 func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:ProjectInfos")
@@ -59,7 +170,7 @@ func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig
 	}
 
 	if filtering {
-		return api.filterService.FilterProjectinfos(ctx, cfg, projectinfos)
+		return api.FilterProjectInfos(ctx, cfg, projectinfos)
 	}
 
 	return projectinfos, nil
@@ -78,6 +189,84 @@ func (api APIClient) ProtectProject(ctx context.Context, _ string, branch string
 	return nil
 }
 
+func (api APIClient) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("Gitea:ProtectedRefs")
+
+	refs, err := api.protectionService.protectedRefs(ctx, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected refs of %s: %w", projectIDStr, err)
+	}
+
+	return refs, nil
+}
+
+func (api APIClient) PushMirror(ctx context.Context, owner string, projectName string, opt model.PushMirrorOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:PushMirror")
+	opt.DebugLog(logger).Str("owner", owner).Str("projectName", projectName).Msg("Gitea:PushMirror")
+
+	if err := api.mirrorService.pushMirror(ctx, owner, projectName, opt); err != nil {
+		return fmt.Errorf("failed to push mirror Gitea project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) Releases(ctx context.Context, owner string, projectName string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:Releases")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("Gitea:Releases")
+
+	releases, err := api.releaseService.releases(ctx, owner, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Gitea releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+// EnableWiki turns on the wiki of a project, which was created without one or had it turned off.
+func (api APIClient) EnableWiki(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:EnableWiki")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("Gitea:EnableWiki")
+
+	err := api.projectService.enableWiki(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to enable Gitea wiki: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) RenameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:RenameProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("newName", newName).Msg("Gitea:RenameProject")
+
+	err := api.projectService.renameProject(ctx, owner, projectName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename Gitea project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) SaveRelease(ctx context.Context, owner string, projectName string, release model.Release) (model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:SaveRelease")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("tag", release.TagName).Msg("Gitea:SaveRelease")
+
+	saved, err := api.releaseService.saveRelease(ctx, owner, projectName, release)
+	if err != nil {
+		return model.Release{}, fmt.Errorf("failed to save Gitea release: %w", err)
+	}
+
+	return saved, nil
+}
+
 func (api APIClient) SetDefaultBranch(ctx context.Context, owner string, projectName string, branch string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:SetDefaultBranch")
@@ -91,6 +280,11 @@ func (api APIClient) SetDefaultBranch(ctx context.Context, owner string, project
 	return nil
 }
 
+// SupportsPushMirror is true, Gitea repositories push to their push mirrors.
+func (APIClient) SupportsPushMirror() bool {
+	return true
+}
+
 func (api APIClient) UnprotectProject(ctx context.Context, branch string, projectIDStr string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:Unprotect")
@@ -104,6 +298,19 @@ func (api APIClient) UnprotectProject(ctx context.Context, branch string, projec
 	return nil
 }
 
+func (api APIClient) UploadReleaseAsset(ctx context.Context, owner string, projectName string, release model.Release, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:UploadReleaseAsset")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("tag", release.TagName).Str("asset", asset.Name).Msg("Gitea:UploadReleaseAsset")
+
+	err := api.releaseService.uploadAsset(ctx, owner, projectName, release, asset, path)
+	if err != nil {
+		return fmt.Errorf("failed to upload Gitea release asset: %w", err)
+	}
+
+	return nil
+}
+
 func NewGiteaAPIClient(ctx context.Context, option model.GitProviderClientOption, httpClient *http.Client) (APIClient, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:NewGiteaClient")
@@ -132,6 +339,9 @@ func NewGiteaAPIClient(ctx context.Context, option model.GitProviderClientOption
 		raw:               rawClient,
 		projectService:    NewProjectService(rawClient),
 		protectionService: NewProtectionService(rawClient),
+		releaseService:    NewReleaseService(rawClient, httpClient, defaultBaseURL, option.HTTPClient.Token),
+		issueService:      NewIssueService(rawClient),
+		mirrorService:     NewMirrorService(rawClient, httpClient, defaultBaseURL, option.HTTPClient.Token),
 		filterService:     NewFilter(),
 	}, nil
 }
@@ -0,0 +1,346 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+const metadataPageSize = 50
+
+// IssueService handles labels, milestones, and issues. Gitea lists pull requests among the issues,
+// their branches are read from the pull requests.
+type IssueService struct {
+	client *gitea.Client
+}
+
+func NewIssueService(client *gitea.Client) *IssueService {
+	return &IssueService{client: client}
+}
+
+func (i IssueService) metadata(ctx context.Context, owner, projectName string, comments bool) (model.Metadata, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:metadata")
+
+	var metadata model.Metadata
+
+	labelOpt := gitea.ListLabelsOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: metadataPageSize}}
+
+	for {
+		labels, resp, err := i.client.ListRepoLabels(owner, projectName, labelOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list labels. page: %d, err: %w", labelOpt.Page, err)
+		}
+
+		for _, label := range labels {
+			metadata.Labels = append(metadata.Labels, toLabel(label))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		labelOpt.Page = resp.NextPage
+	}
+
+	milestoneOpt := gitea.ListMilestoneOption{State: gitea.StateAll, ListOptions: gitea.ListOptions{Page: 1, PageSize: metadataPageSize}}
+
+	for {
+		milestones, resp, err := i.client.ListRepoMilestones(owner, projectName, milestoneOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list milestones. page: %d, err: %w", milestoneOpt.Page, err)
+		}
+
+		for _, milestone := range milestones {
+			metadata.Milestones = append(metadata.Milestones, *toMilestone(milestone))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		milestoneOpt.Page = resp.NextPage
+	}
+
+	pullRequests, err := i.pullRequests(owner, projectName)
+	if err != nil {
+		return model.Metadata{}, err
+	}
+
+	issueOpt := gitea.ListIssueOption{State: gitea.StateAll, Type: gitea.IssueTypeAll, ListOptions: gitea.ListOptions{Page: 1, PageSize: metadataPageSize}}
+
+	for {
+		issues, resp, err := i.client.ListRepoIssues(owner, projectName, issueOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list issues. page: %d, err: %w", issueOpt.Page, err)
+		}
+
+		for _, issue := range issues {
+			converted := toIssue(issue)
+
+			if issue.PullRequest != nil {
+				converted.MergeRequest = pullRequests[issue.Index]
+				if converted.MergeRequest == nil {
+					converted.MergeRequest = &model.MergeRequest{Merged: issue.PullRequest.HasMerged}
+				}
+			}
+
+			if comments && issue.Comments > 0 {
+				converted.Comments, err = i.comments(owner, projectName, issue.Index)
+				if err != nil {
+					return model.Metadata{}, err
+				}
+			}
+
+			metadata.Issues = append(metadata.Issues, converted)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		issueOpt.Page = resp.NextPage
+	}
+
+	return metadata, nil
+}
+
+// pullRequests returns the branches of the pull requests, by their number.
+func (i IssueService) pullRequests(owner, projectName string) (map[int64]*model.MergeRequest, error) {
+	pullRequests := map[int64]*model.MergeRequest{}
+
+	opt := gitea.ListPullRequestsOptions{State: gitea.StateAll, ListOptions: gitea.ListOptions{Page: 1, PageSize: metadataPageSize}}
+
+	for {
+		page, resp, err := i.client.ListRepoPullRequests(owner, projectName, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests. page: %d, err: %w", opt.Page, err)
+		}
+
+		for _, pullRequest := range page {
+			mergeRequest := &model.MergeRequest{Merged: pullRequest.HasMerged}
+
+			if pullRequest.Head != nil {
+				mergeRequest.SourceBranch = pullRequest.Head.Ref
+			}
+
+			if pullRequest.Base != nil {
+				mergeRequest.TargetBranch = pullRequest.Base.Ref
+			}
+
+			pullRequests[pullRequest.Index] = mergeRequest
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return pullRequests, nil
+}
+
+func (i IssueService) comments(owner, projectName string, index int64) ([]model.IssueComment, error) {
+	var comments []model.IssueComment
+
+	opt := gitea.ListIssueCommentOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: metadataPageSize}}
+
+	for {
+		page, resp, err := i.client.ListIssueComments(owner, projectName, index, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of issue %d. page: %d, err: %w", index, opt.Page, err)
+		}
+
+		for _, comment := range page {
+			createdAt := comment.Created
+
+			comments = append(comments, model.IssueComment{
+				Author:    userName(comment.Poster),
+				Body:      comment.Body,
+				CreatedAt: &createdAt,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return comments, nil
+}
+
+func (i IssueService) createLabel(ctx context.Context, owner, projectName string, label model.Label) (model.Label, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:createLabel")
+
+	created, _, err := i.client.CreateLabel(owner, projectName, gitea.CreateLabelOption{
+		Name:        label.Name,
+		Color:       "#" + label.Color,
+		Description: label.Description,
+	})
+	if err != nil {
+		return model.Label{}, fmt.Errorf("failed to create label. name: %s, err: %w", label.Name, err)
+	}
+
+	return toLabel(created), nil
+}
+
+func (i IssueService) createMilestone(ctx context.Context, owner, projectName string, milestone model.Milestone) (model.Milestone, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:createMilestone")
+
+	state := gitea.StateOpen
+	if milestone.Closed {
+		state = gitea.StateClosed
+	}
+
+	created, _, err := i.client.CreateMilestone(owner, projectName, gitea.CreateMilestoneOption{
+		Title:       milestone.Title,
+		Description: milestone.Description,
+		State:       state,
+		Deadline:    milestone.DueOn,
+	})
+	if err != nil {
+		return model.Milestone{}, fmt.Errorf("failed to create milestone. title: %s, err: %w", milestone.Title, err)
+	}
+
+	return *toMilestone(created), nil
+}
+
+// createIssue creates the issue, closed when it is closed, with its comments. Gitea sets labels and milestones by their ids.
+// It is created marked as incomplete until its comments are added, and deleted again when they can't be.
+func (i IssueService) createIssue(ctx context.Context, owner, projectName string, issue model.Issue) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:createIssue")
+
+	opt := gitea.CreateIssueOption{
+		Title:  issue.Title,
+		Body:   model.IncompleteImportHeader + issue.Body,
+		Closed: issue.Closed,
+	}
+
+	for _, label := range issue.Labels {
+		labelID, err := strconv.ParseInt(label.ID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid label id %s: %w", label.ID, err)
+		}
+
+		opt.Labels = append(opt.Labels, labelID)
+	}
+
+	if issue.Milestone != nil {
+		milestoneID, err := strconv.ParseInt(issue.Milestone.ID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid milestone id %s: %w", issue.Milestone.ID, err)
+		}
+
+		opt.Milestone = milestoneID
+	}
+
+	created, _, err := i.client.CreateIssue(owner, projectName, opt)
+	if err != nil {
+		return fmt.Errorf("failed to create issue. title: %s, err: %w", issue.Title, err)
+	}
+
+	if err := i.completeIssue(owner, projectName, created.Index, issue); err != nil {
+		return errors.Join(err, i.abandonIssue(owner, projectName, created.Index))
+	}
+
+	return nil
+}
+
+// completeIssue adds the comments of an issue being imported, and removes the incomplete import header.
+func (i IssueService) completeIssue(owner, projectName string, index int64, issue model.Issue) error {
+	for _, comment := range issue.Comments {
+		_, _, err := i.client.CreateIssueComment(owner, projectName, index, gitea.CreateIssueCommentOption{Body: comment.Body})
+		if err != nil {
+			return fmt.Errorf("failed to create comment of issue %d: %w", index, err)
+		}
+	}
+
+	if _, _, err := i.client.EditIssue(owner, projectName, index, gitea.EditIssueOption{Body: &issue.Body}); err != nil {
+		return fmt.Errorf("failed to complete issue %d: %w", index, err)
+	}
+
+	return nil
+}
+
+// abandonIssue deletes an issue that couldn't be completed, which needs admin rights on the repository.
+// Without them the issue is closed instead, still marked as incomplete.
+func (i IssueService) abandonIssue(owner, projectName string, index int64) error {
+	if _, err := i.client.DeleteIssue(owner, projectName, index); err == nil {
+		return nil
+	}
+
+	closed := gitea.StateClosed
+	if _, _, err := i.client.EditIssue(owner, projectName, index, gitea.EditIssueOption{State: &closed}); err != nil {
+		return fmt.Errorf("failed to delete or close incomplete issue %d: %w", index, err)
+	}
+
+	return nil
+}
+
+func toLabel(label *gitea.Label) model.Label {
+	return model.Label{
+		ID:          strconv.FormatInt(label.ID, 10),
+		Name:        label.Name,
+		Color:       strings.TrimPrefix(label.Color, "#"),
+		Description: label.Description,
+	}
+}
+
+func toMilestone(milestone *gitea.Milestone) *model.Milestone {
+	if milestone == nil {
+		return nil
+	}
+
+	return &model.Milestone{
+		ID:          strconv.FormatInt(milestone.ID, 10),
+		Title:       milestone.Title,
+		Description: milestone.Description,
+		Closed:      milestone.State == gitea.StateClosed,
+		DueOn:       milestone.Deadline,
+	}
+}
+
+func toIssue(issue *gitea.Issue) model.Issue {
+	labels := make([]model.Label, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, toLabel(label))
+	}
+
+	createdAt := issue.Created
+
+	return model.Issue{
+		Number:    int(issue.Index),
+		Title:     issue.Title,
+		Body:      issue.Body,
+		Author:    userName(issue.Poster),
+		CreatedAt: &createdAt,
+		Closed:    issue.State == gitea.StateClosed,
+		Labels:    labels,
+		Milestone: toMilestone(issue.Milestone),
+	}
+}
+
+func userName(user *gitea.User) string {
+	if user == nil {
+		return ""
+	}
+
+	return user.UserName
+}
@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"itiquette/git-provider-sync/internal/model"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIssue(t *testing.T) {
+	tests := []struct {
+		name        string
+		commentCode int
+		wantErr     bool
+		wantCalls   []string
+	}{
+		{
+			name:        "completed",
+			commentCode: http.StatusCreated,
+			wantCalls:   []string{"POST /issues", "POST /issues/7/comments", "PATCH /issues/7"},
+		},
+		{
+			name:        "deleted when a comment fails",
+			commentCode: http.StatusInternalServerError,
+			wantErr:     true,
+			wantCalls:   []string{"POST /issues", "POST /issues/7/comments", "DELETE /issues/7"},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				calls   []string
+				created string
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				path := strings.TrimPrefix(request.URL.Path, "/api/v1/repos/owner/repo")
+				calls = append(calls, request.Method+" "+path)
+
+				switch {
+				case request.Method == http.MethodPost && path == "/issues":
+					body, _ := io.ReadAll(request.Body)
+
+					var opt gitea.CreateIssueOption
+					_ = json.Unmarshal(body, &opt)
+					created = opt.Body
+
+					writer.WriteHeader(http.StatusCreated)
+					_, _ = writer.Write([]byte(`{"number": 7}`))
+				case path == "/issues/7/comments":
+					writer.WriteHeader(tabletest.commentCode)
+					_, _ = writer.Write([]byte(`{}`))
+				case request.Method == http.MethodDelete:
+					writer.WriteHeader(http.StatusNoContent)
+				default:
+					_, _ = writer.Write([]byte(`{"number": 7}`))
+				}
+			}))
+			defer server.Close()
+
+			client, err := gitea.NewClient(server.URL, gitea.SetGiteaVersion(""))
+			require.NoError(t, err)
+
+			issue := model.Issue{Title: "bug", Body: "*Originally issue #1 by @me*", Comments: []model.IssueComment{{Body: "comment"}}}
+			err = NewIssueService(client).createIssue(context.Background(), "owner", "repo", issue)
+
+			if tabletest.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, model.IncompleteImportHeader+issue.Body, created)
+			require.Equal(t, tabletest.wantCalls, calls)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/httpclient"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+var (
+	ErrUnexpectedStatus    = errors.New("unexpected response status")
+	ErrMirrorRemoteChanged = errors.New("mirror remote changed")
+)
+
+// pushMirrorInterval is how often Gitea pushes to a push mirror, besides after every push to the repository.
+const pushMirrorInterval = "8h0m0s"
+
+// MirrorService handles the push mirrors a repository pushes itself to.
+// The client library can add push mirrors, but neither list nor remove them, those are requested directly.
+type MirrorService struct {
+	client     *gitea.Client
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func NewMirrorService(client *gitea.Client, httpClient *http.Client, baseURL, token string) *MirrorService {
+	return &MirrorService{client: client, httpClient: httpClient, baseURL: baseURL, token: token}
+}
+
+// pushMirror adds a push mirror of the repository pushing to the target repository, and starts an update of its push mirrors.
+// Gitea can't change the url or credentials of a push mirror, nor does it list the credentials,
+// so a push mirror already pushing to the target repository is replaced by one with the current ones.
+func (m MirrorService) pushMirror(ctx context.Context, owner, projectName string, opt model.PushMirrorOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:pushMirror")
+
+	var mirrors []gitea.PushMirrorResponse
+
+	err := m.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/push_mirrors", url.PathEscape(owner), url.PathEscape(projectName)), &mirrors)
+	if err != nil {
+		return fmt.Errorf("failed to list push mirrors. name: %s, err: %w", projectName, err)
+	}
+
+	for _, mirror := range mirrors {
+		if !opt.Targets(mirror.RemoteAddress) {
+			continue
+		}
+
+		err := m.do(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/%s/push_mirrors/%s", url.PathEscape(owner), url.PathEscape(projectName), url.PathEscape(mirror.RemoteName)), nil)
+		if err != nil {
+			return fmt.Errorf("failed to remove push mirror. name: %s, err: %w", projectName, err)
+		}
+
+		logger.Debug().Str("name", projectName).Msg("Push mirror removed, to be added with the current url and token")
+	}
+
+	_, _, err = m.client.PushMirrors(owner, projectName, gitea.CreatePushMirrorOption{
+		RemoteAddress:  opt.URL,
+		RemoteUsername: httpclient.GitUsername(opt.URL),
+		RemotePassword: opt.Token,
+		Interval:       pushMirrorInterval,
+		SyncONCommit:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add push mirror. name: %s, err: %w", projectName, err)
+	}
+
+	logger.Debug().Str("name", projectName).Msg("Push mirror added")
+
+	err = m.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/push_mirrors-sync", url.PathEscape(owner), url.PathEscape(projectName)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to start push mirror update. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (m MirrorService) do(ctx context.Context, method, path string, result any) error {
+	endpoint := strings.TrimSuffix(m.baseURL, "/") + "/api/v1" + path
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if m.token != "" {
+		req.Header.Set("Authorization", "token "+m.token)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to do %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		return fmt.Errorf("%w: %s %s: %d %s", ErrUnexpectedStatus, method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
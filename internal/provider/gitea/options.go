@@ -30,7 +30,8 @@ func (p *ProjectOptionsBuilder) BasicOpts(_, name, description, defaultBranch st
 	p.opts.DefaultBranch = defaultBranch //	builder.opts.Private = toVisibility(/* visibility */)
 }
 
-func (p ProjectService) ApplyDisabledSettings(ctx context.Context, owner, projectName string) error {
+// ApplyDisabledSettings disables the features of the repository, but the wiki, releases, and issues when they are to be kept.
+func (p ProjectService) ApplyDisabledSettings(ctx context.Context, owner, projectName string, keepWiki, keepReleases, keepIssues bool) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering gitea:ApplyDisabledSettings")
 	logger.Debug().Str("owner", owner).Str("repo", projectName).Msg("Entering gitea:ApplyDisabledSettings")
@@ -46,11 +47,11 @@ func (p ProjectService) ApplyDisabledSettings(ctx context.Context, owner, projec
 	}
 
 	// Disable all features
-	*editOpts.HasIssues = false
-	*editOpts.HasWiki = false
+	*editOpts.HasIssues = keepIssues
+	*editOpts.HasWiki = keepWiki
 	*editOpts.HasProjects = false
 	*editOpts.HasPullRequests = false
-	*editOpts.HasReleases = false
+	*editOpts.HasReleases = keepReleases
 	*editOpts.HasActions = false
 
 	_, _, err := p.client.EditRepo(owner, projectName, editOpts)
@@ -7,9 +7,12 @@ package gitea
 import (
 	"context"
 	"fmt"
+	"itiquette/git-provider-sync/internal/httpclient"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"net/url"
+	"strings"
 
 	"code.gitea.io/sdk/gitea"
 )
@@ -46,7 +49,7 @@ func (p ProjectService) createProject(ctx context.Context, cfg config.ProviderCo
 	}
 
 	if opt.Disabled {
-		err = p.ApplyDisabledSettings(ctx, createdRepo.Owner.UserName, opt.RepositoryName)
+		err = p.ApplyDisabledSettings(ctx, createdRepo.Owner.UserName, opt.RepositoryName, opt.Wiki, opt.Releases, opt.Issues)
 		if err != nil {
 			return "", fmt.Errorf("failed to apply disabled settings for repo %s: %w", opt.RepositoryName, err)
 		}
@@ -57,6 +60,91 @@ func (p ProjectService) createProject(ctx context.Context, cfg config.ProviderCo
 	return createdRepo.FullName, nil
 }
 
+// mirrorProject migrates the source repository to a pull mirror, or syncs the existing mirror.
+// Gitea can't turn a repository into a mirror, an existing repository that is not one is an error.
+// Neither can Gitea change the remote of a mirror, a mirror of another url is an error too, as migrating it anew
+// would lose what only the repository at the target has.
+func (p ProjectService) mirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering gitea:mirrorProject")
+
+	owner := cfg.User
+	if cfg.IsGroup() {
+		owner = cfg.Group
+	}
+
+	if projectID == "" {
+		return p.migrateMirror(ctx, owner, opt)
+	}
+
+	repository, _, err := p.client.GetRepo(owner, opt.RepositoryName)
+	if err != nil {
+		return fmt.Errorf("failed to get repository. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	if !repository.Mirror {
+		return fmt.Errorf("repository %s exists and is not a mirror, it can't be turned into one", opt.RepositoryName)
+	}
+
+	if !sameRemote(repository.OriginalURL, opt.MirrorURL) {
+		return fmt.Errorf("%w: %s mirrors %s, not %s, delete it at the target to have it migrated anew",
+			ErrMirrorRemoteChanged, opt.RepositoryName, repository.OriginalURL, opt.MirrorURL)
+	}
+
+	if _, err := p.client.MirrorSync(owner, opt.RepositoryName); err != nil {
+		return fmt.Errorf("failed to sync mirror. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	return nil
+}
+
+// migrateMirror migrates the source repository to a new pull mirror.
+func (p ProjectService) migrateMirror(ctx context.Context, owner string, opt model.CreateProjectOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering gitea:migrateMirror")
+
+	_, _, err := p.client.MigrateRepo(gitea.MigrateRepoOption{
+		RepoName:     opt.RepositoryName,
+		RepoOwner:    owner,
+		CloneAddr:    opt.MirrorURL,
+		Service:      gitea.GitServicePlain,
+		AuthUsername: httpclient.GitUsername(opt.MirrorURL),
+		AuthPassword: opt.MirrorToken,
+		Mirror:       true,
+		Private:      strings.EqualFold(opt.Visibility, "private"),
+		Description:  opt.Description,
+		Wiki:         opt.Wiki,
+		LFS:          opt.LFS,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create mirror. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	if opt.Disabled {
+		if err := p.ApplyDisabledSettings(ctx, owner, opt.RepositoryName, opt.Wiki, opt.Releases, opt.Issues); err != nil {
+			return fmt.Errorf("failed to apply disabled settings for repo %s: %w", opt.RepositoryName, err)
+		}
+	}
+
+	logger.Debug().Str("name", opt.RepositoryName).Msg("Mirror created, Gitea fetches it in the background")
+
+	return nil
+}
+
+// sameRemote returns true if two repository urls are the same remote, regardless of credentials, case and .git suffix.
+func sameRemote(remoteURL, otherURL string) bool {
+	normalize := func(repositoryURL string) string {
+		if parsedURL, err := url.Parse(repositoryURL); err == nil {
+			parsedURL.User = nil
+			repositoryURL = parsedURL.String()
+		}
+
+		return strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(repositoryURL, "/"), ".git"))
+	}
+
+	return normalize(remoteURL) == normalize(otherURL)
+}
+
 func (p ProjectService) newProjectInfo(ctx context.Context, config config.ProviderConfig, rawClient *gitea.Client, repositoryName string) (model.ProjectInfo, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Gitea:newProjectInfo")
@@ -80,6 +168,8 @@ func (p ProjectService) newProjectInfo(ctx context.Context, config config.Provid
 		LastActivityAt: &giteaProject.Updated,
 		Visibility:     string(giteaProject.Owner.Visibility),
 		ProjectID:      giteaProject.FullName,
+		Archived:       giteaProject.Archived,
+		HasWiki:        giteaProject.HasWiki,
 	}, nil
 }
 
@@ -146,3 +236,55 @@ func (p ProjectService) setDefaultBranch(ctx context.Context, owner string, proj
 
 	return nil
 }
+
+func (p ProjectService) archiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering gitea:archiveProject")
+
+	archived := true
+
+	_, _, err := p.client.EditRepo(owner, projectName, gitea.EditRepoOption{Archived: &archived})
+	if err != nil {
+		return fmt.Errorf("failed to archive project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) enableWiki(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering gitea:enableWiki")
+
+	hasWiki := true
+
+	_, _, err := p.client.EditRepo(owner, projectName, gitea.EditRepoOption{HasWiki: &hasWiki})
+	if err != nil {
+		return fmt.Errorf("failed to enable wiki. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) renameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering gitea:renameProject")
+
+	_, _, err := p.client.EditRepo(owner, projectName, gitea.EditRepoOption{Name: &newName})
+	if err != nil {
+		return fmt.Errorf("failed to rename project. name: %s, newName: %s, err: %w", projectName, newName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) deleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering gitea:deleteProject")
+
+	_, err := p.client.DeleteRepo(owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
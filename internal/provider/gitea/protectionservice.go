@@ -83,6 +83,37 @@ func (p ProtectionService) unprotect(ctx context.Context, branch string, project
 	return nil
 }
 
+// protectedRefs returns the branch protection rules of the repository as reference patterns.
+// The client library has no tag protections, those are not listed.
+func (p ProtectionService) protectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:protectedRefs")
+	logger.Debug().Str("projectID", projectIDStr).Msg("protectedRefs")
+
+	owner, repoName := splitProjectPath(projectIDStr)
+	if owner == "" || repoName == "" {
+		return nil, fmt.Errorf("invalid project path: %s", projectIDStr)
+	}
+
+	protections, _, err := p.client.ListBranchProtections(owner, repoName, gitea.ListBranchProtectionsOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branch protections: %w", err)
+	}
+
+	refs := make([]string, 0, len(protections))
+
+	for _, protection := range protections {
+		rule := protection.RuleName
+		if rule == "" {
+			rule = protection.BranchName
+		}
+
+		refs = append(refs, "refs/heads/"+rule)
+	}
+
+	return refs, nil
+}
+
 func (p ProtectionService) enableTagProtection(ctx context.Context, owner, repo string) error { //nolint
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering enableTagProtection")
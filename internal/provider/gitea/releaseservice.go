@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	"itiquette/git-provider-sync/internal/provider/assetfile"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// ReleaseService handles releases, with their assets as release attachments.
+type ReleaseService struct {
+	client     *gitea.Client
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func NewReleaseService(client *gitea.Client, httpClient *http.Client, baseURL, token string) *ReleaseService {
+	return &ReleaseService{client: client, httpClient: httpClient, baseURL: baseURL, token: token}
+}
+
+func (r ReleaseService) releases(ctx context.Context, owner, projectName string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:releases")
+
+	opt := gitea.ListReleasesOptions{
+		ListOptions: gitea.ListOptions{
+			Page:     -1, // Set to -1 to get all items
+			PageSize: -1,
+		},
+	}
+
+	giteaReleases, _, err := r.client.ListReleases(owner, projectName, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	releases := make([]model.Release, 0, len(giteaReleases))
+	for _, release := range giteaReleases {
+		releases = append(releases, toRelease(release))
+	}
+
+	return releases, nil
+}
+
+func (r ReleaseService) saveRelease(ctx context.Context, owner, projectName string, release model.Release) (model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:saveRelease")
+
+	if release.ID == "" {
+		created, _, err := r.client.CreateRelease(owner, projectName, gitea.CreateReleaseOption{
+			TagName:      release.TagName,
+			Title:        release.Name,
+			Note:         release.Notes,
+			IsDraft:      release.Draft,
+			IsPrerelease: release.Prerelease,
+		})
+		if err != nil {
+			return model.Release{}, fmt.Errorf("failed to create release. tag: %s, err: %w", release.TagName, err)
+		}
+
+		return toRelease(created), nil
+	}
+
+	releaseID, err := strconv.ParseInt(release.ID, 10, 64)
+	if err != nil {
+		return model.Release{}, fmt.Errorf("invalid release id %s: %w", release.ID, err)
+	}
+
+	edited, _, err := r.client.EditRelease(owner, projectName, releaseID, gitea.EditReleaseOption{
+		TagName:      release.TagName,
+		Title:        release.Name,
+		Note:         release.Notes,
+		IsDraft:      &release.Draft,
+		IsPrerelease: &release.Prerelease,
+	})
+	if err != nil {
+		return model.Release{}, fmt.Errorf("failed to edit release. tag: %s, err: %w", release.TagName, err)
+	}
+
+	return toRelease(edited), nil
+}
+
+// downloadAsset downloads an attachment. The token is only sent to the Gitea instance itself.
+func (r ReleaseService) downloadAsset(ctx context.Context, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:downloadAsset")
+
+	header := http.Header{}
+
+	assetURL, assetErr := url.Parse(asset.URL)
+	baseURL, baseErr := url.Parse(r.baseURL)
+
+	if assetErr == nil && baseErr == nil && assetURL.Host == baseURL.Host && r.token != "" {
+		header.Set("Authorization", "token "+r.token)
+	}
+
+	return assetfile.Download(ctx, r.httpClient, asset.URL, header, path) //nolint:wrapcheck
+}
+
+func (r ReleaseService) uploadAsset(ctx context.Context, owner, projectName string, release model.Release, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Gitea:uploadAsset")
+
+	releaseID, err := strconv.ParseInt(release.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid release id %s: %w", release.ID, err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open asset %s: %w", path, err)
+	}
+	defer file.Close()
+
+	_, _, err = r.client.CreateReleaseAttachment(owner, projectName, releaseID, file, asset.Name)
+	if err != nil {
+		return fmt.Errorf("failed to upload asset. name: %s, err: %w", asset.Name, err)
+	}
+
+	return nil
+}
+
+func toRelease(release *gitea.Release) model.Release {
+	assets := make([]model.ReleaseAsset, 0, len(release.Attachments))
+
+	for _, attachment := range release.Attachments {
+		assets = append(assets, model.ReleaseAsset{
+			ID:   strconv.FormatInt(attachment.ID, 10),
+			Name: attachment.Name,
+			Size: attachment.Size,
+			URL:  attachment.DownloadURL,
+		})
+	}
+
+	createdAt := release.CreatedAt
+
+	return model.Release{
+		ID:         strconv.FormatInt(release.ID, 10),
+		TagName:    release.TagName,
+		Name:       release.Title,
+		Notes:      release.Note,
+		Draft:      release.IsDraft,
+		Prerelease: release.IsPrerelease,
+		CreatedAt:  &createdAt,
+		Assets:     assets,
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
@@ -20,9 +22,63 @@ type APIClient struct {
 	raw               *github.Client
 	projectService    *ProjectService
 	protectionService *ProtectionService
+	releaseService    *ReleaseService
+	issueService      *IssueService
 	filterService     *filterService
 }
 
+func (api APIClient) ArchiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:ArchiveProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitHub:ArchiveProject")
+
+	err := api.projectService.archiveProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive GitHub project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateIssue(ctx context.Context, owner string, projectName string, issue model.Issue) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:CreateIssue")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Int("number", issue.Number).Msg("GitHub:CreateIssue")
+
+	err := api.issueService.createIssue(ctx, owner, projectName, issue)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub issue: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateLabel(ctx context.Context, owner string, projectName string, label model.Label) (model.Label, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:CreateLabel")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("label", label.Name).Msg("GitHub:CreateLabel")
+
+	created, err := api.issueService.createLabel(ctx, owner, projectName, label)
+	if err != nil {
+		return model.Label{}, fmt.Errorf("failed to create GitHub label: %w", err)
+	}
+
+	return created, nil
+}
+
+func (api APIClient) CreateMilestone(ctx context.Context, owner string, projectName string, milestone model.Milestone) (model.Milestone, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:CreateMilestone")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("milestone", milestone.Title).Msg("GitHub:CreateMilestone")
+
+	created, err := api.issueService.createMilestone(ctx, owner, projectName, milestone)
+	if err != nil {
+		return model.Milestone{}, fmt.Errorf("failed to create GitHub milestone: %w", err)
+	}
+
+	return created, nil
+}
+
 func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitHub:CreateProject")
@@ -36,6 +92,32 @@ func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfi
 	return projectID, nil
 }
 
+func (api APIClient) DeleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:DeleteProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitHub:DeleteProject")
+
+	err := api.projectService.deleteProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete GitHub project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) DownloadReleaseAsset(ctx context.Context, owner string, projectName string, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:DownloadReleaseAsset")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("asset", asset.Name).Msg("GitHub:DownloadReleaseAsset")
+
+	err := api.releaseService.downloadAsset(ctx, owner, projectName, asset, path)
+	if err != nil {
+		return fmt.Errorf("failed to download GitHub release asset: %w", err)
+	}
+
+	return nil
+}
+
 func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitHub:IsValidProjectName")
@@ -51,10 +133,42 @@ func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	return true
 }
 
+// MirrorProject is not supported, GitHub has no pull mirrors.
+func (api APIClient) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+// SupportsPushMirror is false, GitHub has no push mirrors.
+func (api APIClient) SupportsPushMirror() bool {
+	return false
+}
+
+func (api APIClient) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) Metadata(ctx context.Context, owner string, projectName string, comments bool) (model.Metadata, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:Metadata")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Bool("comments", comments).Msg("GitHub:Metadata")
+
+	metadata, err := api.issueService.metadata(ctx, owner, projectName, comments)
+	if err != nil {
+		return model.Metadata{}, fmt.Errorf("failed to get GitHub metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
 func (api APIClient) Name() string {
 	return config.GITHUB
 }
 
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return api.filterService.FilterProjectInfos(ctx, cfg, projectinfos)
+}
+
 func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitHub:ProjectInfos")
@@ -66,7 +180,7 @@ func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig
 	}
 
 	if filtering {
-		return api.filterService.FilterProjectInfos(ctx, cfg, projectinfos)
+		return api.FilterProjectInfos(ctx, cfg, projectinfos)
 	}
 
 	return projectinfos, nil
@@ -90,6 +204,77 @@ func (api APIClient) ProtectProject(ctx context.Context, owner string, _, projec
 	return nil
 }
 
+func (api APIClient) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("GitHub:ProtectedRefs")
+
+	owner, project, err := splitProjectPath(projectIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := api.protectionService.protectedRefs(ctx, owner, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected refs. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return refs, nil
+}
+
+func (api APIClient) Releases(ctx context.Context, owner string, projectName string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:Releases")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitHub:Releases")
+
+	releases, err := api.releaseService.releases(ctx, owner, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitHub releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+// EnableWiki turns on the wiki of a project, which was created without one or had it turned off.
+func (api APIClient) EnableWiki(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:EnableWiki")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitHub:EnableWiki")
+
+	err := api.projectService.enableWiki(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to enable GitHub wiki: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) RenameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:RenameProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("newName", newName).Msg("GitHub:RenameProject")
+
+	err := api.projectService.renameProject(ctx, owner, projectName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename GitHub project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) SaveRelease(ctx context.Context, owner string, projectName string, release model.Release) (model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:SaveRelease")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("tag", release.TagName).Msg("GitHub:SaveRelease")
+
+	saved, err := api.releaseService.saveRelease(ctx, owner, projectName, release)
+	if err != nil {
+		return model.Release{}, fmt.Errorf("failed to save GitHub release: %w", err)
+	}
+
+	return saved, nil
+}
+
 func (api APIClient) SetDefaultBranch(ctx context.Context, owner string, projectName string, branch string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitHub:SetDefaultBranch")
@@ -121,6 +306,19 @@ func (api APIClient) UnprotectProject(ctx context.Context, defaultBranch, projec
 	return nil
 }
 
+func (api APIClient) UploadReleaseAsset(ctx context.Context, owner string, projectName string, release model.Release, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:UploadReleaseAsset")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("tag", release.TagName).Str("asset", asset.Name).Msg("GitHub:UploadReleaseAsset")
+
+	err := api.releaseService.uploadAsset(ctx, owner, projectName, release, asset, path)
+	if err != nil {
+		return fmt.Errorf("failed to upload GitHub release asset: %w", err)
+	}
+
+	return nil
+}
+
 func NewGitHubAPIClient(ctx context.Context, opt model.GitProviderClientOption, httpClient *http.Client) (APIClient, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitHub:NewGitHubClient")
@@ -140,6 +338,13 @@ func NewGitHubAPIClient(ctx context.Context, opt model.GitProviderClientOption,
 
 	if opt.UploadURL == "" {
 		rawClient.UploadURL, _ = url.Parse(uploadBaseURL)
+	} else {
+		uploadURL, err := url.Parse(strings.TrimSuffix(opt.UploadURL, "/") + "/")
+		if err != nil {
+			return APIClient{}, fmt.Errorf("invalid githubuploadurl %s: %w", opt.UploadURL, err)
+		}
+
+		rawClient.UploadURL = uploadURL
 	}
 
 	// TODO: secondary rate limiting check
@@ -148,6 +353,8 @@ func NewGitHubAPIClient(ctx context.Context, opt model.GitProviderClientOption,
 		raw:               rawClient,
 		projectService:    NewProjectService(rawClient),
 		protectionService: NewProtectionService(rawClient),
+		releaseService:    NewReleaseService(rawClient, httpClient),
+		issueService:      NewIssueService(rawClient),
 		filterService:     NewFilter(),
 	}, nil
 }
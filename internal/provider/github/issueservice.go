@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// IssueService handles labels, milestones, and issues. GitHub lists pull requests among the issues,
+// their branches are read from the pull requests.
+type IssueService struct {
+	client *github.Client
+}
+
+func NewIssueService(client *github.Client) *IssueService {
+	return &IssueService{client: client}
+}
+
+func (i IssueService) metadata(ctx context.Context, owner, projectName string, comments bool) (model.Metadata, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:metadata")
+
+	var metadata model.Metadata
+
+	labelOpt := &github.ListOptions{PerPage: 100}
+
+	for {
+		labels, resp, err := i.client.Issues.ListLabels(ctx, owner, projectName, labelOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list labels. page: %d, err: %w", labelOpt.Page, err)
+		}
+
+		for _, label := range labels {
+			metadata.Labels = append(metadata.Labels, toLabel(label))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		labelOpt.Page = resp.NextPage
+	}
+
+	milestoneOpt := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		milestones, resp, err := i.client.Issues.ListMilestones(ctx, owner, projectName, milestoneOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list milestones. page: %d, err: %w", milestoneOpt.Page, err)
+		}
+
+		for _, milestone := range milestones {
+			metadata.Milestones = append(metadata.Milestones, *toMilestone(milestone))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		milestoneOpt.Page = resp.NextPage
+	}
+
+	pullRequests, err := i.pullRequests(ctx, owner, projectName)
+	if err != nil {
+		return model.Metadata{}, err
+	}
+
+	issueOpt := &github.IssueListByRepoOptions{State: "all", Sort: "created", Direction: "asc", ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		issues, resp, err := i.client.Issues.ListByRepo(ctx, owner, projectName, issueOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list issues. page: %d, err: %w", issueOpt.Page, err)
+		}
+
+		for _, issue := range issues {
+			converted := toIssue(issue)
+
+			if issue.IsPullRequest() {
+				converted.MergeRequest = pullRequests[issue.GetNumber()]
+				if converted.MergeRequest == nil {
+					converted.MergeRequest = &model.MergeRequest{}
+				}
+			}
+
+			if comments && issue.GetComments() > 0 {
+				converted.Comments, err = i.comments(ctx, owner, projectName, issue.GetNumber())
+				if err != nil {
+					return model.Metadata{}, err
+				}
+			}
+
+			metadata.Issues = append(metadata.Issues, converted)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		issueOpt.Page = resp.NextPage
+	}
+
+	return metadata, nil
+}
+
+// pullRequests returns the branches of the pull requests, by their number.
+func (i IssueService) pullRequests(ctx context.Context, owner, projectName string) (map[int]*model.MergeRequest, error) {
+	pullRequests := map[int]*model.MergeRequest{}
+
+	opt := &github.PullRequestListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		page, resp, err := i.client.PullRequests.List(ctx, owner, projectName, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests. page: %d, err: %w", opt.Page, err)
+		}
+
+		for _, pullRequest := range page {
+			pullRequests[pullRequest.GetNumber()] = &model.MergeRequest{
+				SourceBranch: pullRequest.GetHead().GetRef(),
+				TargetBranch: pullRequest.GetBase().GetRef(),
+				Merged:       pullRequest.MergedAt != nil,
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return pullRequests, nil
+}
+
+func (i IssueService) comments(ctx context.Context, owner, projectName string, number int) ([]model.IssueComment, error) {
+	var comments []model.IssueComment
+
+	opt := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		page, resp, err := i.client.Issues.ListComments(ctx, owner, projectName, number, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments of issue %d. page: %d, err: %w", number, opt.Page, err)
+		}
+
+		for _, comment := range page {
+			comments = append(comments, model.IssueComment{
+				Author:    comment.GetUser().GetLogin(),
+				Body:      comment.GetBody(),
+				CreatedAt: getTimeOrNil(comment.CreatedAt),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return comments, nil
+}
+
+func (i IssueService) createLabel(ctx context.Context, owner, projectName string, label model.Label) (model.Label, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:createLabel")
+
+	created, _, err := i.client.Issues.CreateLabel(ctx, owner, projectName, &github.Label{
+		Name:        github.String(label.Name),
+		Color:       github.String(label.Color),
+		Description: github.String(label.Description),
+	})
+	if err != nil {
+		return model.Label{}, fmt.Errorf("failed to create label. name: %s, err: %w", label.Name, err)
+	}
+
+	return toLabel(created), nil
+}
+
+func (i IssueService) createMilestone(ctx context.Context, owner, projectName string, milestone model.Milestone) (model.Milestone, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:createMilestone")
+
+	state := "open"
+	if milestone.Closed {
+		state = "closed"
+	}
+
+	githubMilestone := &github.Milestone{
+		Title:       github.String(milestone.Title),
+		Description: github.String(milestone.Description),
+		State:       github.String(state),
+	}
+
+	if milestone.DueOn != nil {
+		githubMilestone.DueOn = &github.Timestamp{Time: *milestone.DueOn}
+	}
+
+	created, _, err := i.client.Issues.CreateMilestone(ctx, owner, projectName, githubMilestone)
+	if err != nil {
+		return model.Milestone{}, fmt.Errorf("failed to create milestone. title: %s, err: %w", milestone.Title, err)
+	}
+
+	return *toMilestone(created), nil
+}
+
+// createIssue creates the issue with its comments, and closes it when it is closed. It is created marked as incomplete
+// until then, and closed when it can't be completed, as GitHub issues can't be deleted with the REST API.
+func (i IssueService) createIssue(ctx context.Context, owner, projectName string, issue model.Issue) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:createIssue")
+
+	labels := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	request := &github.IssueRequest{
+		Title:  github.String(issue.Title),
+		Body:   github.String(model.IncompleteImportHeader + issue.Body),
+		Labels: &labels,
+	}
+
+	if issue.Milestone != nil {
+		number, err := strconv.Atoi(issue.Milestone.ID)
+		if err != nil {
+			return fmt.Errorf("invalid milestone number %s: %w", issue.Milestone.ID, err)
+		}
+
+		request.Milestone = &number
+	}
+
+	created, _, err := i.client.Issues.Create(ctx, owner, projectName, request)
+	if err != nil {
+		return fmt.Errorf("failed to create issue. title: %s, err: %w", issue.Title, err)
+	}
+
+	if err := i.completeIssue(ctx, owner, projectName, created.GetNumber(), issue); err != nil {
+		_, _, closeErr := i.client.Issues.Edit(ctx, owner, projectName, created.GetNumber(), &github.IssueRequest{State: github.String("closed")})
+		if closeErr != nil {
+			closeErr = fmt.Errorf("failed to close incomplete issue %d: %w", created.GetNumber(), closeErr)
+		}
+
+		return errors.Join(err, closeErr)
+	}
+
+	return nil
+}
+
+// completeIssue adds the comments of an issue being imported, closes it when it is closed,
+// and removes the incomplete import header.
+func (i IssueService) completeIssue(ctx context.Context, owner, projectName string, number int, issue model.Issue) error {
+	for _, comment := range issue.Comments {
+		_, _, err := i.client.Issues.CreateComment(ctx, owner, projectName, number, &github.IssueComment{Body: github.String(comment.Body)})
+		if err != nil {
+			return fmt.Errorf("failed to create comment of issue %d: %w", number, err)
+		}
+	}
+
+	request := &github.IssueRequest{Body: github.String(issue.Body)}
+	if issue.Closed {
+		request.State = github.String("closed")
+	}
+
+	if _, _, err := i.client.Issues.Edit(ctx, owner, projectName, number, request); err != nil {
+		return fmt.Errorf("failed to complete issue %d: %w", number, err)
+	}
+
+	return nil
+}
+
+func toLabel(label *github.Label) model.Label {
+	return model.Label{
+		ID:          strconv.FormatInt(label.GetID(), 10),
+		Name:        label.GetName(),
+		Color:       label.GetColor(),
+		Description: label.GetDescription(),
+	}
+}
+
+// toMilestone converts a milestone, which GitHub knows by its number.
+func toMilestone(milestone *github.Milestone) *model.Milestone {
+	if milestone == nil {
+		return nil
+	}
+
+	return &model.Milestone{
+		ID:          strconv.Itoa(milestone.GetNumber()),
+		Title:       milestone.GetTitle(),
+		Description: milestone.GetDescription(),
+		Closed:      milestone.GetState() == "closed",
+		DueOn:       getTimeOrNil(milestone.DueOn),
+	}
+}
+
+func toIssue(issue *github.Issue) model.Issue {
+	labels := make([]model.Label, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, toLabel(label))
+	}
+
+	return model.Issue{
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		Body:      issue.GetBody(),
+		Author:    issue.GetUser().GetLogin(),
+		CreatedAt: getTimeOrNil(issue.CreatedAt),
+		Closed:    issue.GetState() == "closed",
+		Labels:    labels,
+		Milestone: toMilestone(issue.Milestone),
+	}
+}
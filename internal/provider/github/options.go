@@ -34,6 +34,14 @@ func (p *ProjectOptionsBuilder) basicOpts(visibility, name, description, default
 	p.opts.Description = &description
 }
 
+func (p *ProjectOptionsBuilder) enableWiki() {
+	p.opts.HasWiki = github.Bool(true)
+}
+
+func (p *ProjectOptionsBuilder) enableIssues() {
+	p.opts.HasIssues = github.Bool(true)
+}
+
 func (p *ProjectOptionsBuilder) disableFeatures() {
 	p.opts.HasIssues = github.Bool(false)
 	p.opts.HasWiki = github.Bool(false)
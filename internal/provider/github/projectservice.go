@@ -36,6 +36,14 @@ func (p ProjectService) createProject(ctx context.Context, cfg config.ProviderCo
 		p.optBuilder.disableFeatures()
 	}
 
+	if opt.Wiki {
+		p.optBuilder.enableWiki()
+	}
+
+	if opt.Issues {
+		p.optBuilder.enableIssues()
+	}
+
 	groupName := ""
 	if cfg.IsGroup() {
 		groupName = cfg.Group
@@ -75,6 +83,8 @@ func (p ProjectService) newProjectInfo(ctx context.Context, cfg config.ProviderC
 		LastActivityAt: getTimeOrNil(gitHubProject.UpdatedAt),
 		Visibility:     getValueOrEmpty(gitHubProject.Visibility),
 		ProjectID:      getValueOrEmpty(gitHubProject.FullName),
+		Archived:       gitHubProject.GetArchived(),
+		HasWiki:        gitHubProject.GetHasWiki(),
 	}, nil
 }
 
@@ -172,6 +182,60 @@ func (p ProjectService) setDefaultBranch(ctx context.Context, owner string, proj
 	return nil
 }
 
+func (p ProjectService) archiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:archiveProject")
+
+	_, _, err := p.client.Repositories.Edit(ctx, owner, projectName, &github.Repository{
+		Archived: github.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) enableWiki(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:enableWiki")
+
+	_, _, err := p.client.Repositories.Edit(ctx, owner, projectName, &github.Repository{
+		HasWiki: github.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable wiki. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) renameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:renameProject")
+
+	_, _, err := p.client.Repositories.Edit(ctx, owner, projectName, &github.Repository{
+		Name: github.String(newName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename project. name: %s, newName: %s, err: %w", projectName, newName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) deleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:deleteProject")
+
+	_, err := p.client.Repositories.Delete(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
 // getValueOrEmpty is a helper function that returns the value of a string pointer if it's not nil,
 // or an empty string otherwise.
 func getValueOrEmpty(s *string) string {
@@ -126,6 +126,40 @@ func (p ProtectionService) unprotect(ctx context.Context, branch, owner, project
 	return nil
 }
 
+// protectedRefs returns the protected branches and the tag protection patterns of the repository as reference patterns.
+func (p ProtectionService) protectedRefs(ctx context.Context, owner, projectName string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:protectedRefs")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitHub:protectedRefs")
+
+	branches, _, err := p.client.Repositories.ListBranches(ctx, owner, projectName, &github.BranchListOptions{
+		Protected:   github.Bool(true),
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected branches. projectName: %s. err: %w", projectName, err)
+	}
+
+	refs := make([]string, 0, len(branches))
+
+	for _, branch := range branches {
+		refs = append(refs, "refs/heads/"+branch.GetName())
+	}
+
+	tagProtections, _, err := p.client.Repositories.ListTagProtection(ctx, owner, projectName) //nolint //lint:ignore SA1019 we will fix
+	if err != nil {
+		if !strings.Contains(err.Error(), "404") {
+			return nil, fmt.Errorf("failed to list tag protections. projectName: %s. err: %w", projectName, err)
+		}
+	}
+
+	for _, tagProtection := range tagProtections {
+		refs = append(refs, "refs/tags/"+tagProtection.GetPattern())
+	}
+
+	return refs, nil
+}
+
 func (p ProtectionService) disableBranchProtection(ctx context.Context, branch, owner, projectName string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitHub:disableBranchProtection")
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	"itiquette/git-provider-sync/internal/provider/assetfile"
+
+	"github.com/google/go-github/v67/github"
+)
+
+type ReleaseService struct {
+	client *github.Client
+	// download follows the redirect of an asset download to its storage, which must not get the token
+	download *http.Client
+}
+
+func NewReleaseService(client *github.Client, download *http.Client) *ReleaseService {
+	return &ReleaseService{client: client, download: download}
+}
+
+func (r ReleaseService) releases(ctx context.Context, owner, projectName string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:releases")
+
+	var releases []model.Release
+
+	opt := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, resp, err := r.client.Repositories.ListReleases(ctx, owner, projectName, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases. page: %d, err: %w", opt.Page, err)
+		}
+
+		for _, release := range page {
+			releases = append(releases, toRelease(release))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return releases, nil
+}
+
+func (r ReleaseService) saveRelease(ctx context.Context, owner, projectName string, release model.Release) (model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:saveRelease")
+
+	repositoryRelease := &github.RepositoryRelease{
+		TagName:    github.String(release.TagName),
+		Name:       github.String(release.Name),
+		Body:       github.String(release.Notes),
+		Draft:      github.Bool(release.Draft),
+		Prerelease: github.Bool(release.Prerelease),
+	}
+
+	if release.ID == "" {
+		created, _, err := r.client.Repositories.CreateRelease(ctx, owner, projectName, repositoryRelease)
+		if err != nil {
+			return model.Release{}, fmt.Errorf("failed to create release. tag: %s, err: %w", release.TagName, err)
+		}
+
+		return toRelease(created), nil
+	}
+
+	releaseID, err := strconv.ParseInt(release.ID, 10, 64)
+	if err != nil {
+		return model.Release{}, fmt.Errorf("invalid release id %s: %w", release.ID, err)
+	}
+
+	edited, _, err := r.client.Repositories.EditRelease(ctx, owner, projectName, releaseID, repositoryRelease)
+	if err != nil {
+		return model.Release{}, fmt.Errorf("failed to edit release. tag: %s, err: %w", release.TagName, err)
+	}
+
+	return toRelease(edited), nil
+}
+
+func (r ReleaseService) downloadAsset(ctx context.Context, owner, projectName string, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:downloadAsset")
+
+	assetID, err := strconv.ParseInt(asset.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid asset id %s: %w", asset.ID, err)
+	}
+
+	content, _, err := r.client.Repositories.DownloadReleaseAsset(ctx, owner, projectName, assetID, r.download)
+	if err != nil {
+		return fmt.Errorf("failed to download asset. name: %s, err: %w", asset.Name, err)
+	}
+	defer content.Close()
+
+	return assetfile.Write(content, path) //nolint:wrapcheck
+}
+
+// uploadAsset uploads the file to the release through the upload URL of the client, set by githubuploadurl for GitHub Enterprise.
+func (r ReleaseService) uploadAsset(ctx context.Context, owner, projectName string, release model.Release, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitHub:uploadAsset")
+
+	releaseID, err := strconv.ParseInt(release.ID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid release id %s: %w", release.ID, err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open asset %s: %w", path, err)
+	}
+	defer file.Close()
+
+	_, _, err = r.client.Repositories.UploadReleaseAsset(ctx, owner, projectName, releaseID, &github.UploadOptions{
+		Name:      asset.Name,
+		MediaType: asset.ContentType,
+	}, file)
+	if err != nil {
+		return fmt.Errorf("failed to upload asset. name: %s, err: %w", asset.Name, err)
+	}
+
+	return nil
+}
+
+func toRelease(release *github.RepositoryRelease) model.Release {
+	assets := make([]model.ReleaseAsset, 0, len(release.Assets))
+
+	for _, asset := range release.Assets {
+		assets = append(assets, model.ReleaseAsset{
+			ID:          strconv.FormatInt(asset.GetID(), 10),
+			Name:        asset.GetName(),
+			ContentType: asset.GetContentType(),
+			Size:        int64(asset.GetSize()),
+			URL:         asset.GetBrowserDownloadURL(),
+		})
+	}
+
+	return model.Release{
+		ID:         strconv.FormatInt(release.GetID(), 10),
+		TagName:    release.GetTagName(),
+		Name:       release.GetName(),
+		Notes:      release.GetBody(),
+		Draft:      release.GetDraft(),
+		Prerelease: release.GetPrerelease(),
+		CreatedAt:  getTimeOrNil(release.CreatedAt),
+		Assets:     assets,
+	}
+}
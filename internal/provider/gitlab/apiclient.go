@@ -22,9 +22,64 @@ type APIClient struct {
 	raw               *gitlab.Client
 	projectService    interfaces.ProjectServicer
 	protectionService interfaces.ProtectionServicer
+	releaseService    *ReleaseService
+	issueService      *IssueService
+	mirrorService     *MirrorService
 	filterService     interfaces.FilterServicer
 }
 
+func (api APIClient) ArchiveProject(ctx context.Context, owner, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:ArchiveProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitLab:ArchiveProject")
+
+	err := api.projectService.ArchiveProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive GitLab project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateIssue(ctx context.Context, owner, projectName string, issue model.Issue) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:CreateIssue")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Int("number", issue.Number).Msg("GitLab:CreateIssue")
+
+	err := api.issueService.CreateIssue(ctx, owner, projectName, issue)
+	if err != nil {
+		return fmt.Errorf("failed to create GitLab issue: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) CreateLabel(ctx context.Context, owner, projectName string, label model.Label) (model.Label, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:CreateLabel")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("label", label.Name).Msg("GitLab:CreateLabel")
+
+	created, err := api.issueService.CreateLabel(ctx, owner, projectName, label)
+	if err != nil {
+		return model.Label{}, fmt.Errorf("failed to create GitLab label: %w", err)
+	}
+
+	return created, nil
+}
+
+func (api APIClient) CreateMilestone(ctx context.Context, owner, projectName string, milestone model.Milestone) (model.Milestone, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:CreateMilestone")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("milestone", milestone.Title).Msg("GitLab:CreateMilestone")
+
+	created, err := api.issueService.CreateMilestone(ctx, owner, projectName, milestone)
+	if err != nil {
+		return model.Milestone{}, fmt.Errorf("failed to create GitLab milestone: %w", err)
+	}
+
+	return created, nil
+}
+
 func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:CreateProject")
@@ -38,6 +93,32 @@ func (api APIClient) CreateProject(ctx context.Context, cfg config.ProviderConfi
 	return projectIDStr, nil
 }
 
+func (api APIClient) DeleteProject(ctx context.Context, owner, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:DeleteProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitLab:DeleteProject")
+
+	err := api.projectService.DeleteProject(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to delete GitLab project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) DownloadReleaseAsset(ctx context.Context, owner, projectName string, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:DownloadReleaseAsset")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("asset", asset.Name).Msg("GitLab:DownloadReleaseAsset")
+
+	err := api.releaseService.DownloadAsset(ctx, asset, path)
+	if err != nil {
+		return fmt.Errorf("failed to download GitLab release asset: %w", err)
+	}
+
+	return nil
+}
+
 func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:IsValidProjectName")
@@ -53,10 +134,40 @@ func (api APIClient) IsValidProjectName(ctx context.Context, name string) bool {
 	return true
 }
 
+func (api APIClient) MirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:MirrorProject")
+	opt.DebugLog(logger).Str("projectID", projectID).Msg("GitLab:MirrorProject")
+
+	if err := api.projectService.MirrorProject(ctx, cfg, opt, projectID); err != nil {
+		return fmt.Errorf("failed to mirror to a GitLab project. err: %w", err)
+	}
+
+	return nil
+}
+
 func (APIClient) Name() string {
 	return config.GITLAB
 }
 
+func (api APIClient) Metadata(ctx context.Context, owner, projectName string, comments bool) (model.Metadata, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:Metadata")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Bool("comments", comments).Msg("GitLab:Metadata")
+
+	metadata, err := api.issueService.Metadata(ctx, owner, projectName, comments)
+	if err != nil {
+		return model.Metadata{}, fmt.Errorf("failed to get GitLab metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	return api.filterService.FilterProjectinfos(ctx, cfg, projectinfos, targetfilter.FilterIncludedExcludedGen(), targetfilter.IsInInterval) //nolint
+}
+
 func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:ProjectInfos")
@@ -68,7 +179,7 @@ func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig
 	}
 
 	if filtering {
-		return api.filterService.FilterProjectinfos(ctx, cfg, projectInfos, targetfilter.FilterIncludedExcludedGen(), targetfilter.IsInInterval) //nolint
+		return api.FilterProjectInfos(ctx, cfg, projectInfos)
 	}
 
 	return projectInfos, nil
@@ -87,6 +198,84 @@ func (api APIClient) ProtectProject(ctx context.Context, _ string, defaultBranch
 	return nil
 }
 
+func (api APIClient) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("GitLab:ProtectedRefs")
+
+	refs, err := api.protectionService.ProtectedRefs(ctx, projectIDStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected refs. projectIDStr: %s, err: %w", projectIDStr, err)
+	}
+
+	return refs, nil
+}
+
+func (api APIClient) PushMirror(ctx context.Context, owner, projectName string, opt model.PushMirrorOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:PushMirror")
+	opt.DebugLog(logger).Str("owner", owner).Str("projectName", projectName).Msg("GitLab:PushMirror")
+
+	if err := api.mirrorService.pushMirror(ctx, owner, projectName, opt); err != nil {
+		return fmt.Errorf("failed to push mirror GitLab project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) Releases(ctx context.Context, owner, projectName string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:Releases")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitLab:Releases")
+
+	releases, err := api.releaseService.Releases(ctx, owner, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get GitLab releases: %w", err)
+	}
+
+	return releases, nil
+}
+
+// EnableWiki turns on the wiki of a project, which was created without one or had it turned off.
+func (api APIClient) EnableWiki(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:EnableWiki")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Msg("GitLab:EnableWiki")
+
+	err := api.projectService.EnableWiki(ctx, owner, projectName)
+	if err != nil {
+		return fmt.Errorf("failed to enable GitLab wiki: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) RenameProject(ctx context.Context, owner, projectName, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:RenameProject")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("newName", newName).Msg("GitLab:RenameProject")
+
+	err := api.projectService.RenameProject(ctx, owner, projectName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename GitLab project: %w", err)
+	}
+
+	return nil
+}
+
+func (api APIClient) SaveRelease(ctx context.Context, owner, projectName string, release model.Release) (model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:SaveRelease")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("tag", release.TagName).Msg("GitLab:SaveRelease")
+
+	saved, err := api.releaseService.SaveRelease(ctx, owner, projectName, release)
+	if err != nil {
+		return model.Release{}, fmt.Errorf("failed to save GitLab release: %w", err)
+	}
+
+	return saved, nil
+}
+
 func (api APIClient) SetDefaultBranch(ctx context.Context, owner, projectName, branch string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:SetDefaultBranch")
@@ -100,6 +289,11 @@ func (api APIClient) SetDefaultBranch(ctx context.Context, owner, projectName, b
 	return nil
 }
 
+// SupportsPushMirror is true, GitLab projects push to their remote mirrors.
+func (APIClient) SupportsPushMirror() bool {
+	return true
+}
+
 func (api APIClient) UnprotectProject(ctx context.Context, defaultBranch string, projectIDStr string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:UnprotectProject")
@@ -113,6 +307,19 @@ func (api APIClient) UnprotectProject(ctx context.Context, defaultBranch string,
 	return nil
 }
 
+func (api APIClient) UploadReleaseAsset(ctx context.Context, owner, projectName string, release model.Release, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:UploadReleaseAsset")
+	logger.Debug().Str("owner", owner).Str("projectName", projectName).Str("tag", release.TagName).Str("asset", asset.Name).Msg("GitLab:UploadReleaseAsset")
+
+	err := api.releaseService.UploadAsset(ctx, owner, projectName, release, asset, path)
+	if err != nil {
+		return fmt.Errorf("failed to upload GitLab release asset: %w", err)
+	}
+
+	return nil
+}
+
 func NewGitLabAPIClient(ctx context.Context, opt model.GitProviderClientOption, httpClient *http.Client) (APIClient, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:NewGitLabClient")
@@ -134,6 +341,9 @@ func NewGitLabAPIClient(ctx context.Context, opt model.GitProviderClientOption,
 		raw:               rawClient,
 		projectService:    NewProjectService(rawClient),
 		protectionService: NewProtectionService(rawClient),
+		releaseService:    NewReleaseService(rawClient, httpClient, opt.HTTPClient.Token),
+		issueService:      NewIssueService(rawClient),
+		mirrorService:     NewMirrorService(rawClient),
 		filterService:     NewFilter(),
 	}, nil
 }
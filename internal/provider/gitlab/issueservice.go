@@ -0,0 +1,384 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// IssueService handles labels, milestones, issues, and merge requests, with their notes as comments.
+// System notes, like a changed label, are left out.
+type IssueService struct {
+	client *gitlab.Client
+}
+
+func NewIssueService(client *gitlab.Client) *IssueService {
+	return &IssueService{client: client}
+}
+
+func (i IssueService) Metadata(ctx context.Context, owner string, projectName string, comments bool) (model.Metadata, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:metadata")
+
+	pid := owner + "/" + projectName
+
+	var metadata model.Metadata
+
+	labelOpt := &gitlab.ListLabelsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+
+	for {
+		labels, resp, err := i.client.Labels.ListLabels(pid, labelOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list labels. page: %d, err: %w", labelOpt.Page, err)
+		}
+
+		for _, label := range labels {
+			metadata.Labels = append(metadata.Labels, toLabel(label))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		labelOpt.Page = resp.NextPage
+	}
+
+	milestoneOpt := &gitlab.ListMilestonesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+
+	for {
+		milestones, resp, err := i.client.Milestones.ListMilestones(pid, milestoneOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list milestones. page: %d, err: %w", milestoneOpt.Page, err)
+		}
+
+		for _, milestone := range milestones {
+			metadata.Milestones = append(metadata.Milestones, *toMilestone(milestone))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		milestoneOpt.Page = resp.NextPage
+	}
+
+	issueOpt := &gitlab.ListProjectIssuesOptions{
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("asc"),
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}
+
+	for {
+		issues, resp, err := i.client.Issues.ListProjectIssues(pid, issueOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list issues. page: %d, err: %w", issueOpt.Page, err)
+		}
+
+		for _, issue := range issues {
+			converted := toIssue(issue)
+
+			if comments && issue.UserNotesCount > 0 {
+				converted.Comments, err = i.notes(func(opt gitlab.ListOptions) ([]*gitlab.Note, *gitlab.Response, error) {
+					return i.client.Notes.ListIssueNotes(pid, issue.IID, &gitlab.ListIssueNotesOptions{ListOptions: opt, OrderBy: gitlab.Ptr("created_at"), Sort: gitlab.Ptr("asc")})
+				})
+				if err != nil {
+					return model.Metadata{}, fmt.Errorf("failed to list notes of issue %d: %w", issue.IID, err)
+				}
+			}
+
+			metadata.Issues = append(metadata.Issues, converted)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		issueOpt.Page = resp.NextPage
+	}
+
+	mergeRequestOpt := &gitlab.ListProjectMergeRequestsOptions{
+		OrderBy:     gitlab.Ptr("created_at"),
+		Sort:        gitlab.Ptr("asc"),
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+	}
+
+	for {
+		mergeRequests, resp, err := i.client.MergeRequests.ListProjectMergeRequests(pid, mergeRequestOpt)
+		if err != nil {
+			return model.Metadata{}, fmt.Errorf("failed to list merge requests. page: %d, err: %w", mergeRequestOpt.Page, err)
+		}
+
+		for _, mergeRequest := range mergeRequests {
+			converted := toMergeRequest(mergeRequest)
+
+			if comments && mergeRequest.UserNotesCount > 0 {
+				converted.Comments, err = i.notes(func(opt gitlab.ListOptions) ([]*gitlab.Note, *gitlab.Response, error) {
+					return i.client.Notes.ListMergeRequestNotes(pid, mergeRequest.IID, &gitlab.ListMergeRequestNotesOptions{ListOptions: opt, OrderBy: gitlab.Ptr("created_at"), Sort: gitlab.Ptr("asc")})
+				})
+				if err != nil {
+					return model.Metadata{}, fmt.Errorf("failed to list notes of merge request %d: %w", mergeRequest.IID, err)
+				}
+			}
+
+			metadata.Issues = append(metadata.Issues, converted)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		mergeRequestOpt.Page = resp.NextPage
+	}
+
+	return metadata, nil
+}
+
+// notes returns the notes written by users, of all pages the list function returns.
+func (i IssueService) notes(list func(opt gitlab.ListOptions) ([]*gitlab.Note, *gitlab.Response, error)) ([]model.IssueComment, error) {
+	var comments []model.IssueComment
+
+	opt := gitlab.ListOptions{PerPage: 100}
+
+	for {
+		notes, resp, err := list(opt)
+		if err != nil {
+			return nil, fmt.Errorf("page: %d, err: %w", opt.Page, err)
+		}
+
+		for _, note := range notes {
+			if note.System {
+				continue
+			}
+
+			comments = append(comments, model.IssueComment{
+				Author:    note.Author.Username,
+				Body:      note.Body,
+				CreatedAt: note.CreatedAt,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return comments, nil
+}
+
+func (i IssueService) CreateLabel(ctx context.Context, owner string, projectName string, label model.Label) (model.Label, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:createLabel")
+
+	created, _, err := i.client.Labels.CreateLabel(owner+"/"+projectName, &gitlab.CreateLabelOptions{
+		Name:        gitlab.Ptr(label.Name),
+		Color:       gitlab.Ptr("#" + label.Color),
+		Description: gitlab.Ptr(label.Description),
+	})
+	if err != nil {
+		return model.Label{}, fmt.Errorf("failed to create label. name: %s, err: %w", label.Name, err)
+	}
+
+	return toLabel(created), nil
+}
+
+// CreateMilestone creates a milestone. GitLab creates milestones active, a closed one is closed after.
+func (i IssueService) CreateMilestone(ctx context.Context, owner string, projectName string, milestone model.Milestone) (model.Milestone, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:createMilestone")
+
+	pid := owner + "/" + projectName
+
+	opt := &gitlab.CreateMilestoneOptions{
+		Title:       gitlab.Ptr(milestone.Title),
+		Description: gitlab.Ptr(milestone.Description),
+	}
+
+	if milestone.DueOn != nil {
+		opt.DueDate = gitlab.Ptr(gitlab.ISOTime(*milestone.DueOn))
+	}
+
+	created, _, err := i.client.Milestones.CreateMilestone(pid, opt)
+	if err != nil {
+		return model.Milestone{}, fmt.Errorf("failed to create milestone. title: %s, err: %w", milestone.Title, err)
+	}
+
+	if milestone.Closed {
+		created, _, err = i.client.Milestones.UpdateMilestone(pid, created.ID, &gitlab.UpdateMilestoneOptions{StateEvent: gitlab.Ptr("close")})
+		if err != nil {
+			return model.Milestone{}, fmt.Errorf("failed to close milestone. title: %s, err: %w", milestone.Title, err)
+		}
+	}
+
+	return *toMilestone(created), nil
+}
+
+// CreateIssue creates the issue with its comments as notes, and closes it when it is closed.
+// It is created marked as incomplete until then, and deleted again when it can't be completed.
+func (i IssueService) CreateIssue(ctx context.Context, owner string, projectName string, issue model.Issue) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:createIssue")
+
+	pid := owner + "/" + projectName
+
+	labels := make(gitlab.LabelOptions, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	opt := &gitlab.CreateIssueOptions{
+		Title:       gitlab.Ptr(issue.Title),
+		Description: gitlab.Ptr(model.IncompleteImportHeader + issue.Body),
+		Labels:      &labels,
+	}
+
+	if issue.Milestone != nil {
+		milestoneID, err := strconv.Atoi(issue.Milestone.ID)
+		if err != nil {
+			return fmt.Errorf("invalid milestone id %s: %w", issue.Milestone.ID, err)
+		}
+
+		opt.MilestoneID = &milestoneID
+	}
+
+	created, _, err := i.client.Issues.CreateIssue(pid, opt)
+	if err != nil {
+		return fmt.Errorf("failed to create issue. title: %s, err: %w", issue.Title, err)
+	}
+
+	if err := i.completeIssue(pid, created.IID, issue); err != nil {
+		return errors.Join(err, i.abandonIssue(pid, created.IID))
+	}
+
+	return nil
+}
+
+// completeIssue adds the comments of an issue being imported as notes, closes it when it is closed,
+// and removes the incomplete import header.
+func (i IssueService) completeIssue(pid string, iid int, issue model.Issue) error {
+	for _, comment := range issue.Comments {
+		_, _, err := i.client.Notes.CreateIssueNote(pid, iid, &gitlab.CreateIssueNoteOptions{Body: gitlab.Ptr(comment.Body)})
+		if err != nil {
+			return fmt.Errorf("failed to create note of issue %d: %w", iid, err)
+		}
+	}
+
+	opt := &gitlab.UpdateIssueOptions{Description: gitlab.Ptr(issue.Body)}
+	if issue.Closed {
+		opt.StateEvent = gitlab.Ptr("close")
+	}
+
+	if _, _, err := i.client.Issues.UpdateIssue(pid, iid, opt); err != nil {
+		return fmt.Errorf("failed to complete issue %d: %w", iid, err)
+	}
+
+	return nil
+}
+
+// abandonIssue deletes an issue that couldn't be completed, which needs the owner role.
+// Without it the issue is closed instead, still marked as incomplete.
+func (i IssueService) abandonIssue(pid string, iid int) error {
+	if _, err := i.client.Issues.DeleteIssue(pid, iid); err == nil {
+		return nil
+	}
+
+	if _, _, err := i.client.Issues.UpdateIssue(pid, iid, &gitlab.UpdateIssueOptions{StateEvent: gitlab.Ptr("close")}); err != nil {
+		return fmt.Errorf("failed to delete or close incomplete issue %d: %w", iid, err)
+	}
+
+	return nil
+}
+
+func toLabel(label *gitlab.Label) model.Label {
+	return model.Label{
+		ID:          strconv.Itoa(label.ID),
+		Name:        label.Name,
+		Color:       strings.TrimPrefix(label.Color, "#"),
+		Description: label.Description,
+	}
+}
+
+func toMilestone(milestone *gitlab.Milestone) *model.Milestone {
+	if milestone == nil {
+		return nil
+	}
+
+	var dueOn *time.Time
+
+	if milestone.DueDate != nil {
+		due := time.Time(*milestone.DueDate)
+		dueOn = &due
+	}
+
+	return &model.Milestone{
+		ID:          strconv.Itoa(milestone.ID),
+		Title:       milestone.Title,
+		Description: milestone.Description,
+		Closed:      milestone.State == "closed",
+		DueOn:       dueOn,
+	}
+}
+
+// toLabels turns the label names GitLab lists issues with into labels.
+func toLabels(names gitlab.Labels) []model.Label {
+	labels := make([]model.Label, 0, len(names))
+	for _, name := range names {
+		labels = append(labels, model.Label{Name: name})
+	}
+
+	return labels
+}
+
+func toIssue(issue *gitlab.Issue) model.Issue {
+	var author string
+	if issue.Author != nil {
+		author = issue.Author.Username
+	}
+
+	return model.Issue{
+		Number:    issue.IID,
+		Title:     issue.Title,
+		Body:      issue.Description,
+		Author:    author,
+		CreatedAt: issue.CreatedAt,
+		Closed:    issue.State == "closed",
+		Labels:    toLabels(issue.Labels),
+		Milestone: toMilestone(issue.Milestone),
+	}
+}
+
+func toMergeRequest(mergeRequest *gitlab.MergeRequest) model.Issue {
+	var author string
+	if mergeRequest.Author != nil {
+		author = mergeRequest.Author.Username
+	}
+
+	return model.Issue{
+		Number:    mergeRequest.IID,
+		Title:     mergeRequest.Title,
+		Body:      mergeRequest.Description,
+		Author:    author,
+		CreatedAt: mergeRequest.CreatedAt,
+		Closed:    mergeRequest.State != "opened",
+		Labels:    toLabels(mergeRequest.Labels),
+		Milestone: toMilestone(mergeRequest.Milestone),
+		MergeRequest: &model.MergeRequest{
+			SourceBranch: mergeRequest.SourceBranch,
+			TargetBranch: mergeRequest.TargetBranch,
+			Merged:       mergeRequest.State == "merged",
+		},
+	}
+}
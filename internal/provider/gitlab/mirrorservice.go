@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// MirrorService handles the remote mirrors a project pushes itself to.
+type MirrorService struct {
+	client *gitlab.Client
+}
+
+func NewMirrorService(client *gitlab.Client) *MirrorService {
+	return &MirrorService{client: client}
+}
+
+// pushMirror adds a remote mirror of the project pushing to the target repository, which GitLab pushes to right away
+// and after every push to the project. GitLab can't change the url or credentials of a remote mirror, and masks them
+// when listed, so a remote mirror already pushing to the target repository is replaced by one with the current ones.
+func (m MirrorService) pushMirror(ctx context.Context, owner, projectName string, opt model.PushMirrorOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:pushMirror")
+
+	pid := owner + "/" + projectName
+
+	mirrors, _, err := m.client.ProjectMirrors.ListProjectMirror(pid, &gitlab.ListProjectMirrorOptions{PerPage: 100})
+	if err != nil {
+		return fmt.Errorf("failed to list remote mirrors. name: %s, err: %w", projectName, err)
+	}
+
+	for _, mirror := range mirrors {
+		// The credentials of a listed mirror are masked, the url is compared without them
+		if !opt.Targets(mirror.URL) {
+			continue
+		}
+
+		if _, err := m.client.ProjectMirrors.DeleteProjectMirror(pid, mirror.ID); err != nil {
+			return fmt.Errorf("failed to remove remote mirror. name: %s, err: %w", projectName, err)
+		}
+
+		logger.Debug().Str("name", projectName).Msg("Remote mirror removed, to be added with the current url and token")
+	}
+
+	_, _, err = m.client.ProjectMirrors.AddProjectMirror(pid, &gitlab.AddProjectMirrorOptions{
+		URL:     gitlab.Ptr(credentialURL(opt.URL, opt.Token)),
+		Enabled: gitlab.Ptr(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add remote mirror. name: %s, err: %w", projectName, err)
+	}
+
+	logger.Debug().Str("name", projectName).Msg("Remote mirror added")
+
+	return nil
+}
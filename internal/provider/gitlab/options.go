@@ -30,6 +30,27 @@ func (builder *ProjectOptionsBuilder) WithBasicOpts(visibility, name, descriptio
 	}
 }
 
+func (builder *ProjectOptionsBuilder) WithWiki() {
+	builder.opts.WikiAccessLevel = gitlab.Ptr(gitlab.EnabledAccessControl)
+}
+
+// WithReleases keeps releases, and the package registry holding their assets, enabled.
+func (builder *ProjectOptionsBuilder) WithReleases() {
+	builder.opts.ReleasesAccessLevel = gitlab.Ptr(gitlab.EnabledAccessControl)
+	builder.opts.PackagesEnabled = gitlab.Ptr(true)
+}
+
+// WithIssues keeps issues, and the merge requests imported as issues, enabled.
+func (builder *ProjectOptionsBuilder) WithIssues() {
+	builder.opts.IssuesAccessLevel = gitlab.Ptr(gitlab.EnabledAccessControl)
+}
+
+// WithMirror has the project pull mirror the repository at the url, which carries the credentials.
+func (builder *ProjectOptionsBuilder) WithMirror(url string) {
+	builder.opts.ImportURL = gitlab.Ptr(url)
+	builder.opts.Mirror = gitlab.Ptr(true)
+}
+
 func (builder *ProjectOptionsBuilder) WithDisabledFeatures() {
 	builder.opts.BuildsAccessLevel = gitlab.Ptr(gitlab.DisabledAccessControl)
 	builder.opts.AutoDevopsEnabled = gitlab.Ptr(false)
@@ -8,11 +8,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"itiquette/git-provider-sync/internal/httpclient"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
@@ -38,12 +41,37 @@ func (p ProjectService) CreateProject(ctx context.Context, cfg config.ProviderCo
 		return "", fmt.Errorf("failed to get namespaceID. err: %w", err)
 	}
 
-	p.optBuilder.WithBasicOpts(opt.Visibility, opt.RepositoryName, opt.Description, opt.DefaultBranch, namespaceID)
+	// A repository in a subgroup of the source is named by its path, the subgroups are recreated in the group
+	subgroupPath, name := path.Split(opt.RepositoryName)
+	if subgroupPath != "" && namespaceID != 0 {
+		namespaceID, err = p.subgroupID(ctx, cfg, strings.TrimSuffix(subgroupPath, "/"))
+		if err != nil {
+			return "", fmt.Errorf("failed to get subgroup namespaceID. err: %w", err)
+		}
+	}
+
+	p.optBuilder.WithBasicOpts(opt.Visibility, name, opt.Description, opt.DefaultBranch, namespaceID)
 
 	if opt.Disabled {
 		p.optBuilder.WithDisabledFeatures()
 	}
 
+	if opt.Wiki {
+		p.optBuilder.WithWiki()
+	}
+
+	if opt.Releases {
+		p.optBuilder.WithReleases()
+	}
+
+	if opt.Issues {
+		p.optBuilder.WithIssues()
+	}
+
+	if opt.MirrorURL != "" {
+		p.optBuilder.WithMirror(credentialURL(opt.MirrorURL, opt.MirrorToken))
+	}
+
 	createdRepo, _, err := p.client.Projects.CreateProject(p.optBuilder.opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to create project. name: %s, err: %w", opt.RepositoryName, err)
@@ -54,6 +82,50 @@ func (p ProjectService) CreateProject(ctx context.Context, cfg config.ProviderCo
 	return strconv.Itoa(createdRepo.ID), nil
 }
 
+// MirrorProject creates a project pull mirroring the source repository, or has an existing project pull mirror it,
+// and starts an update of the mirror. Pull mirroring needs GitLab Premium.
+func (p ProjectService) MirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:mirrorProject")
+
+	if projectID == "" {
+		createdID, err := p.CreateProject(ctx, cfg, opt)
+		if err != nil {
+			return err
+		}
+
+		projectID = createdID
+	} else {
+		_, _, err := p.client.Projects.EditProject(projectID, &gitlab.EditProjectOptions{
+			ImportURL: gitlab.Ptr(credentialURL(opt.MirrorURL, opt.MirrorToken)),
+			Mirror:    gitlab.Ptr(true),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to set pull mirror. name: %s, err: %w", opt.RepositoryName, err)
+		}
+	}
+
+	if _, err := p.client.Projects.StartMirroringProject(projectID); err != nil {
+		return fmt.Errorf("failed to start mirror update. name: %s, err: %w", opt.RepositoryName, err)
+	}
+
+	logger.Debug().Str("name", opt.RepositoryName).Msg("Mirror update started")
+
+	return nil
+}
+
+// credentialURL returns the url of a mirrored repository with the credentials of the mirror, GitLab takes no others.
+func credentialURL(repositoryURL, token string) string {
+	parsedURL, err := url.Parse(repositoryURL)
+	if err != nil || token == "" {
+		return repositoryURL
+	}
+
+	parsedURL.User = url.UserPassword(httpclient.GitUsername(repositoryURL), token)
+
+	return parsedURL.String()
+}
+
 func (p ProjectService) getNamespaceID(ctx context.Context, cfg config.ProviderConfig) (int, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:getNamespaceID")
@@ -62,22 +134,83 @@ func (p ProjectService) getNamespaceID(ctx context.Context, cfg config.ProviderC
 		return 0, nil
 	}
 
-	groups, resp, err := p.client.Groups.ListGroups(&gitlab.ListGroupsOptions{
-		Search: gitlab.Ptr(cfg.Group),
-	})
+	// The group is looked up by its full path, a search by name also matches other groups
+	group, resp, err := p.getGroup(cfg.Group)
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
 			return 0, errors.New("authentication failed: please check your token permissions")
 		}
 
-		return 0, fmt.Errorf("failed to list groups. err: %w", err)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return 0, fmt.Errorf("failed to find group. group: %s", cfg.Group)
+		}
+
+		return 0, fmt.Errorf("failed to get group. err: %w", err)
+	}
+
+	return group.ID, nil
+}
+
+// getGroup returns the group at the path, without its projects.
+func (p ProjectService) getGroup(groupPath string) (*gitlab.Group, *gitlab.Response, error) {
+	return p.client.Groups.GetGroup(groupPath, &gitlab.GetGroupOptions{WithProjects: gitlab.Ptr(false)}) //nolint:wrapcheck
+}
+
+// subgroupID returns the id of the subgroup at the path in the group, like team/backend. The subgroups that are
+// missing on the way are created, with the visibility of the group they are created in.
+func (p ProjectService) subgroupID(ctx context.Context, cfg config.ProviderConfig, subgroupPath string) (int, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:subgroupID")
+
+	parent, _, err := p.getGroup(cfg.Group)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get group. group: %s, err: %w", cfg.Group, err)
+	}
+
+	for _, segment := range strings.Split(subgroupPath, "/") {
+		fullPath := parent.FullPath + "/" + segment
+
+		group, resp, err := p.getGroup(fullPath)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return 0, fmt.Errorf("failed to get subgroup. path: %s, err: %w", fullPath, err)
+		}
+
+		if err != nil {
+			group, err = p.createSubgroup(ctx, parent, segment)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		parent = group
 	}
 
-	if len(groups) == 0 {
-		return 0, fmt.Errorf("failed to find group name. group: %s", cfg.Group)
+	return parent.ID, nil
+}
+
+func (p ProjectService) createSubgroup(ctx context.Context, parent *gitlab.Group, segment string) (*gitlab.Group, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:createSubgroup")
+
+	group, _, err := p.client.Groups.CreateGroup(&gitlab.CreateGroupOptions{
+		Name:       gitlab.Ptr(segment),
+		Path:       gitlab.Ptr(segment),
+		ParentID:   gitlab.Ptr(parent.ID),
+		Visibility: gitlab.Ptr(parent.Visibility),
+	})
+	if err == nil {
+		logger.Debug().Str("path", group.FullPath).Msg("Subgroup created")
+
+		return group, nil
+	}
+
+	// Repositories of the same subgroup are created concurrently, another one may have created it in the meantime
+	group, _, getErr := p.getGroup(parent.FullPath + "/" + segment)
+	if getErr != nil {
+		return nil, fmt.Errorf("failed to create subgroup. path: %s/%s, err: %w", parent.FullPath, segment, err)
 	}
 
-	return groups[0].ID, nil
+	return group, nil
 }
 
 func (p ProjectService) newProjectInfo(ctx context.Context, cfg config.ProviderConfig, name string) (model.ProjectInfo, error) {
@@ -112,6 +245,8 @@ func (p ProjectService) newProjectInfo(ctx context.Context, cfg config.ProviderC
 		ProjectID:      strconv.Itoa(gitlabProject.ID),
 		SSHURL:         gitlabProject.SSHURLToRepo,
 		Visibility:     getVisibility(gitlabProject.Visibility),
+		Archived:       gitlabProject.Archived,
+		HasWiki:        gitlabProject.WikiAccessLevel != gitlab.DisabledAccessControl && (gitlabProject.WikiAccessLevel != "" || gitlabProject.WikiEnabled),
 	}, nil
 }
 
@@ -123,9 +258,10 @@ func (p ProjectService) GetProjectInfos(ctx context.Context, cfg config.Provider
 
 	if cfg.IsGroup() {
 		opt := &gitlab.ListGroupProjectsOptions{
-			OrderBy:     gitlab.Ptr("name"),
-			Sort:        gitlab.Ptr("asc"),
-			ListOptions: gitlab.ListOptions{PerPage: 100}, //TODO: add archived support,
+			OrderBy:          gitlab.Ptr("name"),
+			Sort:             gitlab.Ptr("asc"),
+			IncludeSubGroups: gitlab.Ptr(cfg.Repositories.IncludeSubgroups),
+			ListOptions:      gitlab.ListOptions{PerPage: 100}, //TODO: add archived support,
 		}
 
 		for {
@@ -175,9 +311,14 @@ func (p ProjectService) GetProjectInfos(ctx context.Context, cfg config.Provider
 			continue
 		}
 
-		projectInfo, err := p.newProjectInfo(ctx, cfg, repo.Path)
+		name, ok := projectName(cfg, repo)
+		if !ok {
+			continue
+		}
+
+		projectInfo, err := p.newProjectInfo(ctx, cfg, name)
 		if err != nil {
-			return nil, fmt.Errorf("failed to init projectInfo. path: %s, err: %w", repo.Path, err)
+			return nil, fmt.Errorf("failed to init projectInfo. path: %s, err: %w", name, err)
 		}
 
 		projectinfos = append(projectinfos, projectInfo)
@@ -200,6 +341,76 @@ func (p ProjectService) SetDefaultBranch(ctx context.Context, owner string, proj
 	return nil
 }
 
+func (p ProjectService) ArchiveProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:archiveProject")
+
+	_, _, err := p.client.Projects.ArchiveProject(owner + "/" + projectName)
+	if err != nil {
+		return fmt.Errorf("failed to archive project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+// EnableWiki turns on the wiki of a project.
+func (p ProjectService) EnableWiki(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:enableWiki")
+
+	_, _, err := p.client.Projects.EditProject(owner+"/"+projectName, &gitlab.EditProjectOptions{
+		WikiAccessLevel: gitlab.Ptr(gitlab.EnabledAccessControl),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable wiki. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+// RenameProject changes both the name and the path of a project, as the path is what the repository URL is made of.
+func (p ProjectService) RenameProject(ctx context.Context, owner string, projectName string, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:renameProject")
+
+	_, _, err := p.client.Projects.EditProject(owner+"/"+projectName, &gitlab.EditProjectOptions{
+		Name: gitlab.Ptr(newName),
+		Path: gitlab.Ptr(newName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename project. name: %s, newName: %s, err: %w", projectName, newName, err)
+	}
+
+	return nil
+}
+
+func (p ProjectService) DeleteProject(ctx context.Context, owner string, projectName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:deleteProject")
+
+	_, err := p.client.Projects.DeleteProject(owner+"/"+projectName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete project. name: %s, err: %w", projectName, err)
+	}
+
+	return nil
+}
+
+// projectName returns the name of a listed project, its path in the group when subgroups are included, like team/backend/api.
+// Projects shared with the group from outside of it are left out.
+func projectName(cfg config.ProviderConfig, project *gitlab.Project) (string, bool) {
+	if !cfg.IsGroup() || !cfg.Repositories.IncludeSubgroups {
+		return project.Path, true
+	}
+
+	prefix := cfg.Group + "/"
+	if len(project.PathWithNamespace) <= len(prefix) || !strings.EqualFold(project.PathWithNamespace[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return project.PathWithNamespace[len(prefix):], true
+}
+
 func getProjectPath(cfg config.ProviderConfig, name string) string {
 	if cfg.IsGroup() {
 		return cfg.Group + "/" + name
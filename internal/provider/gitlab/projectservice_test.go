@@ -0,0 +1,59 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xanzy/go-gitlab"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+func TestProjectName(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.ProviderConfig
+		project   *gitlab.Project
+		want      string
+		wantFound bool
+	}{
+		{
+			name:      "group project",
+			cfg:       config.ProviderConfig{Group: "org"},
+			project:   &gitlab.Project{Path: "api", PathWithNamespace: "org/api"},
+			want:      "api",
+			wantFound: true,
+		},
+		{
+			name:      "subgroup project named by its path",
+			cfg:       config.ProviderConfig{Group: "org", Repositories: config.RepositoriesOption{IncludeSubgroups: true}},
+			project:   &gitlab.Project{Path: "api", PathWithNamespace: "Org/team/backend/api"},
+			want:      "team/backend/api",
+			wantFound: true,
+		},
+		{
+			name:    "project shared from outside the group",
+			cfg:     config.ProviderConfig{Group: "org", Repositories: config.RepositoriesOption{IncludeSubgroups: true}},
+			project: &gitlab.Project{Path: "api", PathWithNamespace: "other/api"},
+		},
+		{
+			name:      "user project",
+			cfg:       config.ProviderConfig{User: "me", Repositories: config.RepositoriesOption{IncludeSubgroups: true}},
+			project:   &gitlab.Project{Path: "api", PathWithNamespace: "me/api"},
+			want:      "api",
+			wantFound: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			name, found := projectName(tabletest.cfg, tabletest.project)
+
+			require.Equal(t, tabletest.wantFound, found)
+			require.Equal(t, tabletest.want, name)
+		})
+	}
+}
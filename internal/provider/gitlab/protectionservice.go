@@ -109,6 +109,37 @@ func (p ProtectionService) Unprotect(ctx context.Context, branch string, project
 	return nil
 }
 
+// ProtectedRefs returns the protected branches and tags of the project as reference patterns, wildcards included.
+func (p ProtectionService) ProtectedRefs(ctx context.Context, projectIDStr string) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:ProtectedRefs")
+	logger.Debug().Str("projectIDStr", projectIDStr).Msg("GitLab:ProtectedRefs")
+
+	projectID, _ := strconv.Atoi(projectIDStr)
+
+	branches, _, err := p.client.ProtectedBranches.ListProtectedBranches(projectID, &gitlab.ListProtectedBranchesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected branches. err: %w", err)
+	}
+
+	tags, _, err := p.client.ProtectedTags.ListProtectedTags(projectID, &gitlab.ListProtectedTagsOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protected tags. err: %w", err)
+	}
+
+	refs := make([]string, 0, len(branches)+len(tags))
+
+	for _, branch := range branches {
+		refs = append(refs, "refs/heads/"+branch.Name)
+	}
+
+	for _, tag := range tags {
+		refs = append(refs, "refs/tags/"+tag.Name)
+	}
+
+	return refs, nil
+}
+
 func (p ProtectionService) disableBranchProtection(ctx context.Context, defaultBranch string, projectID int) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitLab:disableBranchProtection")
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	"itiquette/git-provider-sync/internal/provider/assetfile"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// assetPackage is the generic package uploaded release assets are published in, with a version per release.
+const assetPackage = "release-assets"
+
+// packageUnsafe matches what GitLab doesn't allow in generic package versions and file names.
+var packageUnsafe = regexp.MustCompile(`[^0-9A-Za-z._+-]`)
+
+// ReleaseService handles releases. GitLab has no release assets of its own, but links on a release.
+// Assets are uploaded to the generic package registry of the project, and linked from the release.
+type ReleaseService struct {
+	client     *gitlab.Client
+	httpClient *http.Client
+	token      string
+}
+
+func NewReleaseService(client *gitlab.Client, httpClient *http.Client, token string) *ReleaseService {
+	return &ReleaseService{client: client, httpClient: httpClient, token: token}
+}
+
+func (r ReleaseService) Releases(ctx context.Context, owner string, projectName string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:releases")
+
+	var releases []model.Release
+
+	opt := &gitlab.ListReleasesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+
+	for {
+		page, resp, err := r.client.Releases.ListReleases(owner+"/"+projectName, opt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases. page: %d, err: %w", opt.Page, err)
+		}
+
+		for _, release := range page {
+			releases = append(releases, toRelease(release))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return releases, nil
+}
+
+// SaveRelease creates or updates a release. GitLab knows releases by their tag, and has no pre-releases or drafts.
+func (r ReleaseService) SaveRelease(ctx context.Context, owner string, projectName string, release model.Release) (model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:saveRelease")
+
+	if release.ID == "" {
+		created, _, err := r.client.Releases.CreateRelease(owner+"/"+projectName, &gitlab.CreateReleaseOptions{
+			Name:        gitlab.Ptr(release.Name),
+			TagName:     gitlab.Ptr(release.TagName),
+			Description: gitlab.Ptr(release.Notes),
+		})
+		if err != nil {
+			return model.Release{}, fmt.Errorf("failed to create release. tag: %s, err: %w", release.TagName, err)
+		}
+
+		return toRelease(created), nil
+	}
+
+	updated, _, err := r.client.Releases.UpdateRelease(owner+"/"+projectName, release.ID, &gitlab.UpdateReleaseOptions{
+		Name:        gitlab.Ptr(release.Name),
+		Description: gitlab.Ptr(release.Notes),
+	})
+	if err != nil {
+		return model.Release{}, fmt.Errorf("failed to update release. tag: %s, err: %w", release.TagName, err)
+	}
+
+	return toRelease(updated), nil
+}
+
+// DownloadAsset downloads what a release link points to. The token is only sent to the GitLab instance itself.
+func (r ReleaseService) DownloadAsset(ctx context.Context, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:downloadAsset")
+
+	header := http.Header{}
+
+	if assetURL, err := url.Parse(asset.URL); err == nil && assetURL.Host == r.client.BaseURL().Host {
+		header.Set("PRIVATE-TOKEN", r.token)
+	}
+
+	return assetfile.Download(ctx, r.httpClient, asset.URL, header, path) //nolint:wrapcheck
+}
+
+// UploadAsset publishes the file in the generic package of release assets, and links it from the release.
+func (r ReleaseService) UploadAsset(ctx context.Context, owner string, projectName string, release model.Release, asset model.ReleaseAsset, path string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitLab:uploadAsset")
+
+	pid := owner + "/" + projectName
+	version := packageUnsafe.ReplaceAllString(release.TagName, "-")
+	fileName := packageUnsafe.ReplaceAllString(asset.Name, "-")
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open asset %s: %w", path, err)
+	}
+	defer file.Close()
+
+	_, _, err = r.client.GenericPackages.PublishPackageFile(pid, assetPackage, version, fileName, file, nil)
+	if err != nil {
+		return fmt.Errorf("failed to publish asset. name: %s, err: %w", asset.Name, err)
+	}
+
+	packagePath, err := r.client.GenericPackages.FormatPackageURL(pid, assetPackage, version, fileName)
+	if err != nil {
+		return fmt.Errorf("failed to format package url of asset %s: %w", asset.Name, err)
+	}
+
+	_, _, err = r.client.ReleaseLinks.CreateReleaseLink(pid, release.TagName, &gitlab.CreateReleaseLinkOptions{
+		Name:     gitlab.Ptr(asset.Name),
+		URL:      gitlab.Ptr(r.client.BaseURL().String() + packagePath),
+		LinkType: gitlab.LinkType(gitlab.PackageLinkType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to link asset. name: %s, err: %w", asset.Name, err)
+	}
+
+	return nil
+}
+
+func toRelease(release *gitlab.Release) model.Release {
+	assets := make([]model.ReleaseAsset, 0, len(release.Assets.Links))
+
+	for _, link := range release.Assets.Links {
+		assetURL := link.DirectAssetURL
+		if assetURL == "" {
+			assetURL = link.URL
+		}
+
+		assets = append(assets, model.ReleaseAsset{
+			ID:   strconv.Itoa(link.ID),
+			Name: link.Name,
+			URL:  assetURL,
+		})
+	}
+
+	return model.Release{
+		ID:        release.TagName,
+		TagName:   release.TagName,
+		Name:      release.Name,
+		Notes:     release.Description,
+		CreatedAt: release.CreatedAt,
+		Assets:    assets,
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package gitremote provides a source provider for plain git servers without a supported API.
+// Repositories are given as a list of clone urls and inspected over the git protocol only.
+package gitremote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/targetfilter"
+)
+
+var ErrSourceOnly = errors.New("gitremote can only be used as a source provider")
+
+type APIClient struct {
+	remoteService *RemoteService
+}
+
+func (api APIClient) ArchiveProject(_ context.Context, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) CreateProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption) (string, error) {
+	return "", ErrSourceOnly
+}
+
+func (api APIClient) DeleteProject(_ context.Context, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) IsValidProjectName(_ context.Context, _ string) bool {
+	return true
+}
+
+func (api APIClient) Name() string {
+	return config.GITREMOTE
+}
+
+// FilterProjectInfos keeps the listed repositories the configuration syncs.
+// There is no activity time to filter on, only the include/exclude rules apply.
+func (api APIClient) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	filtered, err := targetfilter.FilterIncludedExcludedGen()(ctx, cfg, projectinfos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter repositories by inclusion/exclusion rules: %w", err)
+	}
+
+	return filtered, nil
+}
+
+func (api APIClient) ProjectInfos(ctx context.Context, cfg config.ProviderConfig, filtering bool) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitRemote:ProjectInfos")
+	logger.Debug().Bool("filtering", filtering).Msg("GitRemote:ProjectInfos")
+
+	projectinfos, err := api.remoteService.getProjectInfos(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository infos. err: %w", err)
+	}
+
+	if filtering {
+		return api.FilterProjectInfos(ctx, cfg, projectinfos)
+	}
+
+	return projectinfos, nil
+}
+
+func (api APIClient) ProtectProject(_ context.Context, _ string, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) ProtectedRefs(_ context.Context, _ string) ([]string, error) {
+	return nil, ErrSourceOnly
+}
+
+func (api APIClient) EnableWiki(_ context.Context, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) RenameProject(_ context.Context, _ string, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) SetDefaultBranch(_ context.Context, _ string, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) UnprotectProject(_ context.Context, _ string, _ string) error {
+	return ErrSourceOnly
+}
+
+// Releases are not part of the git protocol, a plain git server has none to list.
+func (api APIClient) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	return nil, interfaces.ErrNotSupported
+}
+
+func (api APIClient) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	return model.Release{}, ErrSourceOnly
+}
+
+func (api APIClient) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	return interfaces.ErrNotSupported
+}
+
+func (api APIClient) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	return ErrSourceOnly
+}
+
+func (api APIClient) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	return ErrSourceOnly
+}
+
+// SupportsPushMirror is false, a plain git server has no push mirrors.
+func (api APIClient) SupportsPushMirror() bool {
+	return false
+}
+
+func (api APIClient) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	return interfaces.ErrNotSupported
+}
+
+// Issues are not part of the git protocol, a plain git server has none to list.
+func (api APIClient) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	return model.Metadata{}, interfaces.ErrNotSupported
+}
+
+func (api APIClient) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	return model.Label{}, ErrSourceOnly
+}
+
+func (api APIClient) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	return model.Milestone{}, ErrSourceOnly
+}
+
+func (api APIClient) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	return ErrSourceOnly
+}
+
+func NewGitRemoteAPIClient(ctx context.Context) APIClient {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitRemote:NewGitRemoteAPIClient")
+
+	return APIClient{remoteService: NewRemoteService()}
+}
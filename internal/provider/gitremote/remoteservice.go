@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitremote
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/target/gitlib"
+)
+
+var (
+	ErrNoURLs         = errors.New("no repository urls configured")
+	ErrReadURLsFile   = errors.New("failed to read repository urls file")
+	ErrRepositoryName = errors.New("failed to derive repository name from url")
+	ErrDuplicateName  = errors.New("duplicate repository name")
+	ErrListRemote     = errors.New("failed to list remote references")
+)
+
+// preferredBranches are tried in order when a remote doesn't advertise where HEAD points.
+var preferredBranches = []string{"main", "master"}
+
+// RemoteService discovers repository information directly over the git protocol.
+type RemoteService struct{}
+
+func NewRemoteService() *RemoteService {
+	return &RemoteService{}
+}
+
+func (r RemoteService) getProjectInfos(ctx context.Context, cfg config.ProviderConfig) ([]model.ProjectInfo, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitRemote:getProjectInfos")
+
+	urls, err := RepositoryURLs(cfg.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	projectinfos := make([]model.ProjectInfo, 0, len(urls))
+	seen := make(map[string]string, len(urls))
+
+	for _, url := range urls {
+		name, err := repositoryName(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if previous, found := seen[strings.ToLower(name)]; found {
+			return nil, fmt.Errorf("%w: %s is used by both %s and %s", ErrDuplicateName, name, previous, url)
+		}
+
+		seen[strings.ToLower(name)] = url
+
+		auth, err := gitlib.NewAuthService().GetAuthMethod(ctx, url, cfg.Git, cfg.HTTPClient, cfg.SSHClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get auth method: %w", err)
+		}
+
+		defaultBranch, err := r.defaultBranch(ctx, url, auth)
+		if err != nil {
+			return nil, err
+		}
+
+		projectinfos = append(projectinfos, model.ProjectInfo{
+			OriginalName:  name,
+			HTTPSURL:      url,
+			SSHURL:        url,
+			DefaultBranch: defaultBranch,
+			// A plain git server has no notion of visibility, so be conservative
+			Visibility: "private",
+			ProjectID:  url,
+		})
+	}
+
+	return projectinfos, nil
+}
+
+// defaultBranch runs the equivalent of git ls-remote and returns the branch the remote HEAD points to.
+func (r RemoteService) defaultBranch(ctx context.Context, url string, auth transport.AuthMethod) (string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitRemote:defaultBranch")
+	logger.Debug().Str("url", url).Msg("GitRemote:defaultBranch")
+
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: config.ORIGIN,
+		URLs: []string{url},
+	})
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("%w: %s: %w", ErrListRemote, url, err)
+	}
+
+	return headBranch(refs), nil
+}
+
+// headBranch resolves the default branch from a list of advertised references.
+// Servers announce HEAD as a symbolic reference, older ones only by hash, in which
+// case a branch at the same commit is picked, preferring the common default names.
+func headBranch(refs []*plumbing.Reference) string {
+	var head *plumbing.Reference
+
+	branches := map[string]plumbing.Hash{}
+
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD:
+			head = ref
+		case ref.Name().IsBranch():
+			branches[ref.Name().Short()] = ref.Hash()
+		}
+	}
+
+	if head == nil {
+		return ""
+	}
+
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target().Short()
+	}
+
+	candidates := make([]string, 0, len(branches))
+
+	for name, hash := range branches {
+		if hash == head.Hash() {
+			candidates = append(candidates, name)
+		}
+	}
+
+	for _, preferred := range preferredBranches {
+		if slices.Contains(candidates, preferred) {
+			return preferred
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	slices.Sort(candidates)
+
+	return candidates[0]
+}
+
+// RepositoryURLs returns the inline configured urls followed by the ones in the urls file.
+func RepositoryURLs(opt config.RepositoriesOption) ([]string, error) {
+	urls := opt.URLList()
+
+	if opt.URLsFile != "" {
+		fileURLs, err := readURLsFile(opt.URLsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, fileURLs...)
+	}
+
+	if len(urls) == 0 {
+		return nil, ErrNoURLs
+	}
+
+	return urls, nil
+}
+
+// readURLsFile reads one url per line, skipping blank lines and lines starting with #.
+func readURLsFile(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReadURLsFile, err)
+	}
+	defer file.Close()
+
+	var urls []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		urls = append(urls, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReadURLsFile, err)
+	}
+
+	return urls, nil
+}
+
+// repositoryName derives the repository name from the last path element of a clone url,
+// e.g. https://git.example.com/cgit/tool.git and git@example.com:team/tool.git both give tool.
+func repositoryName(url string) (string, error) {
+	trimmed := strings.TrimRight(url, "/")
+
+	// scp-like syntax user@host:path has no scheme
+	if !strings.Contains(trimmed, "://") {
+		if _, after, found := strings.Cut(trimmed, ":"); found {
+			trimmed = after
+		}
+	}
+
+	name := strings.TrimSuffix(path.Base(trimmed), ".git")
+	if name == "" || name == "." || name == "/" || strings.Contains(name, ":") {
+		return "", fmt.Errorf("%w: %s", ErrRepositoryName, url)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitremote
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+// createRepo initializes a repository with one commit on the given default branch.
+func createRepo(t *testing.T, path string, defaultBranch string) {
+	t.Helper()
+
+	repo, err := git.PlainInitWithOptions(path, &git.PlainInitOptions{
+		InitOptions: git.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName(defaultBranch)},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "README"), []byte("readme"), 0o600))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Add("README")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+func TestAPIClient_ProjectInfos(t *testing.T) {
+	require := require.New(t)
+	tmpDir := t.TempDir()
+
+	createRepo(t, filepath.Join(tmpDir, "first"), "develop")
+	createRepo(t, filepath.Join(tmpDir, "second.git"), "main")
+
+	urlsFile := filepath.Join(tmpDir, "urls.txt")
+	require.NoError(os.WriteFile(urlsFile, []byte("# mirrored repositories\n\n"+filepath.Join(tmpDir, "second.git")+"\n"), 0o600))
+
+	cfg := config.ProviderConfig{
+		ProviderType: config.GITREMOTE,
+		Repositories: config.RepositoriesOption{URLs: filepath.Join(tmpDir, "first"), URLsFile: urlsFile},
+	}
+
+	client := NewGitRemoteAPIClient(context.Background())
+
+	infos, err := client.ProjectInfos(context.Background(), cfg, false)
+	require.NoError(err)
+	require.Equal([]model.ProjectInfo{
+		{
+			OriginalName:  "first",
+			HTTPSURL:      filepath.Join(tmpDir, "first"),
+			SSHURL:        filepath.Join(tmpDir, "first"),
+			DefaultBranch: "develop",
+			Visibility:    "private",
+			ProjectID:     filepath.Join(tmpDir, "first"),
+		},
+		{
+			OriginalName:  "second",
+			HTTPSURL:      filepath.Join(tmpDir, "second.git"),
+			SSHURL:        filepath.Join(tmpDir, "second.git"),
+			DefaultBranch: "main",
+			Visibility:    "private",
+			ProjectID:     filepath.Join(tmpDir, "second.git"),
+		},
+	}, infos)
+
+	cfg.Repositories.Exclude = "first"
+	infos, err = client.ProjectInfos(context.Background(), cfg, true)
+	require.NoError(err)
+	require.Len(infos, 1)
+	require.Equal("second", infos[0].OriginalName)
+
+	_, err = client.ProjectInfos(context.Background(), config.ProviderConfig{}, false)
+	require.ErrorIs(err, ErrNoURLs)
+
+	_, err = client.ProjectInfos(context.Background(), config.ProviderConfig{Repositories: config.RepositoriesOption{URLs: filepath.Join(tmpDir, "first") + "," + filepath.Join(tmpDir, "other", "first")}}, false)
+	require.ErrorIs(err, ErrDuplicateName)
+}
+
+func TestHeadBranch(t *testing.T) {
+	hash := plumbing.NewHash("1111111111111111111111111111111111111111")
+	other := plumbing.NewHash("2222222222222222222222222222222222222222")
+
+	tests := []struct {
+		name string
+		refs []*plumbing.Reference
+		want string
+	}{
+		{
+			name: "symbolic head",
+			refs: []*plumbing.Reference{
+				plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("trunk")),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("trunk"), hash),
+			},
+			want: "trunk",
+		},
+		{
+			name: "hash head prefers main",
+			refs: []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.HEAD, hash),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("feature"), hash),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), hash),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), other),
+			},
+			want: "main",
+		},
+		{
+			name: "hash head without preferred branch",
+			refs: []*plumbing.Reference{
+				plumbing.NewHashReference(plumbing.HEAD, hash),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("stable"), hash),
+				plumbing.NewHashReference(plumbing.NewBranchReferenceName("alpha"), hash),
+			},
+			want: "alpha",
+		},
+		{
+			name: "no head",
+			refs: []*plumbing.Reference{plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), hash)},
+			want: "",
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, headBranch(tabletest.refs))
+		})
+	}
+}
+
+func TestRepositoryName(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"https://git.example.com/cgit/tool.git", "tool", false},
+		{"https://git.example.com/cgit/tool/", "tool", false},
+		{"ssh://git@example.com:2222/team/tool.git", "tool", false},
+		{"git@example.com:team/tool.git", "tool", false},
+		{"git@example.com:tool", "tool", false},
+		{"/srv/git/tool.git", "tool", false},
+		{"git@example.com:", "", true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.url, func(t *testing.T) {
+			name, err := repositoryName(tabletest.url)
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, ErrRepositoryName)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tabletest.want, name)
+		})
+	}
+}
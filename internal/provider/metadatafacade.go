@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrSyncMetadata = errors.New("failed to sync metadata")
+
+const (
+	// LabelsFile, MilestonesFile and IssuesFile hold the exported metadata of a repository, as JSON Lines.
+	LabelsFile     = "labels.jsonl"
+	MilestonesFile = "milestones.jsonl"
+	IssuesFile     = "issues.jsonl"
+
+	// MetadataDir is the directory an archive keeps the metadata in, next to the repository.
+	MetadataDir = "metadata"
+
+	// MetadataSuffix is added to the name of a repository for the directory a directory target keeps its metadata in.
+	MetadataSuffix = ".metadata"
+)
+
+// importedHeader matches the header an imported issue starts with, telling the issue it was imported from.
+var importedHeader = regexp.MustCompile(`^\*Originally (issue|merge request) #(\d+) by `)
+
+// SupportsMetadata reports whether repositories of the provider type have issues, labels, and milestones.
+func SupportsMetadata(providerType string) bool {
+	switch strings.ToLower(providerType) {
+	case config.GITHUB, config.GITLAB, config.GITEA:
+		return true
+	default:
+		return false
+	}
+}
+
+// SyncMetadata recreates the labels, milestones, and issues of the source repository at the target repository.
+// Merge requests are recreated as issues, as their commits may be gone. Authorship can't be set, so every issue
+// and comment starts with a header telling who wrote it and when. Issues imported before are recognized by
+// their header and not imported again, edits at the source after an import are not synced.
+//
+// A failing issue doesn't stop the others, all failures are returned together.
+func SyncMetadata(ctx context.Context, sourceProviderCfg config.ProviderConfig, source interfaces.GitProvider, targetProviderCfg config.ProviderConfig, target interfaces.GitProvider, repository interfaces.GitRepository) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering SyncMetadata")
+
+	if repository.ProjectInfo().IsWiki() {
+		return nil
+	}
+
+	if !SupportsMetadata(targetProviderCfg.ProviderType) {
+		logger.Warn().Str("name", repository.ProjectInfo().OriginalName).Str("provider", targetProviderCfg.ProviderType).
+			Msg("Target provider has no issues, skipping metadata")
+
+		return nil
+	}
+
+	metadata, err := source.Metadata(ctx, getOwner(sourceProviderCfg), repository.ProjectInfo().OriginalName, true)
+	if err != nil {
+		return fmt.Errorf("%w: read source metadata: %w", ErrSyncMetadata, err)
+	}
+
+	owner, name := getOwner(targetProviderCfg), repository.ProjectInfo().Name(ctx)
+
+	existing, err := target.Metadata(ctx, owner, name, false)
+	if err != nil {
+		return fmt.Errorf("%w: read target metadata: %w", ErrSyncMetadata, err)
+	}
+
+	var failures []error
+
+	labels := map[string]model.Label{}
+	for _, label := range existing.Labels {
+		labels[label.Name] = label
+	}
+
+	for _, label := range metadata.Labels {
+		if _, found := labels[label.Name]; found {
+			continue
+		}
+
+		created, err := target.CreateLabel(ctx, owner, name, label)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%w: label %s: %w", ErrSyncMetadata, label.Name, err))
+
+			continue
+		}
+
+		labels[label.Name] = created
+	}
+
+	milestones := map[string]model.Milestone{}
+	for _, milestone := range existing.Milestones {
+		milestones[milestone.Title] = milestone
+	}
+
+	for _, milestone := range metadata.Milestones {
+		if _, found := milestones[milestone.Title]; found {
+			continue
+		}
+
+		created, err := target.CreateMilestone(ctx, owner, name, milestone)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%w: milestone %s: %w", ErrSyncMetadata, milestone.Title, err))
+
+			continue
+		}
+
+		milestones[milestone.Title] = created
+	}
+
+	imported := map[string]bool{}
+
+	for _, issue := range existing.Issues {
+		if match := importedHeader.FindStringSubmatch(issue.Body); match != nil {
+			imported[match[1]+match[2]] = true
+		}
+	}
+
+	created := 0
+
+	for _, issue := range byCreation(metadata.Issues) {
+		if imported[issueKind(issue)+strconv.Itoa(issue.Number)] {
+			continue
+		}
+
+		if err := target.CreateIssue(ctx, owner, name, toImported(issue, labels, milestones)); err != nil {
+			failures = append(failures, fmt.Errorf("%w: %s #%d: %w", ErrSyncMetadata, issueKind(issue), issue.Number, err))
+
+			continue
+		}
+
+		created++
+	}
+
+	logger.Debug().Str("name", name).Int("issues", created).Msg("SyncMetadata")
+
+	return errors.Join(failures...)
+}
+
+// ExportMetadata writes the labels, milestones, and issues of the source repository, with their comments,
+// to the LabelsFile, MilestonesFile and IssuesFile in dir, for targets keeping repositories on disk.
+func ExportMetadata(ctx context.Context, sourceProviderCfg config.ProviderConfig, source interfaces.GitProvider, repository interfaces.GitRepository, dir string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ExportMetadata")
+	logger.Debug().Str("dir", dir).Msg("ExportMetadata")
+
+	if repository.ProjectInfo().IsWiki() {
+		return nil
+	}
+
+	metadata, err := source.Metadata(ctx, getOwner(sourceProviderCfg), repository.ProjectInfo().OriginalName, true)
+	if err != nil {
+		return fmt.Errorf("%w: read source metadata: %w", ErrSyncMetadata, err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncMetadata, err)
+	}
+
+	if err := writeJSONLines(filepath.Join(dir, LabelsFile), metadata.Labels); err != nil {
+		return err
+	}
+
+	if err := writeJSONLines(filepath.Join(dir, MilestonesFile), metadata.Milestones); err != nil {
+		return err
+	}
+
+	return writeJSONLines(filepath.Join(dir, IssuesFile), byCreation(metadata.Issues))
+}
+
+// toImported returns the issue as it is to be created at the target, with the header of its origin
+// and the labels and milestone of the target.
+func toImported(issue model.Issue, labels map[string]model.Label, milestones map[string]model.Milestone) model.Issue {
+	header := fmt.Sprintf("*Originally %s #%d by @%s on %s", issueKind(issue), issue.Number, issue.Author, formatDate(issue.CreatedAt))
+	if issue.MergeRequest != nil {
+		state := "not merged"
+		if issue.MergeRequest.Merged {
+			state = "merged"
+		}
+
+		header += fmt.Sprintf(", from %s into %s, %s", issue.MergeRequest.SourceBranch, issue.MergeRequest.TargetBranch, state)
+	}
+
+	imported := model.Issue{
+		Number: issue.Number,
+		Title:  issue.Title,
+		Body:   header + "*\n\n" + issue.Body,
+		Author: issue.Author,
+		Closed: issue.Closed,
+	}
+
+	for _, label := range issue.Labels {
+		if targetLabel, found := labels[label.Name]; found {
+			imported.Labels = append(imported.Labels, targetLabel)
+		}
+	}
+
+	if issue.Milestone != nil {
+		if milestone, found := milestones[issue.Milestone.Title]; found {
+			imported.Milestone = &milestone
+		}
+	}
+
+	for _, comment := range issue.Comments {
+		imported.Comments = append(imported.Comments, model.IssueComment{
+			Author: comment.Author,
+			Body:   fmt.Sprintf("*Originally by @%s on %s*\n\n%s", comment.Author, formatDate(comment.CreatedAt), comment.Body),
+		})
+	}
+
+	return imported
+}
+
+// byCreation returns the issues oldest first, as they are to be created. Issues are
+// listed before merge requests created at the same time, or without a creation time.
+func byCreation(issues []model.Issue) []model.Issue {
+	sorted := slices.Clone(issues)
+
+	createdAt := func(issue model.Issue) time.Time {
+		if issue.CreatedAt == nil {
+			return time.Time{}
+		}
+
+		return *issue.CreatedAt
+	}
+
+	slices.SortStableFunc(sorted, func(first, second model.Issue) int {
+		if order := createdAt(first).Compare(createdAt(second)); order != 0 {
+			return order
+		}
+
+		return strings.Compare(issueKind(first), issueKind(second))
+	})
+
+	return sorted
+}
+
+func issueKind(issue model.Issue) string {
+	if issue.MergeRequest != nil {
+		return "merge request"
+	}
+
+	return "issue"
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return "an unknown date"
+	}
+
+	return date.UTC().Format(time.DateOnly)
+}
+
+// writeJSONLines writes each value as a line of JSON to the file at path, replacing any file there.
+func writeJSONLines[T any](path string, values []T) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncMetadata, err)
+	}
+
+	encoder := json.NewEncoder(file)
+
+	for _, value := range values {
+		if err = encoder.Encode(value); err != nil {
+			break
+		}
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrSyncMetadata, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncMetadata(t *testing.T) {
+	errProvider := errors.New("provider error")
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	bug := model.Label{ID: "1", Name: "bug", Color: "ff0000"}
+	release := model.Milestone{ID: "1", Title: "1.0"}
+
+	sourceMetadata := model.Metadata{
+		Labels:     []model.Label{bug},
+		Milestones: []model.Milestone{release},
+		Issues: []model.Issue{
+			{
+				Number: 2, Title: "Fix", Body: "Fixes #1", Author: "alice", CreatedAt: &newer, Closed: true,
+				MergeRequest: &model.MergeRequest{SourceBranch: "fix", TargetBranch: "main", Merged: true},
+			},
+			{
+				Number: 1, Title: "Broken", Body: "It breaks", Author: "bob", CreatedAt: &older,
+				Labels: []model.Label{bug}, Milestone: &release,
+				Comments: []model.IssueComment{{Author: "alice", Body: "Confirmed", CreatedAt: &newer}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		targetType   string
+		setupMocks   func(source, target *MockGitProvider)
+		wantErr      error
+		wantNoSource bool
+	}{
+		{
+			name:       "creates labels, milestones, and issues oldest first",
+			targetType: config.GITLAB,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Metadata", mock.Anything, "source", "repo", true).Return(sourceMetadata, nil)
+				target.On("Metadata", mock.Anything, "target", "repo", false).Return(model.Metadata{}, nil)
+				target.On("CreateLabel", mock.Anything, "target", "repo", bug).Return(model.Label{ID: "21", Name: "bug"}, nil)
+				target.On("CreateMilestone", mock.Anything, "target", "repo", release).Return(model.Milestone{ID: "31", Title: "1.0"}, nil)
+
+				first := target.On("CreateIssue", mock.Anything, "target", "repo", mock.MatchedBy(func(issue model.Issue) bool {
+					return issue.Number == 1 &&
+						strings.HasPrefix(issue.Body, "*Originally issue #1 by @bob on 2024-01-01*\n\nIt breaks") &&
+						issue.Labels[0].ID == "21" && issue.Milestone.ID == "31" &&
+						strings.HasPrefix(issue.Comments[0].Body, "*Originally by @alice on 2024-01-01*\n\nConfirmed")
+				})).Return(nil).Once()
+				target.On("CreateIssue", mock.Anything, "target", "repo", mock.MatchedBy(func(issue model.Issue) bool {
+					return issue.Number == 2 && issue.Closed &&
+						strings.HasPrefix(issue.Body, "*Originally merge request #2 by @alice on 2024-01-01, from fix into main, merged*")
+				})).Return(nil).Once().NotBefore(first)
+			},
+		},
+		{
+			name:       "leaves existing labels, milestones, and imported issues",
+			targetType: config.GITHUB,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Metadata", mock.Anything, "source", "repo", true).Return(sourceMetadata, nil)
+				target.On("Metadata", mock.Anything, "target", "repo", false).Return(model.Metadata{
+					Labels:     []model.Label{{ID: "21", Name: "bug"}},
+					Milestones: []model.Milestone{{ID: "31", Title: "1.0"}},
+					Issues: []model.Issue{
+						{Number: 1, Body: "*Originally issue #1 by @bob on 2024-01-01*\n\nIt breaks"},
+						{Number: 2, Body: "*Originally merge request #2 by @alice on 2024-01-01, from fix into main, merged*\n\nFixes #1"},
+					},
+				}, nil)
+			},
+		},
+		{
+			name:       "failing issue",
+			targetType: config.GITEA,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Metadata", mock.Anything, "source", "repo", true).Return(model.Metadata{Issues: sourceMetadata.Issues[1:]}, nil)
+				target.On("Metadata", mock.Anything, "target", "repo", false).Return(model.Metadata{}, nil)
+				target.On("CreateIssue", mock.Anything, "target", "repo", mock.Anything).Return(errProvider)
+			},
+			wantErr: ErrSyncMetadata,
+		},
+		{
+			name:         "target without issues",
+			targetType:   config.BITBUCKET,
+			setupMocks:   func(_, _ *MockGitProvider) {},
+			wantNoSource: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			source, target := &MockGitProvider{}, &MockGitProvider{}
+			tabletest.setupMocks(source, target)
+
+			sourceCfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "source"}
+			targetCfg := config.ProviderConfig{ProviderType: tabletest.targetType, Group: "target"}
+			repository := testRepository{projectInfo: model.ProjectInfo{OriginalName: "repo"}}
+
+			err := SyncMetadata(testContext(), sourceCfg, source, targetCfg, target, repository)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+			} else {
+				require.NoError(err)
+			}
+
+			source.AssertExpectations(t)
+			target.AssertExpectations(t)
+
+			if tabletest.wantNoSource {
+				source.AssertNotCalled(t, "Metadata", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestExportMetadata(t *testing.T) {
+	require := require.New(t)
+
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	source := &MockGitProvider{}
+	source.On("Metadata", mock.Anything, "source", "repo", true).Return(model.Metadata{
+		Labels: []model.Label{{ID: "1", Name: "bug", Color: "ff0000"}},
+		Issues: []model.Issue{
+			{Number: 2, Title: "Second", CreatedAt: &newer},
+			{Number: 1, Title: "First", CreatedAt: &older},
+		},
+	}, nil)
+
+	dir := filepath.Join(t.TempDir(), "repo"+MetadataSuffix)
+	sourceCfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "source"}
+	repository := testRepository{projectInfo: model.ProjectInfo{OriginalName: "repo"}}
+
+	require.NoError(ExportMetadata(testContext(), sourceCfg, source, repository, dir))
+
+	labels, err := os.ReadFile(filepath.Join(dir, LabelsFile))
+	require.NoError(err)
+	require.JSONEq(`{"name":"bug","color":"ff0000"}`, string(labels))
+
+	milestones, err := os.ReadFile(filepath.Join(dir, MilestonesFile))
+	require.NoError(err)
+	require.Empty(milestones)
+
+	file, err := os.Open(filepath.Join(dir, IssuesFile))
+	require.NoError(err)
+
+	defer file.Close()
+
+	var titles []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var issue model.Issue
+		require.NoError(json.Unmarshal(scanner.Bytes(), &issue))
+
+		titles = append(titles, issue.Title)
+	}
+
+	require.Equal([]string{"First", "Second"}, titles)
+}
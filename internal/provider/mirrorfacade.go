@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrMirrorRepository = errors.New("failed to mirror repository")
+
+// SupportsProviderMirror reports whether the provider type can pull mirror a repository itself.
+func SupportsProviderMirror(providerType string) bool {
+	switch strings.ToLower(providerType) {
+	case config.GITLAB, config.GITEA:
+		return true
+	default:
+		return false
+	}
+}
+
+// Mirror has the target provider mirror the source repository itself, instead of pushing it. A mirror is created
+// when the repository is not at the target, and an update of it is started. The mirror authenticates to the source
+// with the source token. Gitea mirrors a wiki along with its repository, wiki repositories are skipped.
+func Mirror(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, repository interfaces.GitRepository, sourceProviderConfig config.ProviderConfig) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Mirror")
+	targetProviderCfg.DebugLog(logger).Msg("Mirror")
+
+	if repository.ProjectInfo().IsWiki() {
+		return nil
+	}
+
+	name := repository.ProjectInfo().Name(ctx)
+
+	_, projectID, err := repositoryExists(ctx, targetProviderCfg, provider, name)
+	if err != nil {
+		return err
+	}
+
+	sourceURL := repository.ProjectInfo().HTTPSURL
+
+	option, err := projectOption(ctx, targetProviderCfg, sourceProviderConfig, repository, model.Remote{URL: sourceURL})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMirrorRepository, name, err)
+	}
+
+	option.MirrorURL = sourceURL
+	option.MirrorToken = sourceProviderConfig.HTTPClient.Token
+	option.LFS = sourceProviderConfig.Git.LFS
+
+	if err := provider.MirrorProject(ctx, targetProviderCfg, option, projectID); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMirrorRepository, name, err)
+	}
+
+	return nil
+}
+
+// PushMirror has the source provider push mirror the repository to the target, instead of it being pushed.
+// The repository is created at the target first, when it is not there. The source pushes to it with the target token.
+// Gitea pushes a wiki along with its repository, wiki repositories are skipped.
+func PushMirror(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, repository interfaces.GitRepository, sourceProviderConfig config.ProviderConfig, sourceProvider interfaces.GitProvider) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering PushMirror")
+	targetProviderCfg.DebugLog(logger).Msg("PushMirror")
+
+	if repository.ProjectInfo().IsWiki() {
+		return nil
+	}
+
+	name := repository.ProjectInfo().Name(ctx)
+
+	found, _, err := repositoryExists(ctx, targetProviderCfg, provider, name)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		option, err := projectOption(ctx, targetProviderCfg, sourceProviderConfig, repository, model.Remote{URL: repository.ProjectInfo().HTTPSURL})
+		if err != nil {
+			return fmt.Errorf("%w: %s. err: %w", ErrCreateRepository, name, err)
+		}
+
+		if _, err := provider.CreateProject(ctx, targetProviderCfg, option); err != nil {
+			return fmt.Errorf("%w: %s. err: %w", ErrCreateRepository, name, err)
+		}
+	}
+
+	option := model.PushMirrorOption{
+		URL:   toGitURL(ctx, targetProviderCfg, repository),
+		Token: targetProviderCfg.HTTPClient.Token,
+	}
+
+	err = sourceProvider.PushMirror(ctx, getOwner(sourceProviderConfig), repository.ProjectInfo().OriginalName, option)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrMirrorRepository, name, err)
+	}
+
+	return nil
+}
+
+// Listed returns the listed source repositories without cloning them, for targets that are mirrors of the source.
+func Listed(ctx context.Context, sourceProviderConfig config.ProviderConfig, projectinfos []model.ProjectInfo) []interfaces.GitRepository {
+	cleanupName := model.CLIOptions(ctx).CleanupName || sourceProviderConfig.SyncRun.CleanupInvalidName
+	repositories := make([]interfaces.GitRepository, 0, len(projectinfos))
+
+	for _, projectinfo := range projectinfos {
+		projectinfo.CleanupName = cleanupName
+		repositories = append(repositories, model.Repository{ProjectMetaInfo: projectinfo})
+	}
+
+	return repositories
+}
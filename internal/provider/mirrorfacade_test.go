@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"errors"
+	"testing"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirror(t *testing.T) {
+	errProvider := errors.New("provider error")
+	sourceURL := "https://github.com/source/repo.git"
+
+	tests := []struct {
+		name       string
+		wiki       bool
+		setupMocks func(target *MockGitProvider)
+		wantErr    error
+	}{
+		{
+			name: "creates mirror when missing",
+			setupMocks: func(target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).Return([]model.ProjectInfo{}, nil)
+				target.On("MirrorProject", mock.Anything, mock.Anything, mock.MatchedBy(func(option model.CreateProjectOption) bool {
+					return option.RepositoryName == "repo" && option.MirrorURL == sourceURL && option.MirrorToken == "secret" &&
+						option.Visibility == "public" && option.LFS
+				}), "").Return(nil)
+			},
+		},
+		{
+			name: "updates existing mirror",
+			setupMocks: func(target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{OriginalName: "repo", ProjectID: "42"}}, nil)
+				target.On("MirrorProject", mock.Anything, mock.Anything, mock.Anything, "42").Return(nil)
+			},
+		},
+		{
+			name:       "skips wiki",
+			wiki:       true,
+			setupMocks: func(_ *MockGitProvider) {},
+		},
+		{
+			name: "failing mirror",
+			setupMocks: func(target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).Return([]model.ProjectInfo{}, nil)
+				target.On("MirrorProject", mock.Anything, mock.Anything, mock.Anything, "").Return(errProvider)
+			},
+			wantErr: ErrMirrorRepository,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			target := &MockGitProvider{}
+			tabletest.setupMocks(target)
+
+			sourceCfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "source"}
+			sourceCfg.HTTPClient.Token = "secret"
+			sourceCfg.Git.LFS = true
+			targetCfg := config.ProviderConfig{ProviderType: config.GITLAB, Group: "target"}
+
+			projectInfo := model.ProjectInfo{OriginalName: "repo", HTTPSURL: sourceURL, Visibility: "public"}
+			if tabletest.wiki {
+				projectInfo = projectInfo.Wiki()
+			}
+
+			err := Mirror(testContext(), targetCfg, target, testRepository{projectInfo: projectInfo}, sourceCfg)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+			} else {
+				require.NoError(err)
+			}
+
+			target.AssertExpectations(t)
+
+			if tabletest.wiki {
+				target.AssertNotCalled(t, "MirrorProject", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPushMirror(t *testing.T) {
+	errProvider := errors.New("provider error")
+	targetURL := "https://gitlab.com/target/repo"
+
+	tests := []struct {
+		name       string
+		wiki       bool
+		setupMocks func(source, target *MockGitProvider)
+		wantErr    error
+	}{
+		{
+			name: "creates target repository before adding the mirror",
+			setupMocks: func(source, target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).Return([]model.ProjectInfo{}, nil)
+				created := target.On("CreateProject", mock.Anything, mock.Anything, mock.MatchedBy(func(option model.CreateProjectOption) bool {
+					return option.RepositoryName == "repo" && option.MirrorURL == ""
+				})).Return("42", nil)
+				source.On("PushMirror", mock.Anything, "source", "repo", model.PushMirrorOption{URL: targetURL, Token: "secret"}).
+					Return(nil).NotBefore(created)
+			},
+		},
+		{
+			name: "adds the mirror to an existing target repository",
+			setupMocks: func(source, target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{OriginalName: "repo", ProjectID: "42"}}, nil)
+				source.On("PushMirror", mock.Anything, "source", "repo", mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:       "skips wiki",
+			wiki:       true,
+			setupMocks: func(_, _ *MockGitProvider) {},
+		},
+		{
+			name: "failing create",
+			setupMocks: func(_, target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).Return([]model.ProjectInfo{}, nil)
+				target.On("CreateProject", mock.Anything, mock.Anything, mock.Anything).Return("", errProvider)
+			},
+			wantErr: ErrCreateRepository,
+		},
+		{
+			name: "failing mirror",
+			setupMocks: func(source, target *MockGitProvider) {
+				target.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{OriginalName: "repo", ProjectID: "42"}}, nil)
+				source.On("PushMirror", mock.Anything, "source", "repo", mock.Anything).Return(errProvider)
+			},
+			wantErr: ErrMirrorRepository,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			source, target := &MockGitProvider{}, &MockGitProvider{}
+			tabletest.setupMocks(source, target)
+
+			sourceCfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "source"}
+			targetCfg := config.ProviderConfig{ProviderType: config.GITLAB, Domain: "gitlab.com", Group: "target"}
+			targetCfg.HTTPClient.Token = "secret"
+
+			projectInfo := model.ProjectInfo{OriginalName: "repo", HTTPSURL: "https://github.com/source/repo.git", Visibility: "public"}
+			if tabletest.wiki {
+				projectInfo = projectInfo.Wiki()
+			}
+
+			err := PushMirror(testContext(), targetCfg, target, testRepository{projectInfo: projectInfo}, sourceCfg, source)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+			} else {
+				require.NoError(err)
+			}
+
+			source.AssertExpectations(t)
+			target.AssertExpectations(t)
+
+			if tabletest.wiki {
+				source.AssertNotCalled(t, "PushMirror", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
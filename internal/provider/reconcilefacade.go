@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var (
+	ErrRenameRepository  = errors.New("failed to rename repository")
+	ErrMissingRepository = errors.New("failed to apply syncrun.onmissing to repository")
+)
+
+// SourceURL returns the source repository URL recorded in the description of a target repository
+// created by Git Provider Sync. Descriptions without the DescriptionMarker give false.
+func SourceURL(description string) (string, bool) {
+	rest, found := strings.CutPrefix(description, DescriptionMarker)
+	if !found {
+		return "", false
+	}
+
+	sourceURL, _, _ := strings.Cut(rest, ": ")
+	if sourceURL == "" {
+		return "", false
+	}
+
+	return sourceURL, true
+}
+
+// Rename renames a target repository, after its source repository was renamed.
+func Rename(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, name, newName string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Rename")
+	logger.Debug().Str("name", name).Str("newName", newName).Msg("Rename")
+
+	if err := provider.RenameProject(ctx, getOwner(targetProviderCfg), name, newName); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrRenameRepository, name, err)
+	}
+
+	return nil
+}
+
+// RemoveMissing applies the syncrun.onmissing policy of the target to a repository whose source repository is gone.
+// The rename policy appends syncrun.missingsuffix to the name. Ignore leaves the repository as is.
+func RemoveMissing(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, name string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering RemoveMissing")
+
+	policy := targetProviderCfg.SyncRun.MissingPolicy()
+	owner := getOwner(targetProviderCfg)
+
+	logger.Debug().Str("name", name).Str("policy", policy).Msg("RemoveMissing")
+
+	var err error
+
+	switch policy {
+	case config.OnMissingArchive:
+		err = provider.ArchiveProject(ctx, owner, name)
+	case config.OnMissingRename:
+		err = provider.RenameProject(ctx, owner, name, name+targetProviderCfg.SyncRun.RemovedSuffix())
+	case config.OnMissingDelete:
+		err = provider.DeleteProject(ctx, owner, name)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s: %w", ErrMissingRepository, policy, name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"errors"
+	"testing"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+		wantFound   bool
+	}{
+		{"marker", DescriptionMarker + "https://github.com/owner/repo.git: ", "https://github.com/owner/repo.git", true},
+		{"marker with description", DescriptionMarker + "git@github.com:owner/repo.git: A repository: with colons", "git@github.com:owner/repo.git", true},
+		{"user description", "A repository", "", false},
+		{"marker without url", DescriptionMarker, "", false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			got, found := SourceURL(tabletest.description)
+			require.Equal(t, tabletest.wantFound, found)
+			require.Equal(t, tabletest.want, got)
+		})
+	}
+}
+
+func TestRemoveMissing(t *testing.T) {
+	errProvider := errors.New("provider error")
+
+	tests := []struct {
+		name       string
+		syncRun    config.SyncRunOption
+		setupMocks func(*MockGitProvider)
+		wantErr    error
+	}{
+		{
+			name:    "archive",
+			syncRun: config.SyncRunOption{OnMissing: config.OnMissingArchive},
+			setupMocks: func(provider *MockGitProvider) {
+				provider.On("ArchiveProject", mock.Anything, "owner", "repo").Return(nil)
+			},
+		},
+		{
+			name:    "rename with default suffix",
+			syncRun: config.SyncRunOption{OnMissing: config.OnMissingRename},
+			setupMocks: func(provider *MockGitProvider) {
+				provider.On("RenameProject", mock.Anything, "owner", "repo", "repo-removed").Return(nil)
+			},
+		},
+		{
+			name:    "rename with suffix",
+			syncRun: config.SyncRunOption{OnMissing: config.OnMissingRename, MissingSuffix: "-gone"},
+			setupMocks: func(provider *MockGitProvider) {
+				provider.On("RenameProject", mock.Anything, "owner", "repo", "repo-gone").Return(nil)
+			},
+		},
+		{
+			name:    "delete",
+			syncRun: config.SyncRunOption{OnMissing: config.OnMissingDelete},
+			setupMocks: func(provider *MockGitProvider) {
+				provider.On("DeleteProject", mock.Anything, "owner", "repo").Return(errProvider)
+			},
+			wantErr: ErrMissingRepository,
+		},
+		{
+			name:       "ignore",
+			syncRun:    config.SyncRunOption{},
+			setupMocks: func(_ *MockGitProvider) {},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			provider := &MockGitProvider{}
+			tabletest.setupMocks(provider)
+
+			cfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "owner", SyncRun: tabletest.syncRun}
+
+			err := RemoveMissing(testContext(), cfg, provider, "repo")
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+			} else {
+				require.NoError(err)
+			}
+
+			provider.AssertExpectations(t)
+		})
+	}
+}
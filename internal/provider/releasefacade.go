@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/interfaces"
+	"itiquette/git-provider-sync/internal/log"
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var ErrSyncRelease = errors.New("failed to sync release")
+
+const (
+	// ReleasesFile lists the exported releases of a repository, their assets are kept in a directory per tag next to it.
+	ReleasesFile = "releases.json"
+
+	assetsDir = "assets"
+)
+
+// SupportsReleases reports whether repositories of the provider type have releases with assets.
+func SupportsReleases(providerType string) bool {
+	switch strings.ToLower(providerType) {
+	case config.GITHUB, config.GITLAB, config.GITEA:
+		return true
+	default:
+		return false
+	}
+}
+
+// SyncReleases creates the releases of the source repository at the target repository, pushed before,
+// and updates those already there when they changed. Assets missing at a target release are
+// downloaded from the source and uploaded to it, one at a time. Drafts are not published yet and left out.
+//
+// A failing release doesn't stop the others, all failures are returned together.
+func SyncReleases(ctx context.Context, sourceProviderCfg config.ProviderConfig, source interfaces.GitProvider, targetProviderCfg config.ProviderConfig, target interfaces.GitProvider, repository interfaces.GitRepository) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering SyncReleases")
+
+	if repository.ProjectInfo().IsWiki() {
+		return nil
+	}
+
+	if !SupportsReleases(targetProviderCfg.ProviderType) {
+		logger.Warn().Str("name", repository.ProjectInfo().OriginalName).Str("provider", targetProviderCfg.ProviderType).
+			Msg("Target provider has no releases, skipping releases")
+
+		return nil
+	}
+
+	sourceOwner, sourceName := getOwner(sourceProviderCfg), repository.ProjectInfo().OriginalName
+
+	releases, err := published(ctx, source, sourceOwner, sourceName)
+	if err != nil || len(releases) == 0 {
+		return err
+	}
+
+	owner, name := getOwner(targetProviderCfg), repository.ProjectInfo().Name(ctx)
+
+	targetReleases, err := target.Releases(ctx, owner, name)
+	if err != nil {
+		return fmt.Errorf("%w: list target releases: %w", ErrSyncRelease, err)
+	}
+
+	existing := map[string]model.Release{}
+	for _, release := range targetReleases {
+		existing[release.TagName] = release
+	}
+
+	assetDir, err := os.MkdirTemp("", "gitprovidersync-assets-")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncRelease, err)
+	}
+	defer os.RemoveAll(assetDir)
+
+	// GitLab has no pre-releases, comparing them would update such releases on every run
+	comparePrerelease := !strings.EqualFold(targetProviderCfg.ProviderType, config.GITLAB)
+
+	var failures []error
+
+	for _, release := range releases {
+		saved, found := existing[release.TagName]
+
+		if !found || saved.Name != release.Name || saved.Notes != release.Notes || (comparePrerelease && saved.Prerelease != release.Prerelease) {
+			release.ID = saved.ID
+
+			saved, err = target.SaveRelease(ctx, owner, name, release)
+			if err != nil {
+				failures = append(failures, fmt.Errorf("%w: %s: %w", ErrSyncRelease, release.TagName, err))
+
+				continue
+			}
+
+			logger.Debug().Str("tag", release.TagName).Bool("created", !found).Msg("Saved release")
+		}
+
+		for _, asset := range release.Assets {
+			if saved.HasAsset(asset.Name) {
+				continue
+			}
+
+			path := filepath.Join(assetDir, fileName(asset.Name))
+
+			err := source.DownloadReleaseAsset(ctx, sourceOwner, sourceName, asset, path)
+			if err == nil {
+				err = target.UploadReleaseAsset(ctx, owner, name, saved, asset, path)
+			}
+
+			os.Remove(path)
+
+			if err != nil {
+				failures = append(failures, fmt.Errorf("%w: %s: asset %s: %w", ErrSyncRelease, release.TagName, asset.Name, err))
+			}
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+// ExportReleases writes the releases of the source repository to the ReleasesFile in dir,
+// and downloads their assets to a directory per tag, for targets keeping repositories on disk.
+func ExportReleases(ctx context.Context, sourceProviderCfg config.ProviderConfig, source interfaces.GitProvider, repository interfaces.GitRepository, dir string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering ExportReleases")
+	logger.Debug().Str("dir", dir).Msg("ExportReleases")
+
+	if repository.ProjectInfo().IsWiki() {
+		return nil
+	}
+
+	owner, name := getOwner(sourceProviderCfg), repository.ProjectInfo().OriginalName
+
+	releases, err := published(ctx, source, owner, name)
+	if err != nil || len(releases) == 0 {
+		return err
+	}
+
+	for index, release := range releases {
+		releaseDir := filepath.Join(dir, assetsDir, fileName(release.TagName))
+
+		for assetIndex, asset := range release.Assets {
+			if err := os.MkdirAll(releaseDir, 0o755); err != nil {
+				return fmt.Errorf("%w: %w", ErrSyncRelease, err)
+			}
+
+			path := filepath.Join(releaseDir, fileName(asset.Name))
+			if err := source.DownloadReleaseAsset(ctx, owner, name, asset, path); err != nil {
+				return fmt.Errorf("%w: %s: asset %s: %w", ErrSyncRelease, release.TagName, asset.Name, err)
+			}
+
+			releases[index].Assets[assetIndex].File, _ = filepath.Rel(dir, path)
+		}
+	}
+
+	content, err := json.MarshalIndent(releases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncRelease, err)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrSyncRelease, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ReleasesFile), content, 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("%w: %w", ErrSyncRelease, err)
+	}
+
+	return nil
+}
+
+// published returns the releases of a source repository, but drafts, oldest first as they are to be created.
+func published(ctx context.Context, source interfaces.GitProvider, owner, name string) ([]model.Release, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering published")
+
+	releases, err := source.Releases(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: list source releases: %w", ErrSyncRelease, err)
+	}
+
+	releases = slices.DeleteFunc(releases, func(release model.Release) bool { return release.Draft })
+
+	// A release without a creation time sorts first
+	createdAt := func(release model.Release) time.Time {
+		if release.CreatedAt == nil {
+			return time.Time{}
+		}
+
+		return *release.CreatedAt
+	}
+
+	slices.SortStableFunc(releases, func(first, second model.Release) int {
+		return createdAt(first).Compare(createdAt(second))
+	})
+
+	logger.Debug().Str("name", name).Int("releases", len(releases)).Msg("published")
+
+	return releases, nil
+}
+
+// fileName turns a tag or asset name into a file name, as both may contain path separators.
+func fileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(name)
+	if name == "." || name == ".." || name == "" {
+		return "_" + name
+	}
+
+	return name
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncReleases(t *testing.T) {
+	errProvider := errors.New("provider error")
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	asset := model.ReleaseAsset{ID: "7", Name: "tool.tar.gz", URL: "https://example.com/tool.tar.gz"}
+
+	sourceReleases := []model.Release{
+		{ID: "2", TagName: "v2", Name: "Second", CreatedAt: &newer, Assets: []model.ReleaseAsset{asset}},
+		{ID: "1", TagName: "v1", Name: "First", CreatedAt: &older},
+		{ID: "3", TagName: "v3", Name: "Draft", Draft: true},
+	}
+
+	tests := []struct {
+		name         string
+		targetType   string
+		setupMocks   func(source, target *MockGitProvider)
+		wantErr      error
+		wantNoSource bool
+	}{
+		{
+			name:       "creates missing releases oldest first with assets",
+			targetType: config.GITEA,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Releases", mock.Anything, "source", "repo").Return(slices.Clone(sourceReleases), nil)
+				target.On("Releases", mock.Anything, "target", "repo").Return([]model.Release{}, nil)
+
+				first := target.On("SaveRelease", mock.Anything, "target", "repo", mock.MatchedBy(func(release model.Release) bool {
+					return release.TagName == "v1" && release.ID == ""
+				})).Return(model.Release{ID: "11", TagName: "v1"}, nil).Once()
+				target.On("SaveRelease", mock.Anything, "target", "repo", mock.MatchedBy(func(release model.Release) bool {
+					return release.TagName == "v2" && release.ID == ""
+				})).Return(model.Release{ID: "12", TagName: "v2"}, nil).Once().NotBefore(first)
+
+				source.On("DownloadReleaseAsset", mock.Anything, "source", "repo", asset, mock.Anything).Return(nil)
+				target.On("UploadReleaseAsset", mock.Anything, "target", "repo", model.Release{ID: "12", TagName: "v2"}, asset, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:       "leaves unchanged releases and uploaded assets",
+			targetType: config.GITHUB,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Releases", mock.Anything, "source", "repo").Return(slices.Clone(sourceReleases), nil)
+				target.On("Releases", mock.Anything, "target", "repo").Return([]model.Release{
+					{ID: "11", TagName: "v1", Name: "First"},
+					{ID: "12", TagName: "v2", Name: "Second", Assets: []model.ReleaseAsset{{Name: "tool.tar.gz"}}},
+				}, nil)
+			},
+		},
+		{
+			name:       "updates changed release",
+			targetType: config.GITHUB,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Releases", mock.Anything, "source", "repo").Return(slices.Clone(sourceReleases[1:2]), nil)
+				target.On("Releases", mock.Anything, "target", "repo").Return([]model.Release{
+					{ID: "11", TagName: "v1", Name: "Old name"},
+				}, nil)
+				target.On("SaveRelease", mock.Anything, "target", "repo", mock.MatchedBy(func(release model.Release) bool {
+					return release.ID == "11" && release.Name == "First"
+				})).Return(model.Release{ID: "11", TagName: "v1", Name: "First"}, nil)
+			},
+		},
+		{
+			name:       "failing release",
+			targetType: config.GITLAB,
+			setupMocks: func(source, target *MockGitProvider) {
+				source.On("Releases", mock.Anything, "source", "repo").Return(slices.Clone(sourceReleases[1:2]), nil)
+				target.On("Releases", mock.Anything, "target", "repo").Return([]model.Release{}, nil)
+				target.On("SaveRelease", mock.Anything, "target", "repo", mock.Anything).Return(model.Release{}, errProvider)
+			},
+			wantErr: ErrSyncRelease,
+		},
+		{
+			name:         "target without releases",
+			targetType:   config.BITBUCKET,
+			setupMocks:   func(_, _ *MockGitProvider) {},
+			wantNoSource: true,
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			source, target := &MockGitProvider{}, &MockGitProvider{}
+			tabletest.setupMocks(source, target)
+
+			sourceCfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "source"}
+			targetCfg := config.ProviderConfig{ProviderType: tabletest.targetType, Group: "target"}
+			repository := testRepository{projectInfo: model.ProjectInfo{OriginalName: "repo"}}
+
+			err := SyncReleases(testContext(), sourceCfg, source, targetCfg, target, repository)
+			if tabletest.wantErr != nil {
+				require.ErrorIs(err, tabletest.wantErr)
+			} else {
+				require.NoError(err)
+			}
+
+			source.AssertExpectations(t)
+			target.AssertExpectations(t)
+
+			if tabletest.wantNoSource {
+				source.AssertNotCalled(t, "Releases", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestExportReleases(t *testing.T) {
+	require := require.New(t)
+
+	asset := model.ReleaseAsset{ID: "7", Name: "bin/tool", URL: "https://example.com/tool"}
+	source := &MockGitProvider{}
+	source.On("Releases", mock.Anything, "source", "repo").Return([]model.Release{
+		{ID: "1", TagName: "release/v1", Name: "First", Assets: []model.ReleaseAsset{asset}},
+		{ID: "2", TagName: "v2", Draft: true},
+	}, nil)
+	source.On("DownloadReleaseAsset", mock.Anything, "source", "repo", asset, mock.Anything).
+		Run(func(args mock.Arguments) {
+			require.NoError(os.WriteFile(args.String(4), []byte("content"), 0o600))
+		}).Return(nil)
+
+	dir := t.TempDir()
+	sourceCfg := config.ProviderConfig{ProviderType: config.GITHUB, Group: "source"}
+	repository := testRepository{projectInfo: model.ProjectInfo{OriginalName: "repo"}}
+
+	require.NoError(ExportReleases(testContext(), sourceCfg, source, repository, dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, ReleasesFile))
+	require.NoError(err)
+
+	var releases []model.Release
+	require.NoError(json.Unmarshal(content, &releases))
+	require.Len(releases, 1)
+	require.Equal(filepath.Join("assets", "release_v1", "bin_tool"), releases[0].Assets[0].File)
+
+	exported, err := os.ReadFile(filepath.Join(dir, releases[0].Assets[0].File))
+	require.NoError(err)
+	require.Equal("content", string(exported))
+}
@@ -7,30 +7,64 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/workerpool"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
+var ErrCloneRepository = errors.New("failed to clone repository")
+
 // Clone clones multiple repositories based on their metadata.
 // It takes a context, a SourceReader interface for cloning operations,
 // and a slice of RepositoryMetainfo containing information about the repositories to clone.
+// Up to concurrency repositories are cloned at the same time, the result keeps the order of projectinfos.
+// When continuing on errors, a failing repository is recorded in the SyncRunMetainfo and left out of the result.
 // It returns a slice of GitRepository interfaces representing the cloned repositories and any error encountered.
-func Clone(ctx context.Context, reader interfaces.SourceReader, sourceProviderConfig config.ProviderConfig, projectinfos []model.ProjectInfo) ([]interfaces.GitRepository, error) {
+func Clone(ctx context.Context, reader interfaces.SourceReader, sourceProviderConfig config.ProviderConfig, projectinfos []model.ProjectInfo, concurrency int) ([]interfaces.GitRepository, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Clone")
+	logger.Debug().Int("concurrency", concurrency).Int("repositories", len(projectinfos)).Msg("Clone")
+
+	continueOnError := model.CLIOptions(ctx).ContinueOnError || sourceProviderConfig.SyncRun.ContinueOnError
+	repositories := make([]interfaces.GitRepository, len(projectinfos))
 
-	repositories := make([]interfaces.GitRepository, 0, len(projectinfos))
+	err := workerpool.Run(ctx, concurrency, len(projectinfos), func(ctx context.Context, index int) error {
+		metainfo := projectinfos[index]
+		ctx = log.WithRepository(ctx, metainfo.OriginalName)
 
-	for _, metainfo := range projectinfos {
 		option := model.NewCloneOption(ctx, metainfo, true, sourceProviderConfig)
 
 		resultRepo, err := reader.Clone(ctx, option)
+		if err != nil && metainfo.IsWiki() && wikiMissing(err) {
+			// The wiki repository is only created with the first page, an enabled wiki can still have none
+			logger.Warn().Err(err).Str("repository", metainfo.WikiOf).Msg("Wiki has no pages, skipping it")
+
+			return nil
+		}
+
 		if err != nil {
-			return nil, fmt.Errorf("failed to clone repository %s: %w", metainfo.OriginalName, err)
+			err = fmt.Errorf("%w %s: %w", ErrCloneRepository, metainfo.OriginalName, err)
+			if !continueOnError {
+				return err
+			}
+
+			model.AddRepositoryFailure(ctx, model.FailClone, metainfo.OriginalName, err)
+
+			return nil
+		}
+
+		if metainfo.IsWiki() {
+			metainfo.DefaultBranch = headBranch(resultRepo)
 		}
 
 		resultRepo.ProjectMetaInfo = metainfo
@@ -39,10 +73,70 @@ func Clone(ctx context.Context, reader interfaces.SourceReader, sourceProviderCo
 			resultRepo.ProjectMetaInfo.CleanupName = true
 		}
 
-		repositories = append(repositories, resultRepo)
+		repositories[index] = resultRepo
+
+		return nil
+	})
+	if err != nil {
+		for _, repository := range repositories {
+			if repository != nil {
+				repository.Release()
+			}
+		}
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	return slices.DeleteFunc(repositories, func(repository interfaces.GitRepository) bool {
+		return repository == nil
+	}), nil
+}
+
+// wikiMissingOutput is what the git binary prints when the repository of a wiki doesn't exist, on the providers syncing wikis.
+var wikiMissingOutput = []string{"repository not found", "could not be found", "does not appear to be a git repository"}
+
+// wikiMissing reports whether a wiki failed to clone because it has no repository yet, or an empty one.
+func wikiMissing(err error) bool {
+	if errors.Is(err, transport.ErrRepositoryNotFound) || errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+
+	return slices.ContainsFunc(wikiMissingOutput, func(output string) bool { return strings.Contains(message, output) }) ||
+		(strings.Contains(message, "fatal: repository '") && strings.Contains(message, "' not found"))
+}
+
+// WithWikis adds the wiki repositories of the repositories with an enabled wiki after them,
+// when the source has repositories.includewikis set.
+func WithWikis(sourceProviderConfig config.ProviderConfig, projectinfos []model.ProjectInfo) []model.ProjectInfo {
+	if !sourceProviderConfig.Repositories.IncludeWikis {
+		return projectinfos
+	}
+
+	result := slices.Clone(projectinfos)
+
+	for _, projectinfo := range projectinfos {
+		if projectinfo.HasWiki {
+			result = append(result, projectinfo.Wiki())
+		}
+	}
+
+	return result
+}
+
+// headBranch returns the branch HEAD of a cloned repository points to, empty when it can't be read.
+func headBranch(repository model.Repository) string {
+	if repository.GoGitRepository() == nil {
+		return ""
+	}
+
+	head, err := repository.GoGitRepository().Storer.Reference(plumbing.HEAD)
+	if err != nil || head.Type() != plumbing.SymbolicReference {
+		return ""
 	}
 
-	return repositories, nil
+	return head.Target().Short()
 }
 
 // FetchProjectInfo retrieves metadata information for repositories from a Git provider.
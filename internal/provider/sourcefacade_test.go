@@ -6,6 +6,8 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"testing"
 
 	"itiquette/git-provider-sync/internal/model"
@@ -13,6 +15,7 @@ import (
 
 	mocks "itiquette/git-provider-sync/generated/mocks/mockgogit"
 
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -31,7 +34,10 @@ func TestClone(t *testing.T) {
 	tests := []struct {
 		name         string
 		projectinfos []model.ProjectInfo
+		concurrency  int
+		continueErr  bool
 		mockSetup    func(*mocks.SourceReader)
+		wantLen      int
 		wantErr      bool
 	}{
 		{
@@ -43,6 +49,7 @@ func TestClone(t *testing.T) {
 			mockSetup: func(m *mocks.SourceReader) {
 				m.EXPECT().Clone(mock.Anything, mock.Anything).Return(model.Repository{}, nil).Twice()
 			},
+			wantLen: 2,
 			wantErr: false,
 		},
 		{
@@ -55,6 +62,68 @@ func TestClone(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Continue past failing clone",
+			projectinfos: []model.ProjectInfo{
+				{HTTPSURL: "https://github.com/user/repo1.git", OriginalName: "repo1"},
+				{HTTPSURL: "https://github.com/user/broken.git", OriginalName: "broken"},
+				{HTTPSURL: "https://github.com/user/repo3.git", OriginalName: "repo3"},
+			},
+			concurrency: 2,
+			continueErr: true,
+			mockSetup: func(m *mocks.SourceReader) {
+				m.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool { return opt.Name == "broken" })).
+					Return(model.Repository{}, errors.New("clone failed"))
+				m.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool { return opt.Name != "broken" })).
+					Return(model.Repository{}, nil).Twice()
+			},
+			wantLen: 2,
+			wantErr: false,
+		},
+		{
+			name: "Concurrent clone keeps repository order",
+			projectinfos: []model.ProjectInfo{
+				{HTTPSURL: "https://github.com/user/repo1.git", OriginalName: "repo1"},
+				{HTTPSURL: "https://github.com/user/repo2.git", OriginalName: "repo2"},
+				{HTTPSURL: "https://github.com/user/repo3.git", OriginalName: "repo3"},
+			},
+			concurrency: 3,
+			mockSetup: func(m *mocks.SourceReader) {
+				m.EXPECT().Clone(mock.Anything, mock.Anything).Return(model.Repository{}, nil).Times(3)
+			},
+			wantLen: 3,
+			wantErr: false,
+		},
+		{
+			name: "Wiki without pages is skipped",
+			projectinfos: []model.ProjectInfo{
+				{HTTPSURL: "https://github.com/user/repo1.git", OriginalName: "repo1"},
+				{HTTPSURL: "https://github.com/user/repo1.wiki.git", OriginalName: "repo1.wiki", WikiOf: "repo1"},
+			},
+			mockSetup: func(m *mocks.SourceReader) {
+				m.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool { return opt.Name == "repo1" })).
+					Return(model.Repository{}, nil)
+				m.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool { return opt.Name == "repo1.wiki" })).
+					Return(model.Repository{}, fmt.Errorf("clone: %w", transport.ErrRepositoryNotFound))
+			},
+			continueErr: true,
+			wantLen:     1,
+			wantErr:     false,
+		},
+		{
+			name: "Wiki failing to clone fails",
+			projectinfos: []model.ProjectInfo{
+				{HTTPSURL: "https://github.com/user/repo1.git", OriginalName: "repo1"},
+				{HTTPSURL: "https://github.com/user/repo1.wiki.git", OriginalName: "repo1.wiki", WikiOf: "repo1"},
+			},
+			mockSetup: func(m *mocks.SourceReader) {
+				m.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool { return opt.Name == "repo1" })).
+					Return(model.Repository{}, nil).Maybe()
+				m.EXPECT().Clone(mock.Anything, mock.MatchedBy(func(opt model.CloneOption) bool { return opt.Name == "repo1.wiki" })).
+					Return(model.Repository{}, transport.ErrAuthenticationRequired)
+			},
+			wantErr: true,
+		},
 		{
 			name:         "Empty projectinfos list",
 			projectinfos: []model.ProjectInfo{},
@@ -68,13 +137,25 @@ func TestClone(t *testing.T) {
 			mockReader := new(mocks.SourceReader)
 			tabletest.mockSetup(mockReader)
 
-			repos, err := Clone(ctx, mockReader, config.ProviderConfig{}, tabletest.projectinfos)
+			meta := model.NewSyncRunMetainfo(0, "", "", len(tabletest.projectinfos))
+			ctx := context.WithValue(ctx, model.SyncRunMetainfoKey{}, meta)
+			sourceCfg := config.ProviderConfig{SyncRun: config.SyncRunOption{ContinueOnError: tabletest.continueErr}}
+
+			repos, err := Clone(ctx, mockReader, sourceCfg, tabletest.projectinfos, tabletest.concurrency)
 
 			if tabletest.wantErr {
-				require.Error(err)
+				require.ErrorIs(err, ErrCloneRepository)
 			} else {
 				require.NoError(err)
-				require.Len(repos, len(tabletest.projectinfos))
+				require.Len(repos, tabletest.wantLen)
+				require.Equal(len(slices.DeleteFunc(slices.Clone(tabletest.projectinfos), model.ProjectInfo.IsWiki))-tabletest.wantLen, meta.FailureCount())
+
+				names := []string{}
+				for _, repo := range repos {
+					names = append(names, repo.ProjectInfo().OriginalName)
+				}
+
+				require.IsIncreasing(names)
 			}
 
 			mockReader.AssertExpectations(t)
@@ -82,6 +163,36 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestWithWikis(t *testing.T) {
+	projectinfos := []model.ProjectInfo{
+		{OriginalName: "repo1", HTTPSURL: "https://gitlab.com/group/repo1.git", SSHURL: "git@gitlab.com:group/repo1.git", ProjectID: "1", DefaultBranch: "main", HasWiki: true},
+		{OriginalName: "repo2", HTTPSURL: "https://gitlab.com/group/repo2.git", ProjectID: "2", DefaultBranch: "main"},
+	}
+
+	tests := []struct {
+		name         string
+		includeWikis bool
+		want         []model.ProjectInfo
+	}{
+		{"wikis not included", false, projectinfos},
+		{"wikis added after the repositories", true, append(slices.Clone(projectinfos), model.ProjectInfo{
+			OriginalName: "repo1.wiki",
+			HTTPSURL:     "https://gitlab.com/group/repo1.wiki.git",
+			SSHURL:       "git@gitlab.com:group/repo1.wiki.git",
+			WikiOf:       "repo1",
+		})},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			cfg := config.ProviderConfig{Repositories: config.RepositoriesOption{IncludeWikis: tabletest.includeWikis}}
+
+			wikis := WithWikis(cfg, projectinfos)
+			require.Equal(t, tabletest.want, wikis)
+		})
+	}
+}
+
 func TestFetchMetainfo(t *testing.T) {
 	require := require.New(t)
 
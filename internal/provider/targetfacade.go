@@ -8,12 +8,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/provider/azuredevops"
+	"itiquette/git-provider-sync/internal/provider/bitbucketserver"
 	"itiquette/git-provider-sync/internal/provider/stringconvert"
 	a "itiquette/git-provider-sync/internal/target/archive"
 )
@@ -24,8 +27,15 @@ var (
 	ErrCreateRepository     = errors.New("failed to create repository")
 	ErrPushChanges          = errors.New("failed to push changes")
 	ErrDefaultBranch        = errors.New("failed to set default branch")
+	ErrRepositoryLookup     = errors.New("failed to look up repository at target provider")
+	ErrProtectRepository    = errors.New("failed to change repository protection")
+	ErrEnableWiki           = errors.New("failed to enable wiki")
 )
 
+// DescriptionMarker starts the description of target repositories created by Git Provider Sync,
+// unless a project.description was configured. It is followed by the URL of the source repository.
+const DescriptionMarker = "Git Provider Sync cloned this from: "
+
 // Push handles the process of pushing changes to a Git provider.
 // It checks if the repository exists, creates it if necessary, and then pushes the changes.
 //
@@ -37,12 +47,16 @@ var (
 //   - repository: The Git repository interface
 //
 // Returns an error if any step in the process fails.
-func Push(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, writer interfaces.TargetWriter, repository interfaces.GitRepository, sourceProviderConfig config.ProviderConfig) error {
+func Push(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, writer interfaces.TargetWriter, repository interfaces.GitRepository, sourceProviderConfig config.ProviderConfig) (err error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Push")
 	targetProviderCfg.DebugLog(logger).Msg("Push")
 
-	_, _, projectID, err := exists(ctx, targetProviderCfg, provider, sourceProviderConfig.ProviderType, repository)
+	if repository.ProjectInfo().IsWiki() && !isArchiveOrDirectory(targetProviderCfg.ProviderType) {
+		return pushWiki(ctx, targetProviderCfg, writer, repository)
+	}
+
+	_, _, projectID, err := exists(ctx, targetProviderCfg, provider, sourceProviderConfig, repository)
 	if err != nil {
 		return fmt.Errorf("failed to check if the repository exists at provider: %w", err)
 	}
@@ -54,56 +68,115 @@ func Push(ctx context.Context, targetProviderCfg config.ProviderConfig, provider
 		forcePush = true
 	}
 
-	if targetProviderCfg.Project.Disabled {
-		err := provider.UnprotectProject(ctx, repository.ProjectInfo().DefaultBranch, projectID)
+	prune := cliOptions.Prune || targetProviderCfg.SyncRun.Prune
+	owner := getOwner(targetProviderCfg)
+
+	disabled := targetProviderCfg.Project.Disabled && !isArchiveOrDirectory(targetProviderCfg.ProviderType)
+
+	// A disabled project has all branches and tags protected. The protection is lifted for the push,
+	// and put back after it, also when the push fails.
+	if disabled {
+		if err := provider.UnprotectProject(ctx, repository.ProjectInfo().DefaultBranch, projectID); err != nil {
+			return fmt.Errorf("%w: unprotect: %w", ErrProtectRepository, err)
+		}
+
+		defer func() {
+			if protectErr := provider.ProtectProject(ctx, owner, repository.ProjectInfo().DefaultBranch, projectID); protectErr != nil {
+				err = errors.Join(err, fmt.Errorf("%w: protect: %w", ErrProtectRepository, protectErr))
+			}
+		}()
+	}
+
+	pushOption := getPushOption(ctx, targetProviderCfg, repository, forcePush, prune)
+
+	// Protection set up at the target is left as it is, its protected branches and tags are kept when pruning
+	if prune && !disabled && projectID != "" && !isArchiveOrDirectory(targetProviderCfg.ProviderType) {
+		keepRefs, err := provider.ProtectedRefs(ctx, projectID)
 		if err != nil {
-			return fmt.Errorf("failed to protect the repository at provider: %w", err)
+			return fmt.Errorf("%w: protected refs: %w", ErrProtectRepository, err)
 		}
+
+		pushOption.KeepRefs = keepRefs
 	}
 
-	pushOption := getPushOption(ctx, targetProviderCfg, repository, forcePush)
+	// Providers refuse pushes to their own pull request refs, the fetched head refs are pushed to a namespace of their own
+	if reviewRefs := model.ReviewRefsPushSpec(sourceProviderConfig); reviewRefs != "" && !isArchiveOrDirectory(targetProviderCfg.ProviderType) {
+		pushOption.RefSpecs = append(pushOption.RefSpecs, reviewRefs)
+	}
 
 	if err := writer.Push(ctx, repository, pushOption, targetProviderCfg.Git); err != nil {
 		return fmt.Errorf("%w: %w", ErrPushChanges, err)
 	}
 
-	owner := targetProviderCfg.User
-	if targetProviderCfg.IsGroup() {
-		owner = targetProviderCfg.Group
-	}
-
 	if err := provider.SetDefaultBranch(ctx, owner, repository.ProjectInfo().Name(ctx), repository.ProjectInfo().DefaultBranch); err != nil {
 		return fmt.Errorf("%w: %w", ErrDefaultBranch, err)
 	}
 
-	if targetProviderCfg.Project.Disabled {
-		err := provider.ProtectProject(ctx, owner, repository.ProjectInfo().DefaultBranch, projectID)
-		if err != nil {
-			return fmt.Errorf("failed to protect the repository at provider: %w", err)
-		}
+	return nil
+}
+
+// pushWiki pushes a wiki repository to the wiki of its repository at the target, which was synced before it.
+// Archive and directory targets keep wikis next to their repositories, like any other repository.
+func pushWiki(ctx context.Context, targetProviderCfg config.ProviderConfig, writer interfaces.TargetWriter, repository interfaces.GitRepository) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering pushWiki")
+
+	if !SupportsWikis(targetProviderCfg.ProviderType) {
+		logger.Warn().Str("name", repository.ProjectInfo().OriginalName).Str("provider", targetProviderCfg.ProviderType).
+			Msg("Target provider has no wiki repositories, skipping wiki")
+
+		return nil
+	}
+
+	cliOptions := model.CLIOptions(ctx)
+	forcePush := cliOptions.ForcePush || targetProviderCfg.SyncRun.ForcePush
+	prune := cliOptions.Prune || targetProviderCfg.SyncRun.Prune
+
+	// Wikis are served at <repository>.wiki.git, also by providers serving repositories without .git
+	pushOption := getPushOption(ctx, targetProviderCfg, repository, forcePush, prune)
+	pushOption.Target = strings.TrimSuffix(pushOption.Target, ".git") + ".git"
+
+	if err := writer.Push(ctx, repository, pushOption, targetProviderCfg.Git); err != nil {
+		return fmt.Errorf("%w: wiki: %w", ErrPushChanges, err)
 	}
 
 	return nil
 }
 
+// wantsWiki reports whether the wiki of the repository is synced to the target, which then needs the wiki enabled.
+func wantsWiki(targetProviderCfg config.ProviderConfig, sourceProviderConfig config.ProviderConfig, repository interfaces.GitRepository) bool {
+	return sourceProviderConfig.Repositories.IncludeWikis && repository.ProjectInfo().HasWiki && SupportsWikis(targetProviderCfg.ProviderType)
+}
+
+// SupportsWikis reports whether repositories of the provider type have a wiki that is a git repository of its own.
+func SupportsWikis(providerType string) bool {
+	switch strings.ToLower(providerType) {
+	case config.GITHUB, config.GITLAB, config.GITEA:
+		return true
+	default:
+		return false
+	}
+}
+
 // getPushOption determines the appropriate PushOption based on the provider configuration.
 // It handles different scenarios for archive, directory, and remote Git providers.
-func getPushOption(ctx context.Context, providerConfig config.ProviderConfig, repository interfaces.GitRepository, forcePush bool) model.PushOption {
+// Pruning only applies to remote providers, directory targets prune when pulling and archives are written anew.
+func getPushOption(ctx context.Context, providerConfig config.ProviderConfig, repository interfaces.GitRepository, forcePush, prune bool) model.PushOption {
 	switch strings.ToLower(providerConfig.ProviderType) {
 	case config.ARCHIVE:
 		name := repository.ProjectInfo().Name(ctx)
 
 		return model.NewPushOption(a.TargetPath(name, providerConfig.ArchiveTargetDir()), false, false, config.HTTPClientOption{})
 	case config.DIRECTORY:
-		return model.NewPushOption(providerConfig.DirectoryTargetDir(), false, false, config.HTTPClientOption{})
+		return model.NewPushOption(providerConfig.DirectoryTargetDir(), false, false, providerConfig.HTTPClient)
 	default:
-		return model.NewPushOption(toGitURL(ctx, providerConfig, repository), false, forcePush, providerConfig.HTTPClient)
+		return model.NewPushOption(toGitURL(ctx, providerConfig, repository), prune, forcePush, providerConfig.HTTPClient)
 	}
 }
 
 // create attempts to create a new repository on the Git provider.
 // It builds the repository description and uses the provider's Create method.
-func create(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, sourceProviderType string, repository interfaces.GitRepository) (string, error) {
+func create(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, sourceProviderConfig config.ProviderConfig, repository interfaces.GitRepository) (string, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering create")
 	targetProviderCfg.DebugLog(logger).Msg("create")
@@ -113,27 +186,42 @@ func create(ctx context.Context, targetProviderCfg config.ProviderConfig, provid
 		return "", fmt.Errorf("failed to get gpsupstream remote: %w", err)
 	}
 
+	option, err := projectOption(ctx, targetProviderCfg, sourceProviderConfig, repository, gpsUpstreamRemote)
+	if err != nil {
+		return "", err
+	}
+
+	projectID, err := provider.CreateProject(ctx, targetProviderCfg, option)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s. err: %w", ErrCreateRepository, option.RepositoryName, err)
+	}
+
+	return projectID, err //nolint
+}
+
+// projectOption returns the options the repository is created with at the target, described as cloned from the upstream remote.
+func projectOption(ctx context.Context, targetProviderCfg config.ProviderConfig, sourceProviderConfig config.ProviderConfig, repository interfaces.GitRepository, gpsUpstreamRemote model.Remote) (model.CreateProjectOption, error) {
 	description := buildDescription(gpsUpstreamRemote, repository, targetProviderCfg.Project.Description)
 	name := repository.ProjectInfo().Name(ctx)
 
 	visibility := targetProviderCfg.Project.Visibility
 	if targetProviderCfg.Project.Visibility == "" {
-		visibility, err = mapVisibility(sourceProviderType, targetProviderCfg.ProviderType, repository.ProjectInfo().Visibility)
+		var err error
+
+		visibility, err = mapVisibility(sourceProviderConfig.ProviderType, targetProviderCfg.ProviderType, repository.ProjectInfo().Visibility)
 		if err != nil {
-			return "", fmt.Errorf("failed to map visibility: %w", err)
+			return model.CreateProjectOption{}, fmt.Errorf("failed to map visibility: %w", err)
 		}
 	}
 
 	disabled := targetProviderCfg.Project.Disabled
 
 	option := model.NewCreateOption(name, visibility, description, repository.ProjectInfo().DefaultBranch, disabled)
+	option.Wiki = wantsWiki(targetProviderCfg, sourceProviderConfig, repository)
+	option.Releases = sourceProviderConfig.Repositories.IncludeReleases
+	option.Issues = sourceProviderConfig.Repositories.IncludeMetadata
 
-	projectID, err := provider.CreateProject(ctx, targetProviderCfg, option)
-	if err != nil {
-		return "", fmt.Errorf("%w: %s. err: %w", ErrCreateRepository, name, err)
-	}
-
-	return projectID, err //nolint
+	return option, nil
 }
 
 // buildDescription creates a description for the repository, combining the upstream URL and existing description.
@@ -142,7 +230,7 @@ func buildDescription(gpsUpstreamRemote model.Remote, repository interfaces.GitR
 	if userDescription != "" {
 		description = userDescription
 	} else {
-		description = "Git Provider Sync cloned this from: " + gpsUpstreamRemote.URL + ": "
+		description = DescriptionMarker + gpsUpstreamRemote.URL + ": "
 	}
 
 	if repository.ProjectInfo().Description != "" {
@@ -154,7 +242,7 @@ func buildDescription(gpsUpstreamRemote model.Remote, repository interfaces.GitR
 
 // exists checks if a repository already exists on the Git provider.
 // If it doesn't exist, it attempts to create it.
-func exists(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, sourceProviderType string, repository interfaces.GitRepository) (bool, context.Context, string, error) {
+func exists(ctx context.Context, targetProviderCfg config.ProviderConfig, provider interfaces.GitProvider, sourceProviderConfig config.ProviderConfig, repository interfaces.GitRepository) (bool, context.Context, string, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering exists")
 
@@ -165,14 +253,15 @@ func exists(ctx context.Context, targetProviderCfg config.ProviderConfig, provid
 	cliOption := model.CLIOptions(ctx)
 	repositoryName := repository.ProjectInfo().Name(ctx)
 
-	repoExists, projectID := repositoryExists(ctx, targetProviderCfg, provider, repositoryName)
+	repoExists, projectID, err := repositoryExists(ctx, targetProviderCfg, provider, repositoryName)
+	if err != nil {
+		return false, ctx, "", err
+	}
 
 	if !repoExists {
 		logger.Debug().Str("name", repositoryName).Msg("Repository didn't exist at target provider")
 
-		var err error
-
-		projectID, err = create(ctx, targetProviderCfg, provider, sourceProviderType, repository)
+		projectID, err = create(ctx, targetProviderCfg, provider, sourceProviderConfig, repository)
 		if err != nil {
 			return false, ctx, projectID, err
 		}
@@ -180,6 +269,11 @@ func exists(ctx context.Context, targetProviderCfg config.ProviderConfig, provid
 		cliOption.ForcePush = true
 
 		ctx = model.WithCLIOption(ctx, cliOption)
+	} else if wantsWiki(targetProviderCfg, sourceProviderConfig, repository) {
+		// A repository created before its wiki was synced, or with the wiki turned off, has no wiki to push to
+		if err := provider.EnableWiki(ctx, getOwner(targetProviderCfg), repositoryName); err != nil {
+			return false, ctx, projectID, fmt.Errorf("%w: wiki: %w", ErrEnableWiki, err)
+		}
 	}
 
 	logger.Debug().Str("projectID", projectID).Str("domain", targetProviderCfg.GetDomain()).Str("name", repositoryName).Msg("Repository exists at target provider")
@@ -193,22 +287,22 @@ func isArchiveOrDirectory(provider string) bool {
 }
 
 // repositoryExists checks if a repository with the given name exists on the provider.
-func repositoryExists(ctx context.Context, config config.ProviderConfig, provider interfaces.GitProvider, repositoryName string) (bool, string) {
+func repositoryExists(ctx context.Context, config config.ProviderConfig, provider interfaces.GitProvider, repositoryName string) (bool, string, error) {
 	logger := log.Logger(ctx)
-	projectinfos, err := provider.ProjectInfos(ctx, config, false)
+	logger.Trace().Msg("Entering repositoryExists")
 
+	projectinfos, err := provider.ProjectInfos(ctx, config, false)
 	if err != nil {
-		logger.Error().Msgf("failed to get repository meta information. Aborting run. err: %s", err.Error())
-		panic(4)
+		return false, "", fmt.Errorf("%w: %s: %w", ErrRepositoryLookup, repositoryName, err)
 	}
 
 	for _, metainfo := range projectinfos {
 		if strings.EqualFold(repositoryName, metainfo.OriginalName) {
-			return true, metainfo.ProjectID
+			return true, metainfo.ProjectID, nil
 		}
 	}
 
-	return false, ""
+	return false, "", nil
 }
 
 // SetGPSUpstreamRemoteFromOrigin sets the GPSUPSTREAM remote to match the ORIGIN remote.
@@ -261,11 +355,30 @@ func toGitURL(ctx context.Context, config config.ProviderConfig, repository inte
 	return newURL
 }
 
-// getProjectPath constructs the project path based on whether it's a group or user repository.
-func getProjectPath(config config.ProviderConfig, repositoryName string) string {
-	if config.IsGroup() {
-		return fmt.Sprintf("%s/%s", config.Group, repositoryName)
+// getProjectPath constructs the provider specific project path of a group or user repository.
+func getProjectPath(cfg config.ProviderConfig, repositoryName string) string {
+	switch strings.ToLower(cfg.ProviderType) {
+	case config.BITBUCKETSERVER:
+		return fmt.Sprintf("scm/%s/%s.git", strings.ToLower(bitbucketserver.ProjectKey(cfg)), strings.ToLower(repositoryName))
+	case config.AZUREDEVOPS:
+		organization, project, _ := azuredevops.SplitGroup(cfg.Group)
+
+		return fmt.Sprintf("%s/%s/_git/%s", url.PathEscape(organization), url.PathEscape(project), url.PathEscape(repositoryName))
+	default:
+		return fmt.Sprintf("%s/%s", getOwner(cfg), repositoryName)
 	}
+}
 
-	return fmt.Sprintf("%s/%s", config.User, repositoryName)
+// getOwner returns the owner of the target repositories in the form the provider expects it.
+func getOwner(cfg config.ProviderConfig) string {
+	switch strings.ToLower(cfg.ProviderType) {
+	case config.BITBUCKETSERVER:
+		return bitbucketserver.ProjectKey(cfg)
+	default:
+		if cfg.IsGroup() {
+			return cfg.Group
+		}
+
+		return cfg.User
+	}
 }
@@ -10,6 +10,7 @@ import (
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/model"
 	config "itiquette/git-provider-sync/internal/model/configuration"
+	"slices"
 	"testing"
 
 	git "github.com/go-git/go-git/v5"
@@ -59,6 +60,12 @@ func (m *MockGitProvider) ProjectInfos(ctx context.Context, cfg config.ProviderC
 	return args.Get(0).([]model.ProjectInfo), args.Error(1) //nolint
 }
 
+func (m *MockGitProvider) FilterProjectInfos(ctx context.Context, cfg config.ProviderConfig, projectinfos []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	args := m.Called(ctx, cfg, projectinfos)
+
+	return args.Get(0).([]model.ProjectInfo), args.Error(1) //nolint
+}
+
 func (m *MockGitProvider) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
 	args := m.Called(ctx, cfg, opt)
 
@@ -77,12 +84,108 @@ func (m *MockGitProvider) ProtectProject(ctx context.Context, owner string, bran
 	return args.Error(0) //nolint
 }
 
+func (m *MockGitProvider) ProtectedRefs(ctx context.Context, projectID string) ([]string, error) {
+	args := m.Called(ctx, projectID)
+
+	return args.Get(0).([]string), args.Error(1) //nolint
+}
+
 func (m *MockGitProvider) SetDefaultBranch(ctx context.Context, owner string, repo string, branch string) error {
 	args := m.Called(ctx, owner, repo, branch)
 
 	return args.Error(0) //nolint
 }
 
+func (m *MockGitProvider) ArchiveProject(ctx context.Context, owner string, repo string) error {
+	args := m.Called(ctx, owner, repo)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) EnableWiki(ctx context.Context, owner string, repo string) error {
+	args := m.Called(ctx, owner, repo)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) RenameProject(ctx context.Context, owner string, repo string, newName string) error {
+	args := m.Called(ctx, owner, repo, newName)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) DeleteProject(ctx context.Context, owner string, repo string) error {
+	args := m.Called(ctx, owner, repo)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) Releases(ctx context.Context, owner string, repo string) ([]model.Release, error) {
+	args := m.Called(ctx, owner, repo)
+
+	return args.Get(0).([]model.Release), args.Error(1) //nolint
+}
+
+func (m *MockGitProvider) SaveRelease(ctx context.Context, owner string, repo string, release model.Release) (model.Release, error) {
+	args := m.Called(ctx, owner, repo, release)
+
+	return args.Get(0).(model.Release), args.Error(1) //nolint
+}
+
+func (m *MockGitProvider) DownloadReleaseAsset(ctx context.Context, owner string, repo string, asset model.ReleaseAsset, path string) error {
+	args := m.Called(ctx, owner, repo, asset, path)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) UploadReleaseAsset(ctx context.Context, owner string, repo string, release model.Release, asset model.ReleaseAsset, path string) error {
+	args := m.Called(ctx, owner, repo, release, asset, path)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) Metadata(ctx context.Context, owner string, repo string, comments bool) (model.Metadata, error) {
+	args := m.Called(ctx, owner, repo, comments)
+
+	return args.Get(0).(model.Metadata), args.Error(1) //nolint
+}
+
+func (m *MockGitProvider) CreateLabel(ctx context.Context, owner string, repo string, label model.Label) (model.Label, error) {
+	args := m.Called(ctx, owner, repo, label)
+
+	return args.Get(0).(model.Label), args.Error(1) //nolint
+}
+
+func (m *MockGitProvider) CreateMilestone(ctx context.Context, owner string, repo string, milestone model.Milestone) (model.Milestone, error) {
+	args := m.Called(ctx, owner, repo, milestone)
+
+	return args.Get(0).(model.Milestone), args.Error(1) //nolint
+}
+
+func (m *MockGitProvider) CreateIssue(ctx context.Context, owner string, repo string, issue model.Issue) error {
+	args := m.Called(ctx, owner, repo, issue)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) MirrorProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption, projectID string) error {
+	args := m.Called(ctx, cfg, opt, projectID)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) PushMirror(ctx context.Context, owner, name string, opt model.PushMirrorOption) error {
+	args := m.Called(ctx, owner, name, opt)
+
+	return args.Error(0) //nolint
+}
+
+func (m *MockGitProvider) SupportsPushMirror() bool {
+	args := m.Called()
+
+	return args.Bool(0)
+}
+
 type MockTargetWriter struct {
 	mock.Mock
 }
@@ -163,6 +266,166 @@ func TestPush(t *testing.T) {
 			expectedErr:       ErrPushChanges,
 			expectedErrString: "push failed",
 		},
+		{
+			name: "push with review refs",
+			targetConfig: config.ProviderConfig{
+				User: "testuser",
+			},
+			sourceConfig: config.ProviderConfig{
+				ProviderType: "gitlab",
+				Repositories: config.RepositoriesOption{ReviewRefs: "refs/mirror/mr/"},
+			},
+			setupMocks: func(provider *MockGitProvider, writer *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "main",
+					OriginalName:  "test-repo",
+				})
+				provider.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{ProjectID: "123", OriginalName: "test-repo"}}, nil)
+				writer.On("Push", mock.Anything, mock.Anything, mock.MatchedBy(func(opt model.PushOption) bool {
+					return slices.Contains(opt.RefSpecs, "+refs/mirror/mr/*:refs/mirror/mr/*")
+				}), mock.Anything).Return(nil)
+				provider.On("SetDefaultBranch", mock.Anything, "testuser", mock.Anything, "main").Return(nil)
+			},
+		},
+		{
+			name: "existing repository gets its wiki enabled",
+			targetConfig: config.ProviderConfig{
+				ProviderType: "gitea",
+				User:         "testuser",
+			},
+			sourceConfig: config.ProviderConfig{
+				ProviderType: "github",
+				Repositories: config.RepositoriesOption{IncludeWikis: true},
+			},
+			setupMocks: func(provider *MockGitProvider, writer *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "main",
+					OriginalName:  "test-repo",
+					HasWiki:       true,
+				})
+				provider.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{ProjectID: "123", OriginalName: "test-repo"}}, nil)
+				provider.On("EnableWiki", mock.Anything, "testuser", "test-repo").Return(nil)
+				writer.On("Push", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				provider.On("SetDefaultBranch", mock.Anything, "testuser", mock.Anything, "main").Return(nil)
+			},
+		},
+		{
+			name: "repository lookup failure",
+			targetConfig: config.ProviderConfig{
+				User: "testuser",
+			},
+			setupMocks: func(provider *MockGitProvider, _ *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "main",
+					OriginalName:  "test-repo",
+				})
+				provider.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{}, errors.New("service unavailable"))
+			},
+			expectedErr:       ErrRepositoryLookup,
+			expectedErrString: "service unavailable",
+		},
+		{
+			name: "protect failure",
+			targetConfig: config.ProviderConfig{
+				User: "testuser",
+				Project: config.ProjectOption{
+					Disabled: true,
+				},
+			},
+			setupMocks: func(provider *MockGitProvider, writer *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "main",
+					OriginalName:  "test-repo",
+				})
+				provider.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{ProjectID: "123", OriginalName: "test-repo"}}, nil)
+				provider.On("UnprotectProject", mock.Anything, "main", "123").Return(nil)
+				writer.On("Push", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				provider.On("SetDefaultBranch", mock.Anything, "testuser", mock.Anything, "main").Return(nil)
+				provider.On("ProtectProject", mock.Anything, "testuser", "main", "123").Return(errors.New("forbidden"))
+			},
+			expectedErr:       ErrProtectRepository,
+			expectedErrString: "forbidden",
+		},
+		{
+			name: "prune keeps the protected refs and leaves the protection as it is",
+			targetConfig: config.ProviderConfig{
+				User:    "testuser",
+				SyncRun: config.SyncRunOption{Prune: true},
+			},
+			setupMocks: func(provider *MockGitProvider, writer *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "main",
+					OriginalName:  "test-repo",
+				})
+				provider.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{ProjectID: "123", OriginalName: "test-repo"}}, nil)
+				provider.On("ProtectedRefs", mock.Anything, "123").Return([]string{"refs/heads/main", "refs/tags/v*"}, nil)
+				writer.On("Push", mock.Anything, mock.Anything, mock.MatchedBy(func(opt model.PushOption) bool {
+					return opt.Prune && opt.Keeps("refs/heads/main") && opt.Keeps("refs/tags/v1.0") && !opt.Keeps("refs/heads/feature")
+				}), mock.Anything).Return(errors.New("push failed"))
+			},
+			expectedErr:       ErrPushChanges,
+			expectedErrString: "push failed",
+		},
+		{
+			name: "prune with a disabled project lifts its protection and puts it back",
+			targetConfig: config.ProviderConfig{
+				User:    "testuser",
+				Project: config.ProjectOption{Disabled: true},
+				SyncRun: config.SyncRunOption{Prune: true},
+			},
+			setupMocks: func(provider *MockGitProvider, writer *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "main",
+					OriginalName:  "test-repo",
+				})
+				provider.On("ProjectInfos", mock.Anything, mock.Anything, false).
+					Return([]model.ProjectInfo{{ProjectID: "123", OriginalName: "test-repo"}}, nil)
+				provider.On("UnprotectProject", mock.Anything, "main", "123").Return(nil)
+				writer.On("Push", mock.Anything, mock.Anything, mock.MatchedBy(func(opt model.PushOption) bool {
+					return opt.Prune && len(opt.KeepRefs) == 0
+				}), mock.Anything).Return(errors.New("push failed"))
+				provider.On("ProtectProject", mock.Anything, "testuser", "main", "123").Return(nil)
+			},
+			expectedErr:       ErrPushChanges,
+			expectedErrString: "push failed",
+		},
+		{
+			name: "wiki pushed to the wiki of its repository",
+			targetConfig: config.ProviderConfig{
+				ProviderType: "gitlab",
+				Domain:       "gitlab.com",
+				User:         "testuser",
+				Project:      config.ProjectOption{Disabled: true},
+			},
+			setupMocks: func(_ *MockGitProvider, writer *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					DefaultBranch: "master",
+					OriginalName:  "test-repo.wiki",
+					WikiOf:        "test-repo",
+				})
+				writer.On("Push", mock.Anything, mock.Anything, mock.MatchedBy(func(opt model.PushOption) bool {
+					return opt.Target == "https://gitlab.com/testuser/test-repo.wiki.git"
+				}), mock.Anything).Return(nil)
+			},
+		},
+		{
+			name: "wiki skipped at target without wikis",
+			targetConfig: config.ProviderConfig{
+				ProviderType: "bitbucket",
+				User:         "testuser",
+			},
+			setupMocks: func(_ *MockGitProvider, _ *MockTargetWriter, repo *MockRepository) {
+				repo.On("ProjectInfo").Return(model.ProjectInfo{
+					OriginalName: "test-repo.wiki",
+					WikiOf:       "test-repo",
+				})
+			},
+		},
 	}
 
 	for _, tabletest := range tests {
@@ -218,6 +481,11 @@ func (m *MockRepository) Remote(_ string) (model.Remote, error) {
 	panic("unimplemented")
 }
 
+// Release implements interfaces.GitRepository.
+func (m *MockRepository) Release() {
+	panic("unimplemented")
+}
+
 func (m *MockRepository) ProjectInfo() model.ProjectInfo {
 	args := m.Called()
 
@@ -255,6 +523,11 @@ func (r testRepository) ProjectInfo() model.ProjectInfo {
 	return r.projectInfo
 }
 
+// Release implements interfaces.GitRepository.
+func (r testRepository) Release() {
+	panic("unimplemented")
+}
+
 func (r testRepository) Remote(name string) (model.Remote, error) {
 	return r.remoteFunc(name)
 }
@@ -267,6 +540,7 @@ func TestGetPushOption(t *testing.T) {
 		providerConfig config.ProviderConfig
 		repository     testRepository
 		forcePush      bool
+		prune          bool
 		want           model.PushOption
 	}{
 		{
@@ -391,6 +665,64 @@ func TestGetPushOption(t *testing.T) {
 				HTTPClient: config.HTTPClientOption{},
 			},
 		},
+		{
+			name: "prune remote target",
+			providerConfig: config.ProviderConfig{
+				ProviderType: "github",
+				Domain:       "github.com",
+				User:         "testuser",
+			},
+			repository: testRepository{
+				projectInfo: model.ProjectInfo{
+					OriginalName: "test-repo",
+				},
+			},
+			prune: true,
+			want: model.PushOption{
+				Target:     "https://github.com/testuser/test-repo",
+				Prune:      true,
+				HTTPClient: config.HTTPClientOption{},
+			},
+		},
+		{
+			name: "prune is not pushed to directory target",
+			providerConfig: config.ProviderConfig{
+				ProviderType: config.DIRECTORY,
+				Additional: map[string]string{
+					"directorytargetdir": "/target/directory",
+				},
+			},
+			repository: testRepository{
+				projectInfo: model.ProjectInfo{
+					OriginalName: "test-repo",
+				},
+			},
+			prune: true,
+			want: model.PushOption{
+				Target:     "/target/directory",
+				Prune:      false,
+				HTTPClient: config.HTTPClientOption{},
+			},
+		},
+		{
+			name: "directory target keeps its http client settings",
+			providerConfig: config.ProviderConfig{
+				ProviderType: config.DIRECTORY,
+				Additional: map[string]string{
+					"directorytargetdir": "/target/directory",
+				},
+				HTTPClient: config.HTTPClientOption{MaxAttempts: 5},
+			},
+			repository: testRepository{
+				projectInfo: model.ProjectInfo{
+					OriginalName: "test-repo",
+				},
+			},
+			want: model.PushOption{
+				Target:     "/target/directory",
+				HTTPClient: config.HTTPClientOption{MaxAttempts: 5},
+			},
+		},
 		{
 			name: "empty provider type defaults to git URL",
 			providerConfig: config.ProviderConfig{
@@ -415,7 +747,7 @@ func TestGetPushOption(t *testing.T) {
 		t.Run(tabletest.name, func(t *testing.T) {
 			require := require.New(t)
 
-			result := getPushOption(ctx, tabletest.providerConfig, tabletest.repository, tabletest.forcePush)
+			result := getPushOption(ctx, tabletest.providerConfig, tabletest.repository, tabletest.forcePush, tabletest.prune)
 
 			if tabletest.providerConfig.ProviderType == config.ARCHIVE {
 				require.Contains(result.Target, tabletest.want.Target)
@@ -426,6 +758,7 @@ func TestGetPushOption(t *testing.T) {
 			}
 
 			require.Equal(tabletest.want.Force, result.Force, "Force flags should match")
+			require.Equal(tabletest.want.Prune, result.Prune, "Prune flags should match")
 			require.Equal(tabletest.want.HTTPClient, result.HTTPClient, "HTTP client options should match")
 		})
 	}
@@ -456,6 +789,11 @@ func (t testGitProvider) ProtectProject(_ context.Context, _ string, _ string, _
 	panic("unimplemented")
 }
 
+// ProtectedRefs implements interfaces.GitProvider.
+func (t testGitProvider) ProtectedRefs(_ context.Context, _ string) ([]string, error) {
+	panic("unimplemented")
+}
+
 // SetDefaultBranch implements interfaces.GitProvider.
 func (t testGitProvider) SetDefaultBranch(_ context.Context, _ string, _ string, _ string) error {
 	panic("unimplemented")
@@ -466,6 +804,86 @@ func (t testGitProvider) UnprotectProject(_ context.Context, _ string, _ string)
 	panic("unimplemented")
 }
 
+// ArchiveProject implements interfaces.GitProvider.
+func (t testGitProvider) ArchiveProject(_ context.Context, _ string, _ string) error {
+	panic("unimplemented")
+}
+
+// FilterProjectInfos implements interfaces.GitProvider.
+func (t testGitProvider) FilterProjectInfos(_ context.Context, _ config.ProviderConfig, _ []model.ProjectInfo) ([]model.ProjectInfo, error) {
+	panic("unimplemented")
+}
+
+// EnableWiki implements interfaces.GitProvider.
+func (t testGitProvider) EnableWiki(_ context.Context, _ string, _ string) error {
+	panic("unimplemented")
+}
+
+// RenameProject implements interfaces.GitProvider.
+func (t testGitProvider) RenameProject(_ context.Context, _ string, _ string, _ string) error {
+	panic("unimplemented")
+}
+
+// DeleteProject implements interfaces.GitProvider.
+func (t testGitProvider) DeleteProject(_ context.Context, _ string, _ string) error {
+	panic("unimplemented")
+}
+
+// Releases implements interfaces.GitProvider.
+func (t testGitProvider) Releases(_ context.Context, _ string, _ string) ([]model.Release, error) {
+	panic("unimplemented")
+}
+
+// SaveRelease implements interfaces.GitProvider.
+func (t testGitProvider) SaveRelease(_ context.Context, _ string, _ string, _ model.Release) (model.Release, error) {
+	panic("unimplemented")
+}
+
+// DownloadReleaseAsset implements interfaces.GitProvider.
+func (t testGitProvider) DownloadReleaseAsset(_ context.Context, _ string, _ string, _ model.ReleaseAsset, _ string) error {
+	panic("unimplemented")
+}
+
+// UploadReleaseAsset implements interfaces.GitProvider.
+func (t testGitProvider) UploadReleaseAsset(_ context.Context, _ string, _ string, _ model.Release, _ model.ReleaseAsset, _ string) error {
+	panic("unimplemented")
+}
+
+// Metadata implements interfaces.GitProvider.
+func (t testGitProvider) Metadata(_ context.Context, _ string, _ string, _ bool) (model.Metadata, error) {
+	panic("unimplemented")
+}
+
+// CreateLabel implements interfaces.GitProvider.
+func (t testGitProvider) CreateLabel(_ context.Context, _ string, _ string, _ model.Label) (model.Label, error) {
+	panic("unimplemented")
+}
+
+// CreateMilestone implements interfaces.GitProvider.
+func (t testGitProvider) CreateMilestone(_ context.Context, _ string, _ string, _ model.Milestone) (model.Milestone, error) {
+	panic("unimplemented")
+}
+
+// CreateIssue implements interfaces.GitProvider.
+func (t testGitProvider) CreateIssue(_ context.Context, _ string, _ string, _ model.Issue) error {
+	panic("unimplemented")
+}
+
+// MirrorProject implements interfaces.GitProvider.
+func (t testGitProvider) MirrorProject(_ context.Context, _ config.ProviderConfig, _ model.CreateProjectOption, _ string) error {
+	panic("unimplemented")
+}
+
+// PushMirror implements interfaces.GitProvider.
+func (t testGitProvider) PushMirror(_ context.Context, _ string, _ string, _ model.PushMirrorOption) error {
+	panic("unimplemented")
+}
+
+// SupportsPushMirror implements interfaces.GitProvider.
+func (t testGitProvider) SupportsPushMirror() bool {
+	panic("unimplemented")
+}
+
 func (t testGitProvider) CreateProject(ctx context.Context, cfg config.ProviderConfig, opt model.CreateProjectOption) (string, error) {
 	return t.createProjectFunc(ctx, cfg, opt)
 }
@@ -579,7 +997,7 @@ func TestCreate(t *testing.T) {
 		t.Run(tabletest.name, func(t *testing.T) {
 			require := require.New(t)
 
-			projectID, err := create(testContext(), tabletest.targetConfig, tabletest.provider, tabletest.sourceProviderType, tabletest.repository)
+			projectID, err := create(testContext(), tabletest.targetConfig, tabletest.provider, config.ProviderConfig{ProviderType: tabletest.sourceProviderType}, tabletest.repository)
 
 			if tabletest.wantErr {
 				require.Error(err)
@@ -720,6 +1138,33 @@ func TestGetProjectPath(t *testing.T) {
 			repositoryName: "repo",
 			want:           "test-user/repo",
 		},
+		{
+			name: "bitbucket server project path",
+			config: config.ProviderConfig{
+				ProviderType: config.BITBUCKETSERVER,
+				Group:        "PROJ",
+			},
+			repositoryName: "Repo",
+			want:           "scm/proj/repo.git",
+		},
+		{
+			name: "bitbucket server personal path",
+			config: config.ProviderConfig{
+				ProviderType: config.BITBUCKETSERVER,
+				User:         "jdoe",
+			},
+			repositoryName: "repo",
+			want:           "scm/~jdoe/repo.git",
+		},
+		{
+			name: "azure devops path",
+			config: config.ProviderConfig{
+				ProviderType: config.AZUREDEVOPS,
+				Group:        "myorg/My Project",
+			},
+			repositoryName: "repo",
+			want:           "myorg/My%20Project/_git/repo",
+		},
 	}
 
 	for _, tabletest := range tests {
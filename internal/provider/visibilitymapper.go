@@ -22,16 +22,70 @@ func mapVisibility(fromProvider, toProvider, visibility string) (string, error)
 	// Define mapping based on the provided tables
 	mappings := map[string]map[string]map[string]string{
 		"gitlab": {
-			"github": {"public": "public", "internal": "private", "private": "private"},
-			"gitea":  {"public": "public", "internal": "private", "private": "private"},
+			"github":          {"public": "public", "internal": "private", "private": "private"},
+			"gitea":           {"public": "public", "internal": "private", "private": "private"},
+			"bitbucket":       {"public": "public", "internal": "private", "private": "private"},
+			"bitbucketserver": {"public": "public", "internal": "private", "private": "private"},
+			"azuredevops":     {"public": "public", "internal": "private", "private": "private"},
 		},
 		"github": {
-			"gitlab": {"public": "public", "private": "private"},
-			"gitea":  {"public": "public", "private": "private"},
+			"gitlab":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"bitbucket":       {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
+			"azuredevops":     {"public": "public", "private": "private"},
 		},
 		"gitea": {
-			"gitlab": {"public": "public", "private": "private", "limited": "private"},
-			"github": {"public": "public", "private": "private", "limited": "private"},
+			"gitlab":          {"public": "public", "private": "private", "limited": "private"},
+			"github":          {"public": "public", "private": "private", "limited": "private"},
+			"bitbucket":       {"public": "public", "private": "private", "limited": "private"},
+			"bitbucketserver": {"public": "public", "private": "private", "limited": "private"},
+			"azuredevops":     {"public": "public", "private": "private", "limited": "private"},
+		},
+		"bitbucket": {
+			"gitlab":          {"public": "public", "private": "private"},
+			"github":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
+			"azuredevops":     {"public": "public", "private": "private"},
+		},
+		"bitbucketserver": {
+			"gitlab":      {"public": "public", "private": "private"},
+			"github":      {"public": "public", "private": "private"},
+			"gitea":       {"public": "public", "private": "private"},
+			"bitbucket":   {"public": "public", "private": "private"},
+			"azuredevops": {"public": "public", "private": "private"},
+		},
+		"azuredevops": {
+			"gitlab":          {"public": "public", "private": "private"},
+			"github":          {"public": "public", "private": "private"},
+			"gitea":           {"public": "public", "private": "private"},
+			"bitbucket":       {"public": "public", "private": "private"},
+			"bitbucketserver": {"public": "public", "private": "private"},
+		},
+		"gitremote": {
+			"gitlab":          {"private": "private"},
+			"github":          {"private": "private"},
+			"gitea":           {"private": "private"},
+			"bitbucket":       {"private": "private"},
+			"bitbucketserver": {"private": "private"},
+			"azuredevops":     {"private": "private"},
+		},
+		"archive": {
+			"gitlab":          {"private": "private"},
+			"github":          {"private": "private"},
+			"gitea":           {"private": "private"},
+			"bitbucket":       {"private": "private"},
+			"bitbucketserver": {"private": "private"},
+			"azuredevops":     {"private": "private"},
+		},
+		"directory": {
+			"gitlab":          {"private": "private"},
+			"github":          {"private": "private"},
+			"gitea":           {"private": "private"},
+			"bitbucket":       {"private": "private"},
+			"bitbucketserver": {"private": "private"},
+			"azuredevops":     {"private": "private"},
 		},
 	}
 
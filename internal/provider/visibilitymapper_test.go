@@ -45,6 +45,16 @@ func TestMapVisibility(t *testing.T) {
 		{"Gitea Private to GitHub", "gitea", "github", "private", "private", ""},
 		{"Gitea Limited to GitHub", "gitea", "github", "limited", "private", ""},
 
+		{"GitLab Internal to Bitbucket", "gitlab", "bitbucket", "internal", "private", ""},
+		{"Gitea Limited to Bitbucket", "gitea", "bitbucket", "limited", "private", ""},
+		{"Bitbucket Public to GitLab", "bitbucket", "gitlab", "public", "public", ""},
+		{"Bitbucket Private to Gitea", "bitbucket", "gitea", "private", "private", ""},
+		{"Bitbucket Server Public to GitLab", "bitbucketserver", "gitlab", "public", "public", ""},
+		{"GitLab Internal to Bitbucket Server", "gitlab", "bitbucketserver", "internal", "private", ""},
+		{"Azure DevOps Private to Gitea", "azuredevops", "gitea", "private", "private", ""},
+		{"Gitea Limited to Azure DevOps", "gitea", "azuredevops", "limited", "private", ""},
+		{"Git Remote Private to GitLab", "gitremote", "gitlab", "private", "private", ""},
+
 		// Case insensitivity tests
 		{"Case Insensitive Provider", "GitLab", "GitHub", "Public", "public", ""},
 		{"Case Insensitive Visibility", "gitlab", "github", "INTERNAL", "private", ""},
@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package retry retries provider API requests and git transfers that failed for reasons expected to pass,
+// such as rate limits, overloaded servers and dropped connections.
+//
+// Attempts are spaced by exponential backoff with jitter, unless the provider tells how long to wait.
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+	config "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+const (
+	// DefaultMaxAttempts is how often an operation is tried when httpclient.maxattempts is not set.
+	DefaultMaxAttempts = 3
+
+	// DefaultMaxWait is the longest wait for a rate limit when httpclient.maxretrywait is not set.
+	DefaultMaxWait = 5 * time.Minute
+
+	defaultBaseDelay = time.Second
+)
+
+// Policy decides how often an operation is tried, and how long to wait in between.
+type Policy struct {
+	// MaxAttempts is the number of tries, including the first. One or less disables retries.
+	MaxAttempts int
+
+	// MaxWait is the longest wait before a retry. An operation that would have to wait longer fails instead.
+	MaxWait time.Duration
+
+	// BaseDelay is the wait before the first retry, doubled for every retry after it.
+	BaseDelay time.Duration
+}
+
+// NewPolicy returns the policy of the httpclient configuration of a provider.
+// The configuration is validated on load, an unparsable wait falls back to the default.
+func NewPolicy(option config.HTTPClientOption) Policy {
+	policy := Policy{
+		MaxAttempts: DefaultMaxAttempts,
+		MaxWait:     DefaultMaxWait,
+		BaseDelay:   defaultBaseDelay,
+	}
+
+	if option.MaxAttempts > 0 {
+		policy.MaxAttempts = option.MaxAttempts
+	}
+
+	if wait, err := time.ParseDuration(option.MaxRetryWait); err == nil {
+		policy.MaxWait = wait
+	}
+
+	return policy
+}
+
+// Backoff returns the wait before the retry following the given attempt, starting at 1.
+// Up to half of the delay is added as jitter, so concurrent workers don't retry in lockstep.
+func (p Policy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << min(max(attempt-1, 0), 16) //nolint:mnd
+
+	if delay > 0 {
+		delay += rand.N(delay/2 + 1) //nolint:gosec
+	}
+
+	if p.MaxWait > 0 && delay > p.MaxWait {
+		return p.MaxWait
+	}
+
+	return delay
+}
+
+// Do runs the operation until it succeeds, fails with an error that is not retryable, or runs out of attempts.
+// The last error is returned.
+func (p Policy) Do(ctx context.Context, retryable func(error) bool, operation func() error) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering Retry:Do")
+
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt >= p.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		wait := p.Backoff(attempt)
+		logger.Warn().Err(err).Int("attempt", attempt).Dur("wait", wait).Msg("retrying after transient failure")
+
+		if err := Wait(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Wait pauses for the duration, or until the context is done.
+func Wait(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	case <-timer.C:
+		return nil
+	}
+}
+
+// transientMessages are parts of error messages, including git's, of network failures expected to pass.
+var transientMessages = []string{
+	"could not resolve host",
+	"connection timed out",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"early eof",
+	"unexpected eof",
+	"the remote end hung up unexpectedly",
+	"rpc failed",
+	"operation timed out",
+	"tls handshake timeout",
+	"returned error: 429",
+	"returned error: 502",
+	"returned error: 503",
+	"returned error: 504",
+}
+
+// IsTransient reports whether an error of a git transfer is a network failure worth retrying.
+// Authentication failures, missing repositories and rejected pushes are not.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testPolicy(maxAttempts int) Policy {
+	return Policy{MaxAttempts: maxAttempts, MaxWait: time.Second, BaseDelay: time.Millisecond}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		option config.HTTPClientOption
+		want   Policy
+	}{
+		{"defaults", config.HTTPClientOption{}, Policy{MaxAttempts: DefaultMaxAttempts, MaxWait: DefaultMaxWait, BaseDelay: defaultBaseDelay}},
+		{"configured", config.HTTPClientOption{MaxAttempts: 5, MaxRetryWait: "30s"}, Policy{MaxAttempts: 5, MaxWait: 30 * time.Second, BaseDelay: defaultBaseDelay}},
+		{"retries disabled", config.HTTPClientOption{MaxAttempts: 1}, Policy{MaxAttempts: 1, MaxWait: DefaultMaxWait, BaseDelay: defaultBaseDelay}},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, NewPolicy(tabletest.option))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	require := require.New(t)
+	policy := Policy{MaxAttempts: 5, MaxWait: 10 * time.Second, BaseDelay: time.Second}
+
+	for attempt, base := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+		wait := policy.Backoff(attempt)
+		require.GreaterOrEqual(wait, base)
+		require.LessOrEqual(wait, base+base/2)
+	}
+
+	require.Equal(10*time.Second, policy.Backoff(10))
+}
+
+func TestDo(t *testing.T) {
+	errTransient := errors.New("fatal: unable to access: Could not resolve host: example.com")
+	errPermanent := errors.New("fatal: Authentication failed")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"succeeds", []error{nil}, 1, nil},
+		{"succeeds after transient failure", []error{errTransient, nil}, 2, nil},
+		{"gives up after max attempts", []error{errTransient, errTransient, errTransient, nil}, 3, errTransient},
+		{"permanent failure", []error{errPermanent, nil}, 1, errPermanent},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			calls := 0
+
+			err := testPolicy(3).Do(context.Background(), IsTransient, func() error {
+				calls++
+
+				return tabletest.errs[calls-1]
+			})
+
+			require.Equal(t, tabletest.wantCalls, calls)
+			require.ErrorIs(t, err, tabletest.wantErr)
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"hung up", errors.New("fatal: the remote end hung up unexpectedly"), true},
+		{"gateway", errors.New("error: RPC failed; HTTP 502 curl 22 The requested URL returned error: 502"), true},
+		{"unexpected eof", fmt.Errorf("clone: %w", io.ErrUnexpectedEOF), true},
+		{"not found", errors.New("remote: Repository not found."), false},
+		{"canceled", fmt.Errorf("clone: %w", context.Canceled), false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, IsTransient(tabletest.err))
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Unix(), 10)
+	farReset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		headers    []map[string]string
+		statuses   []int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{"ok", http.MethodGet, "/api", nil, []int{200}, 200, 1},
+		{"retry after", http.MethodGet, "/api", []map[string]string{{"Retry-After": "0"}}, []int{429, 200}, 200, 2},
+		{"github rate limit", http.MethodPost, "/api", []map[string]string{{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}}, []int{403, 200}, 200, 2},
+		{"gitlab rate limit", http.MethodGet, "/api", []map[string]string{{"RateLimit-Remaining": "0", "RateLimit-Reset": reset}}, []int{429, 200}, 200, 2},
+		{"rate limit beyond max wait", http.MethodGet, "/api", []map[string]string{{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": farReset}}, []int{403, 200}, 403, 1},
+		{"forbidden", http.MethodGet, "/api", nil, []int{403, 200}, 403, 1},
+		{"gateway error", http.MethodGet, "/api", nil, []int{502, 503, 200}, 200, 3},
+		{"gateway error gives up", http.MethodGet, "/api", nil, []int{502, 502, 502, 200}, 502, 3},
+		{"gateway error on post", http.MethodPost, "/api", nil, []int{502, 200}, 502, 1},
+		{"gateway error on git push", http.MethodPost, "/repo.git/git-receive-pack", nil, []int{502, 200}, 200, 2},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+
+			var calls atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+				call := int(calls.Add(1)) - 1
+
+				body, _ := io.ReadAll(req.Body)
+				if req.Method == http.MethodPost {
+					require.Equal("payload", string(body))
+				}
+
+				if call < len(tabletest.headers) {
+					for key, value := range tabletest.headers[call] {
+						writer.Header().Set(key, value)
+					}
+				}
+
+				writer.WriteHeader(tabletest.statuses[call])
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: &Transport{Policy: testPolicy(3), Timeout: time.Second}}
+
+			req, err := http.NewRequestWithContext(context.Background(), tabletest.method, server.URL+tabletest.path, strings.NewReader("payload"))
+			require.NoError(err)
+
+			resp, err := client.Do(req)
+			require.NoError(err)
+			resp.Body.Close()
+
+			require.Equal(tabletest.wantStatus, resp.StatusCode)
+			require.Equal(tabletest.wantCalls, calls.Load())
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		value     string
+		want      time.Duration
+		wantFound bool
+	}{
+		{"seconds", "120", 2 * time.Minute, true},
+		{"date", now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{"past date", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"missing", "", 0, false},
+		{"invalid", "soon", 0, false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			header := http.Header{}
+			if tabletest.value != "" {
+				header.Set("Retry-After", tabletest.value)
+			}
+
+			wait, found := retryAfter(header, now)
+			require.Equal(t, tabletest.wantFound, found)
+			require.Equal(t, tabletest.want, wait)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package retry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+// maxDrain is how much of a response body is read before retrying, so its connection can be reused.
+const maxDrain = 4096
+
+// Transport is an http.RoundTripper retrying requests that were rate limited, or failed with
+// a gateway error or a network failure, by its Policy.
+//
+// How long to wait for a rate limit is taken from the Retry-After header, or the reset time of
+// the X-RateLimit-* headers (GitHub, Gitea) or RateLimit-* headers (GitLab), once none remain.
+// Otherwise the policy backoff applies. A wait longer than the policy allows returns the response as is.
+//
+// Rate limited requests are always retried. Other failures only when the request is idempotent,
+// or a git smart HTTP transfer. A request body must be replayable through GetBody to be sent again.
+type Transport struct {
+	// Base sends each attempt, http.DefaultTransport when nil.
+	Base http.RoundTripper
+
+	Policy Policy
+
+	// Timeout limits each attempt, up to reading the response body. Zero means no limit.
+	// Unlike http.Client.Timeout, waits between attempts don't count.
+	Timeout time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := log.Logger(req.Context())
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, cancel, err := t.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base().RoundTrip(attemptReq)
+
+		wait, retryable := t.retryWait(req, resp, err, attempt)
+		if !retryable || attempt >= t.Policy.MaxAttempts {
+			if err != nil {
+				cancel()
+
+				return nil, err //nolint:wrapcheck
+			}
+
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+			return resp, nil
+		}
+
+		event := logger.Warn().Str("url", req.URL.Redacted()).Int("attempt", attempt).Dur("wait", wait)
+		if err != nil {
+			event = event.Err(err)
+		} else {
+			event = event.Int("status", resp.StatusCode)
+
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrain))
+			resp.Body.Close()
+		}
+
+		cancel()
+		event.Msg("retrying request")
+
+		if err := Wait(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attemptRequest prepares the request for an attempt, with a fresh body for retries and the attempt timeout.
+func (t *Transport) attemptRequest(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+	}
+
+	attemptReq := req.WithContext(ctx)
+
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+
+			return nil, nil, err //nolint:wrapcheck
+		}
+
+		attemptReq.Body = body
+	}
+
+	return attemptReq, cancel, nil
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+
+	return t.Base
+}
+
+// retryWait reports whether the outcome of an attempt is worth retrying, and how long to wait first.
+func (t *Transport) retryWait(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if !replayable(req) || req.Context().Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return t.Policy.Backoff(attempt), idempotent(req)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusForbidden:
+		if resp.StatusCode == http.StatusForbidden && !rateLimited(resp.Header) {
+			return 0, false
+		}
+
+		wait, found := rateLimitWait(resp.Header, time.Now())
+		if !found {
+			wait = t.Policy.Backoff(attempt)
+		}
+
+		if wait > t.Policy.MaxWait {
+			log.Logger(req.Context()).Warn().Dur("wait", wait).Dur("maxWait", t.Policy.MaxWait).
+				Msg("rate limited for longer than httpclient.maxretrywait, not retrying")
+
+			return 0, false
+		}
+
+		return wait, true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		wait, found := retryAfter(resp.Header, time.Now())
+		if !found || wait > t.Policy.MaxWait {
+			wait = t.Policy.Backoff(attempt)
+		}
+
+		return wait, idempotent(req)
+	default:
+		return 0, false
+	}
+}
+
+// replayable reports whether the request can be sent again, which needs a way to get its body anew.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// idempotent reports whether sending the request twice has the same effect as sending it once.
+// Git smart HTTP transfers are POST requests, but a push either applies in full or not at all.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return strings.HasSuffix(req.URL.Path, "/git-upload-pack") || strings.HasSuffix(req.URL.Path, "/git-receive-pack")
+}
+
+// rateLimited reports whether a 403 response is a rate limit rather than missing permissions.
+func rateLimited(header http.Header) bool {
+	return header.Get("Retry-After") != "" ||
+		header.Get("X-RateLimit-Remaining") == "0" ||
+		header.Get("RateLimit-Remaining") == "0"
+}
+
+// rateLimitWait returns how long the provider asks to wait before the next request.
+func rateLimitWait(header http.Header, now time.Time) (time.Duration, bool) {
+	if wait, found := retryAfter(header, now); found {
+		return wait, true
+	}
+
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if header.Get(prefix+"Remaining") != "0" {
+			continue
+		}
+
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+	}
+
+	return 0, false
+}
+
+// retryAfter reads the Retry-After header, given in seconds or as an HTTP date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+
+	return 0, false
+}
+
+// cancelBody releases the attempt timeout of a response once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err //nolint:wrapcheck
+}
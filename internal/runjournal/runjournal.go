@@ -0,0 +1,256 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package runjournal records the progress of a sync run, so an interrupted run can be resumed
+// with the steps it did not complete.
+//
+// A step is a configuration, a target of a configuration, or a repository synced to a target,
+// identified by the Key of its names. The journal is written to disk after each completed step.
+package runjournal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+var (
+	ErrNoJournal       = errors.New("no interrupted run to resume")
+	ErrJournalMismatch = errors.New("the interrupted run used a different configuration")
+	ErrLoadJournal     = errors.New("failed to load run journal")
+	ErrSaveJournal     = errors.New("failed to save run journal")
+)
+
+// version is the format version of the journal file.
+const version = 1
+
+// JournalKey is used as a key for context values.
+type JournalKey struct{}
+
+// Journal is the progress of a sync run.
+// All methods are safe for concurrent use, and do nothing on a nil Journal.
+type Journal struct {
+	mu   sync.Mutex
+	path string
+	run  journalFile
+}
+
+type journalFile struct {
+	Version     int                  `json:"version"`
+	Fingerprint string               `json:"fingerprint"`
+	TmpDir      string               `json:"tmpDir"`
+	Started     time.Time            `json:"started"`
+	Finished    bool                 `json:"finished"`
+	Completed   map[string]time.Time `json:"completed"`
+}
+
+// Key returns the key of a step from the names of the configuration, and optionally target and repository.
+func Key(names ...string) string {
+	return strings.Join(names, "/")
+}
+
+// Fingerprint identifies a configuration, so a run is only resumed with the configuration it was started with.
+// Only the hash is stored, never the configuration itself.
+func Fingerprint(configuration any) (string, error) {
+	content, err := json.Marshal(configuration)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Start begins a new journal at path, replacing any previous one.
+func Start(ctx context.Context, path, fingerprint, tmpDir string) (*Journal, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering RunJournal:Start")
+	logger.Debug().Str("path", path).Str("tmpDir", tmpDir).Msg("RunJournal:Start")
+
+	journal := &Journal{
+		path: path,
+		run: journalFile{
+			Version:     version,
+			Fingerprint: fingerprint,
+			TmpDir:      tmpDir,
+			Started:     time.Now().UTC(),
+			Completed:   map[string]time.Time{},
+		},
+	}
+
+	if err := journal.save(); err != nil {
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+// Resume loads the journal of an interrupted run at path.
+// It fails with ErrNoJournal if there is none, or the last run finished,
+// and with ErrJournalMismatch if it was started with another configuration.
+func Resume(ctx context.Context, path, fingerprint string) (*Journal, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering RunJournal:Resume")
+	logger.Debug().Str("path", path).Msg("RunJournal:Resume")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNoJournal
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrLoadJournal, err)
+	}
+
+	var run journalFile
+	if err := json.Unmarshal(content, &run); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrLoadJournal, path, err)
+	}
+
+	if run.Version != version {
+		return nil, fmt.Errorf("%w: %s: unsupported version %d", ErrLoadJournal, path, run.Version)
+	}
+
+	if run.Finished {
+		return nil, ErrNoJournal
+	}
+
+	if run.Fingerprint != fingerprint {
+		return nil, ErrJournalMismatch
+	}
+
+	if run.Completed == nil {
+		run.Completed = map[string]time.Time{}
+	}
+
+	return &Journal{path: path, run: run}, nil
+}
+
+// TmpDir returns the temporary directory of the run.
+func (j *Journal) TmpDir() string {
+	if j == nil {
+		return ""
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.run.TmpDir
+}
+
+// SetTmpDir replaces the temporary directory of the run, when the one of the interrupted run is gone.
+func (j *Journal) SetTmpDir(tmpDir string) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.run.TmpDir = tmpDir
+
+	return j.save()
+}
+
+// Completed reports whether the step with the key was completed.
+func (j *Journal) Completed(key string) bool {
+	if j == nil {
+		return false
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, found := j.run.Completed[key]
+
+	return found
+}
+
+// Complete records the step with the key as completed, and writes the journal.
+func (j *Journal) Complete(key string) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.run.Completed[key] = time.Now().UTC()
+
+	return j.save()
+}
+
+// Finish marks the run as finished, so there is nothing left to resume.
+func (j *Journal) Finish() error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.run.Finished = true
+
+	return j.save()
+}
+
+// save writes the journal, replacing the file in one step. The caller holds the lock.
+func (j *Journal) save() error {
+	content, err := json.MarshalIndent(j.run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	dir := filepath.Dir(j.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(j.path)+".*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+
+		return fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), j.path); err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveJournal, err)
+	}
+
+	return nil
+}
+
+// FromContext returns the journal of the run in the context, or nil when the run is not journaled.
+func FromContext(ctx context.Context) *Journal {
+	journal, _ := ctx.Value(JournalKey{}).(*Journal)
+
+	return journal
+}
+
+// WithJournal returns a context carrying the journal of the run.
+func WithJournal(ctx context.Context, journal *Journal) context.Context {
+	return context.WithValue(ctx, JournalKey{}, journal)
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package runjournal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalResume(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "journal", "journal.json")
+
+	_, err := Resume(ctx, path, "fingerprint")
+	require.ErrorIs(err, ErrNoJournal)
+
+	journal, err := Start(ctx, path, "fingerprint", "/tmp/gitprovidersync.1")
+	require.NoError(err)
+	require.NoError(journal.Complete(Key("config", "target", "repo")))
+	require.NoError(journal.Complete(Key("config", "other")))
+
+	_, err = Resume(ctx, path, "changed")
+	require.ErrorIs(err, ErrJournalMismatch)
+
+	resumed, err := Resume(ctx, path, "fingerprint")
+	require.NoError(err)
+	require.Equal("/tmp/gitprovidersync.1", resumed.TmpDir())
+	require.True(resumed.Completed("config/target/repo"))
+	require.True(resumed.Completed("config/other"))
+	require.False(resumed.Completed("config/target"))
+
+	require.NoError(resumed.SetTmpDir("/tmp/gitprovidersync.2"))
+	require.NoError(resumed.Finish())
+
+	_, err = Resume(ctx, path, "fingerprint")
+	require.ErrorIs(err, ErrNoJournal)
+}
+
+func TestNilJournal(t *testing.T) {
+	require := require.New(t)
+
+	var journal *Journal
+
+	require.Empty(journal.TmpDir())
+	require.False(journal.Completed("config"))
+	require.NoError(journal.Complete("config"))
+	require.NoError(journal.SetTmpDir("/tmp"))
+	require.NoError(journal.Finish())
+	require.Nil(FromContext(context.Background()))
+}
+
+func TestFingerprint(t *testing.T) {
+	require := require.New(t)
+
+	type config struct {
+		Targets map[string]string
+	}
+
+	first, err := Fingerprint(config{Targets: map[string]string{"a": "1", "b": "2"}})
+	require.NoError(err)
+
+	same, err := Fingerprint(config{Targets: map[string]string{"b": "2", "a": "1"}})
+	require.NoError(err)
+	require.Equal(first, same)
+
+	other, err := Fingerprint(config{Targets: map[string]string{"a": "1"}})
+	require.NoError(err)
+	require.NotEqual(first, other)
+}
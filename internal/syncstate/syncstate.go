@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package syncstate persists what is known about each repository after it was last synced to a target,
+// so unchanged repositories can be skipped in the next run.
+//
+// The state is kept in a JSON file, keyed by configuration, target and repository name.
+package syncstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+var (
+	ErrLoadState = errors.New("failed to load sync state")
+	ErrSaveState = errors.New("failed to save sync state")
+)
+
+// ResultSynced is the result of a repository that was pushed to the target without errors.
+// Any other result is the failure category of the last attempt.
+const ResultSynced = "synced"
+
+// version is the format version of the state file.
+const version = 1
+
+// RepositoryState is the state of a repository as of its last sync to a target.
+type RepositoryState struct {
+	// Refs are the source references at the time of the sync, as listed by git ls-remote.
+	Refs map[string]string `json:"refs,omitempty"`
+
+	// LastActivityAt is the last activity time the source provider reported at the time of the sync.
+	LastActivityAt *time.Time `json:"lastActivityAt,omitempty"`
+
+	// LastSync is when the repository was last synced to the target.
+	LastSync time.Time `json:"lastSync"`
+
+	// Result is ResultSynced, or the failure category the sync failed with.
+	Result string `json:"result"`
+
+	// SourceID is the project ID the source provider reported, to recognize the repository after it was renamed there.
+	SourceID string `json:"sourceId,omitempty"`
+}
+
+// Unchanged reports whether the repository was synced and the source has not changed since.
+// The references are compared when they could be listed, otherwise the last activity time is.
+// Without either nothing is known, and the repository is taken as changed.
+func (r RepositoryState) Unchanged(refs map[string]string, lastActivityAt *time.Time) bool {
+	if r.Result != ResultSynced {
+		return false
+	}
+
+	if refs != nil {
+		return r.Refs != nil && maps.Equal(r.Refs, refs)
+	}
+
+	return lastActivityAt != nil && r.LastActivityAt != nil && r.LastActivityAt.Equal(*lastActivityAt)
+}
+
+// Store holds the state of all repositories in a state file.
+// It is safe for concurrent use.
+type Store struct {
+	mu           sync.Mutex
+	path         string
+	repositories map[string]RepositoryState
+}
+
+type stateFile struct {
+	Version      int                        `json:"version"`
+	Repositories map[string]RepositoryState `json:"repositories"`
+}
+
+// Key returns the key of a repository synced from a configuration to a target.
+func Key(configuration, target, repository string) string {
+	return strings.Join([]string{configuration, target, repository}, "/")
+}
+
+// Load reads the state file at path. A missing file gives an empty store, created on Save.
+func Load(ctx context.Context, path string) (*Store, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering SyncState:Load")
+	logger.Debug().Str("path", path).Msg("SyncState:Load")
+
+	store := &Store{path: path, repositories: map[string]RepositoryState{}}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrLoadState, err)
+	}
+
+	var state stateFile
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrLoadState, path, err)
+	}
+
+	if state.Version != version {
+		return nil, fmt.Errorf("%w: %s: unsupported version %d", ErrLoadState, path, state.Version)
+	}
+
+	if state.Repositories != nil {
+		store.repositories = state.Repositories
+	}
+
+	return store, nil
+}
+
+// Get returns the state of the repository with the key, if there is one.
+func (s *Store) Get(key string) (RepositoryState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, found := s.repositories[key]
+
+	return state, found
+}
+
+// Set replaces the state of the repository with the key.
+func (s *Store) Set(key string, state RepositoryState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.repositories[key] = state
+}
+
+// Delete removes the state of the repository with the key.
+func (s *Store) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.repositories, key)
+}
+
+// Repositories returns the states of all repositories synced from a configuration to a target, by repository name.
+func (s *Store) Repositories(configuration, target string) map[string]RepositoryState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := Key(configuration, target, "")
+	repositories := map[string]RepositoryState{}
+
+	for key, state := range s.repositories {
+		if repository, found := strings.CutPrefix(key, prefix); found {
+			repositories[repository] = state
+		}
+	}
+
+	return repositories
+}
+
+// Save writes the store to its state file.
+// The file is replaced in one step, so an interrupted save leaves the previous state in place.
+func (s *Store) Save(ctx context.Context) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering SyncState:Save")
+	logger.Debug().Str("path", s.path).Msg("SyncState:Save")
+
+	s.mu.Lock()
+	content, err := json.MarshalIndent(stateFile{Version: version, Repositories: s.repositories}, "", "  ")
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveState, err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveState, err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveState, err)
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+
+		return fmt.Errorf("%w: %w", ErrSaveState, err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveState, err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.path); err != nil {
+		return fmt.Errorf("%w: %w", ErrSaveState, err)
+	}
+
+	return nil
+}
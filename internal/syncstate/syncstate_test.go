@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package syncstate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnchanged(t *testing.T) {
+	activity := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	later := activity.Add(time.Hour)
+	refs := map[string]string{"HEAD": "ref: refs/heads/main", "refs/heads/main": "abc"}
+
+	tests := []struct {
+		name           string
+		state          RepositoryState
+		refs           map[string]string
+		lastActivityAt *time.Time
+		want           bool
+	}{
+		{"same refs", RepositoryState{Refs: refs, Result: ResultSynced}, map[string]string{"HEAD": "ref: refs/heads/main", "refs/heads/main": "abc"}, nil, true},
+		{"moved ref", RepositoryState{Refs: refs, Result: ResultSynced}, map[string]string{"HEAD": "ref: refs/heads/main", "refs/heads/main": "def"}, nil, false},
+		{"new ref", RepositoryState{Refs: refs, Result: ResultSynced}, map[string]string{"HEAD": "ref: refs/heads/main", "refs/heads/main": "abc", "refs/tags/v1": "abc"}, nil, false},
+		{"failed last time", RepositoryState{Refs: refs, Result: "push"}, refs, nil, false},
+		{"refs not stored", RepositoryState{LastActivityAt: &activity, Result: ResultSynced}, refs, &activity, false},
+		{"same activity", RepositoryState{LastActivityAt: &activity, Result: ResultSynced}, nil, &activity, true},
+		{"later activity", RepositoryState{LastActivityAt: &activity, Result: ResultSynced}, nil, &later, false},
+		{"nothing known", RepositoryState{Result: ResultSynced}, nil, nil, false},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, tabletest.state.Unchanged(tabletest.refs, tabletest.lastActivityAt))
+		})
+	}
+}
+
+func TestStoreSaveAndLoad(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "state", "gitprovidersync.json")
+
+	store, err := Load(ctx, path)
+	require.NoError(err)
+
+	_, found := store.Get(Key("config", "target", "repo"))
+	require.False(found)
+
+	state := RepositoryState{
+		Refs:     map[string]string{"refs/heads/main": "abc"},
+		LastSync: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Result:   ResultSynced,
+	}
+	store.Set(Key("config", "target", "repo"), state)
+	require.NoError(store.Save(ctx))
+
+	loaded, err := Load(ctx, path)
+	require.NoError(err)
+
+	got, found := loaded.Get("config/target/repo")
+	require.True(found)
+	require.Equal(state, got)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(err)
+	require.Len(entries, 1, "no temporary files should be left behind")
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not json", "{"},
+		{"unknown version", `{"version": 99, "repositories": {}}`},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			require.NoError(t, os.WriteFile(path, []byte(tabletest.content), 0o600))
+
+			_, err := Load(context.Background(), path)
+			require.ErrorIs(t, err, ErrLoadState)
+		})
+	}
+}
+
+func TestStoreRepositories(t *testing.T) {
+	require := require.New(t)
+
+	store, err := Load(context.Background(), filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(err)
+
+	store.Set(Key("config", "target", "repo"), RepositoryState{Result: ResultSynced, SourceID: "1"})
+	store.Set(Key("config", "target", "other"), RepositoryState{Result: ResultSynced, SourceID: "2"})
+	store.Set(Key("config", "target2", "repo"), RepositoryState{Result: ResultSynced, SourceID: "1"})
+	store.Set(Key("config2", "target", "repo"), RepositoryState{Result: ResultSynced, SourceID: "3"})
+
+	require.Equal(map[string]RepositoryState{
+		"repo":  {Result: ResultSynced, SourceID: "1"},
+		"other": {Result: ResultSynced, SourceID: "2"},
+	}, store.Repositories("config", "target"))
+
+	store.Delete(Key("config", "target", "repo"))
+	require.Len(store.Repositories("config", "target"), 1)
+}
@@ -9,6 +9,8 @@ import "errors"
 var (
 	ErrArchiveCompression = errors.New("failed to compress archive")
 	ErrArchiveCreation    = errors.New("failed to create archive file")
+	ErrArchiveExtraction  = errors.New("failed to extract archive file")
+	ErrIllegalArchivePath = errors.New("archive entry outside of target directory")
 	ErrDirectoryCreation  = errors.New("failed to create target directory")
 	ErrNoFilesToArchive   = errors.New("no files found to archive")
 	ErrRepoInitialization = errors.New("failed to initialize repository")
@@ -56,5 +56,9 @@ func (h *GitHandler) configureRepository(ctx context.Context, repo interfaces.Gi
 		return fmt.Errorf("failed to set default branch: %w", err)
 	}
 
+	if err := h.client.Ops.SetDescription(ctx, path, repo.ProjectInfo().Description); err != nil {
+		return fmt.Errorf("failed to set description: %w", err)
+	}
+
 	return nil
 }
@@ -7,13 +7,19 @@ package archive
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/mholt/archives"
 )
 
+// archiveNameRegex matches the file names created by TargetPath, capturing the repository name and timestamp.
+var archiveNameRegex = regexp.MustCompile(`^(.+)(_\d{8}_\d{6}_\d+)\.tar\.gz$`)
+
 type Handler struct{}
 
 func NewHandler() *Handler {
@@ -47,6 +53,75 @@ func TargetPath(name, targetDir string) string {
 	return filepath.Join(targetDir, tarArchive)
 }
 
+// ArchiveName returns the repository name and timestamp of an archive file created by TargetPath.
+func ArchiveName(filename string) (string, string, bool) {
+	matches := archiveNameRegex.FindStringSubmatch(filepath.Base(filename))
+	if matches == nil {
+		return "", "", false
+	}
+
+	return matches[1], matches[2], true
+}
+
+// ExtractArchive extracts a tar.gz archive created by CreateArchive into targetDir.
+func (h *Handler) ExtractArchive(ctx context.Context, archivePath, targetDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrArchiveExtraction, archivePath, err)
+	}
+	defer file.Close()
+
+	format := archives.CompressedArchive{
+		Compression: archives.Gz{},
+		Extraction:  archives.Tar{},
+	}
+
+	if err := format.Extract(ctx, file, func(_ context.Context, info archives.FileInfo) error {
+		return extractFile(targetDir, info)
+	}); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrArchiveExtraction, archivePath, err)
+	}
+
+	return nil
+}
+
+func extractFile(targetDir string, info archives.FileInfo) error {
+	targetPath := filepath.Join(targetDir, filepath.Clean(info.NameInArchive))
+	if !strings.HasPrefix(targetPath, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+		return fmt.Errorf("%w: %s", ErrIllegalArchivePath, info.NameInArchive)
+	}
+
+	switch {
+	case info.IsDir():
+		return os.MkdirAll(targetPath, 0o755) //nolint:wrapcheck
+	case !info.Mode().IsRegular():
+		// A mirrored repository has no use for links or devices
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0o755); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	source, err := info.Open()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer target.Close()
+
+	if _, err := io.Copy(target, source); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
+
 func (h *Handler) mapFilesToArchive(ctx context.Context, sourceDir, targetName string) ([]archives.FileInfo, error) {
 	files, err := archives.FilesFromDisk(ctx, nil, map[string]string{
 		sourceDir: targetName,
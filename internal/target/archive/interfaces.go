@@ -23,4 +23,5 @@ type StorageHandlerer interface {
 
 type Handlerer interface {
 	CreateArchive(ctx context.Context, sourceDir, targetPath, name string) error
+	ExtractArchive(ctx context.Context, archivePath, targetDir string) error
 }
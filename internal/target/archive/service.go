@@ -14,10 +14,14 @@ import (
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 )
 
+// Exporter writes more of a repository than its git data, like its releases, into the directory archived with it.
+type Exporter func(ctx context.Context, dir string) error
+
 type Service struct {
-	git      GitHandler
-	storage  StorageHandler
-	archiver Handlerer
+	git       GitHandler
+	storage   StorageHandler
+	archiver  Handlerer
+	exporters []Exporter
 }
 
 func NewService(git GitHandler, storage StorageHandler, archiver Handlerer) *Service {
@@ -28,6 +32,13 @@ func NewService(git GitHandler, storage StorageHandler, archiver Handlerer) *Ser
 	}
 }
 
+// WithExporters adds exporters run on each archived repository, before it is archived.
+func (s *Service) WithExporters(exporters ...Exporter) *Service {
+	s.exporters = append(s.exporters, exporters...)
+
+	return s
+}
+
 func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption, _ gpsconfig.GitOption) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Archive:Push")
@@ -42,5 +53,11 @@ func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt m
 		return fmt.Errorf("failed to initialize target repository: %w", err)
 	}
 
+	for _, export := range s.exporters {
+		if err := export(ctx, storagePath); err != nil {
+			return fmt.Errorf("failed to export to target repository: %w", err)
+		}
+	}
+
 	return s.archiver.CreateArchive(ctx, storagePath, opt.Target, repo.ProjectInfo().Name(ctx)) //nolint
 }
@@ -12,6 +12,7 @@ var (
 	ErrRepoInitialization = errors.New("failed to initialize repository")
 	ErrPushRepository     = errors.New("failed to push repository")
 	ErrPullRepository     = errors.New("failed to pull repository")
+	ErrPruneRepository    = errors.New("failed to prune repository")
 )
 
 // package main
@@ -30,7 +31,7 @@ var (
 // 	}
 
 // 	// Use for pull
-// 	err = dirService.Pull(ctx, sourceCfg, targetPath, repo)
+// 	err = dirService.Pull(ctx, sourceCfg, targetCfg, repo, prune)
 // 	if err != nil {
 // 		log.Error().Err(err).Msg("Failed to pull from directory")
 // 		return
@@ -10,6 +10,7 @@ import (
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/model"
 	"itiquette/git-provider-sync/internal/target/gitlib"
+	"itiquette/git-provider-sync/internal/target/lfs"
 
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 
@@ -24,14 +25,14 @@ func NewGitHandler(client *gitlib.Service) GitHandler {
 	return GitHandler{client: client}
 }
 
-func (h *GitHandler) InitializeRepository(ctx context.Context, targetDir string, repo interfaces.GitRepository) error {
+func (h *GitHandler) InitializeRepository(ctx context.Context, targetDir string, repo interfaces.GitRepository, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption) error {
 	initializedRepo, err := git.PlainInit(targetDir, false)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrRepoInitialization, err)
 	}
 
-	pushOpt := model.NewPushOption(targetDir, false, true, gpsconfig.HTTPClientOption{})
-	if err := h.client.Push(ctx, repo, pushOpt, gpsconfig.GitOption{}); err != nil {
+	pushOpt := model.NewPushOption(targetDir, false, true, httpOpt)
+	if err := h.client.Push(ctx, repo, pushOpt, gitOpt); err != nil {
 		return fmt.Errorf("%w: %w", ErrPushRepository, err)
 	}
 
@@ -43,9 +44,24 @@ func (h *GitHandler) InitializeRepository(ctx context.Context, targetDir string,
 		return fmt.Errorf("failed to set default branch: %w", err)
 	}
 
+	if err := h.client.Ops.SetDescription(ctx, targetDir, repo.ProjectInfo().Description); err != nil {
+		return fmt.Errorf("failed to set description: %w", err)
+	}
+
 	return nil
 }
 
+// Prune removes the branches and tags of the working copy at targetDir that the source repository no longer has.
+func (h *GitHandler) Prune(ctx context.Context, targetDir string, repo interfaces.GitRepository) ([]string, error) {
+	return h.client.Ops.PruneReferences(ctx, targetDir, repo.GoGitRepository()) //nolint
+}
+
+// CopyLFS copies the LFS objects the source repository has, and the working copy at targetDir lacks, into it.
+// A pull only brings the pointers, the objects come with the repository cloned for the sync.
+func (h *GitHandler) CopyLFS(ctx context.Context, targetDir string, repo interfaces.GitRepository, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption) error {
+	return lfs.Push(ctx, repo.GoGitRepository(), targetDir, gitOpt, httpOpt) //nolint
+}
+
 func (h *GitHandler) Pull(ctx context.Context, opt model.PullOption, targetDir string) error {
 	return h.client.Pull(ctx, opt, targetDir) //nolint
 }
@@ -244,7 +244,7 @@ func TestInitializeRepository(t *testing.T) {
 			bareRepository.ProjectMetaInfo.DefaultBranch = "main"
 
 			targetTmpDir := t.TempDir()
-			err = handler.InitializeRepository(testContext(), targetTmpDir, bareRepository)
+			err = handler.InitializeRepository(testContext(), targetTmpDir, bareRepository, gpsconfig.GitOption{}, gpsconfig.HTTPClientOption{})
 
 			if tabletest.wantErr {
 				require.Error(t, err)
@@ -25,7 +25,7 @@ func NewService(git GitHandler, storage StorageHandler) *Service {
 	}
 }
 
-func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption, _ gpsconfig.GitOption) error {
+func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption, gitOpt gpsconfig.GitOption) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Directory:Push")
 	opt.DebugLog(logger).Msg("Directory:Push")
@@ -37,18 +37,21 @@ func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt m
 
 	cliOpt := model.CLIOptions(ctx)
 	if cliOpt.ForcePush || !s.storage.DirectoryExists(targetDir) {
-		return s.git.InitializeRepository(ctx, targetDir, repo)
+		return s.git.InitializeRepository(ctx, targetDir, repo, gitOpt, opt.HTTPClient)
 	}
 
 	return nil
 }
 
-func (s *Service) Pull(ctx context.Context, sourceCfg gpsconfig.ProviderConfig, targetPath string, repo interfaces.GitRepository) error {
+// Pull updates the working copy of the repository from the source, along with the LFS objects of the
+// repository cloned for the sync, copied with the git and HTTP client settings of the target.
+// With prune, the branches and tags the source no longer has are removed from it.
+func (s *Service) Pull(ctx context.Context, sourceCfg, targetCfg gpsconfig.ProviderConfig, repo interfaces.GitRepository, prune bool) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Directory:Pull")
-	logger.Debug().Str("targetPath", targetPath).Msg("Directory:Pull")
+	logger.Debug().Str("targetPath", targetCfg.DirectoryTargetDir()).Msg("Directory:Pull")
 
-	targetDir, err := s.storage.GetTargetPath(ctx, targetPath, repo.ProjectInfo().Name(ctx))
+	targetDir, err := s.storage.GetTargetPath(ctx, targetCfg.DirectoryTargetDir(), repo.ProjectInfo().Name(ctx))
 	if err != nil {
 		return fmt.Errorf("%w %w", ErrDirGetPath, err)
 	}
@@ -58,5 +61,22 @@ func (s *Service) Pull(ctx context.Context, sourceCfg gpsconfig.ProviderConfig,
 		return fmt.Errorf("%w: targetDir: %s: %w", ErrPullRepository, targetDir, err)
 	}
 
+	if err := s.git.CopyLFS(ctx, targetDir, repo, targetCfg.Git, targetCfg.HTTPClient); err != nil {
+		return fmt.Errorf("%w: targetDir: %s: %w", ErrPullRepository, targetDir, err)
+	}
+
+	if !prune {
+		return nil
+	}
+
+	pruned, err := s.git.Prune(ctx, targetDir, repo)
+	if err != nil {
+		return fmt.Errorf("%w: targetDir: %s: %w", ErrPruneRepository, targetDir, err)
+	}
+
+	if len(pruned) > 0 {
+		logger.Info().Strs("references", pruned).Str("targetDir", targetDir).Msg("Pruned references no longer at the source")
+	}
+
 	return nil
 }
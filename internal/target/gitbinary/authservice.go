@@ -20,7 +20,12 @@ func (s *authService) AddBasicAuthToURL(ctx context.Context, urlStr, username, p
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("AddBasicAuthToURL")
 
-	parsedURL, _ := url.Parse(urlStr)
+	// Only http(s) urls carry basic auth, local paths and other schemes are passed as is
+	parsedURL, err := url.Parse(urlStr)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		return urlStr
+	}
+
 	parsedURL.User = url.UserPassword(username, password)
 
 	return parsedURL.String()
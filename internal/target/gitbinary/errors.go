@@ -27,5 +27,6 @@ var (
 	ErrPermissionDenied    = errors.New("failed with permission denied (publickey). Provide correct key in your ssh-agent")
 	ErrSetRepositoryConfig = errors.New("failed to set repository config")
 	ErrGetRemoteBranches   = errors.New("failed to get remote branches")
+	ErrListRemote          = errors.New("failed to list remote references")
 	ErrTmpDirPath          = errors.New("failed to get tmpdirpath")
 )
@@ -8,10 +8,10 @@ import (
 	"context"
 	"fmt"
 	"itiquette/git-provider-sync/internal/log"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 	"os"
 	"os/exec"
 	"strings"
-	"time"
 )
 
 type executorService struct {
@@ -28,8 +28,13 @@ func (e *executorService) RunGitCommand(ctx context.Context, env []string, worki
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering RunGitCommand")
 
-	ctx, cancel := context.WithTimeout(ctx, 3*time.Minute)
-	defer cancel()
+	// Commands without a deadline of their own, as transfers limited by git.timeout have, get the default one
+	if _, found := ctx.Deadline(); !found {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, gpsconfig.DefaultGitTimeout)
+		defer cancel()
+	}
 
 	cmd := exec.CommandContext(ctx, e.gitBinaryPath, args...) //nolint:gosec
 
@@ -50,7 +55,16 @@ func (e *executorService) RunGitCommand(ctx context.Context, env []string, worki
 }
 
 func (e *executorService) RunGitCommandWithOutput(ctx context.Context, workingDir string, args ...string) ([]byte, error) {
+	return e.RunGitCommandWithEnvOutput(ctx, nil, workingDir, args...)
+}
+
+// RunGitCommandWithEnvOutput runs a git command with extra environment variables and returns its standard output.
+func (e *executorService) RunGitCommandWithEnvOutput(ctx context.Context, env []string, workingDir string, args ...string) ([]byte, error) {
 	cmd := exec.CommandContext(ctx, e.gitBinaryPath, args...) //nolint:gosec
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
 	if len(workingDir) != 0 {
 		cmd.Dir = workingDir
 	}
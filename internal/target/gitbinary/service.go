@@ -6,16 +6,27 @@ package gitbinary
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"itiquette/git-provider-sync/internal/httpclient"
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"itiquette/git-provider-sync/internal/retry"
+	"itiquette/git-provider-sync/internal/target/lfs"
+	"itiquette/git-provider-sync/internal/target/mirrorcache"
+
 	"github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
 )
 
 type Service struct {
@@ -52,8 +63,41 @@ func (g *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 	logger.Trace().Msg("Entering Clone")
 	opt.DebugLog(logger).Msg("Clone")
 
+	repo, err := g.clone(ctx, opt)
+	if err != nil {
+		return repo, err
+	}
+
+	if opt.ReviewRefs != "" {
+		if err := g.fetchReviewRefs(ctx, repo, opt); err != nil {
+			repo.Release()
+
+			return model.Repository{}, err
+		}
+	}
+
+	if !opt.Git.LFS {
+		return repo, nil
+	}
+
+	if err := lfs.Fetch(ctx, repo.GoGitRepository(), opt.URL, opt.Git, opt.HTTPClient); err != nil {
+		repo.Release()
+
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	return repo, nil
+}
+
+func (g *Service) clone(ctx context.Context, opt model.CloneOption) (model.Repository, error) {
+	logger := log.Logger(ctx)
+
 	env := SetupSSHCommandEnv(opt.SSHClient.SSHCommand, opt.SSHClient.RewriteSSHURLFrom, opt.SSHClient.RewriteSSHURLTo)
 
+	if opt.Git.CacheDir != "" {
+		return g.cachedClone(ctx, opt, env)
+	}
+
 	tmpDirPath, err := model.GetTmpDirPath(ctx)
 	if err != nil {
 		return model.Repository{}, fmt.Errorf("%w: %w", ErrTmpDirPath, err)
@@ -64,12 +108,12 @@ func (g *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 
 	cloneURL := g.prepareCloneURL(ctx, opt)
 
-	if err := g.executorService.RunGitCommand(ctx, env, parentDir, "clone", cloneURL, destinationDir); err != nil {
-		if strings.Contains(err.Error(), "Permission denied (publickey)") {
-			return model.Repository{}, ErrPermissionDenied
-		}
+	if _, err := os.Stat(filepath.Join(destinationDir, git.GitDirName)); err == nil {
+		return g.updateClone(ctx, opt, env, destinationDir, cloneURL)
+	}
 
-		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	if err := g.transfer(ctx, opt.Git, opt.HTTPClient, env, parentDir, "clone", cloneURL, destinationDir); err != nil {
+		return model.Repository{}, cloneError(err)
 	}
 
 	if err := g.branchService.Fetch(ctx, destinationDir); err != nil {
@@ -79,6 +123,171 @@ func (g *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 	return g.finalizeClone(ctx, destinationDir, cloneURL, opt.Git.Type)
 }
 
+// fetchReviewRefs fetches the pull and merge request head refs into the clone, under the namespace they are pushed to.
+func (g *Service) fetchReviewRefs(ctx context.Context, repo model.Repository, opt model.CloneOption) error {
+	env := SetupSSHCommandEnv(opt.SSHClient.SSHCommand, opt.SSHClient.RewriteSSHURLFrom, opt.SSHClient.RewriteSSHURLTo)
+
+	if err := g.transfer(ctx, opt.Git, opt.HTTPClient, env, repositoryDir(repo), "fetch", "--force", g.prepareCloneURL(ctx, opt), opt.ReviewRefs); err != nil {
+		return cloneError(err)
+	}
+
+	return nil
+}
+
+// updateClone brings a clone left in the temporary directory by an interrupted run up to date, as the run is resumed.
+// Local branches are updated along with the remote ones, as a fresh clone would have them.
+func (g *Service) updateClone(ctx context.Context, opt model.CloneOption, env []string, destinationDir, cloneURL string) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering updateClone")
+	logger.Debug().Str("destinationDir", destinationDir).Msg("reusing clone of interrupted run")
+
+	if err := g.transfer(ctx, opt.Git, opt.HTTPClient, env, destinationDir, "fetch", "--prune", "--force", "--update-head-ok", cloneURL,
+		"+refs/heads/*:refs/heads/*", "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"); err != nil {
+		return model.Repository{}, cloneError(err)
+	}
+
+	return g.finalizeClone(ctx, destinationDir, cloneURL, opt.Git.Type)
+}
+
+// cachedClone brings the bare mirror in the cache up to date, creating it on first use.
+// The mirror stays locked until the repository is released, as the repository is the mirror itself.
+func (g *Service) cachedClone(ctx context.Context, opt model.CloneOption, env []string) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering cachedClone")
+
+	mirrorDir, err := mirrorcache.Dir(opt.Git.CacheDir, opt.URL)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	unlock, err := mirrorcache.Lock(ctx, mirrorDir)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	repo, err := g.updateMirror(ctx, opt, env, mirrorDir)
+	if err != nil {
+		unlock()
+
+		return model.Repository{}, err
+	}
+
+	return repo.WithRelease(unlock), nil
+}
+
+// updateMirror fetches into the locked mirror, creating an empty one to fetch into when there is none yet.
+// Credentials are only passed on the command line of the fetches, never stored in the mirror,
+// which is why the mirror isn't created with git clone --mirror.
+func (g *Service) updateMirror(ctx context.Context, opt model.CloneOption, env []string, mirrorDir string) (model.Repository, error) {
+	logger := log.Logger(ctx)
+
+	if !mirrorcache.Exists(mirrorDir) {
+		logger.Debug().Str("mirrorDir", mirrorDir).Msg("no cached mirror, creating it")
+
+		if err := g.executorService.RunGitCommand(ctx, env, filepath.Dir(mirrorDir), "init", "--bare", "--quiet", mirrorDir); err != nil {
+			return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+		}
+
+		if err := g.executorService.RunGitCommand(ctx, env, mirrorDir, "remote", "add", "--mirror=fetch", gpsconfig.ORIGIN, opt.URL); err != nil {
+			return model.Repository{}, fmt.Errorf("%w: %w", ErrSetRepositoryConfig, err)
+		}
+	}
+
+	logger.Debug().Str("mirrorDir", mirrorDir).Msg("fetching into cached mirror")
+
+	// Keep origin pointing at the current url, in case the source repository moved
+	if err := g.executorService.RunGitCommand(ctx, env, mirrorDir, "remote", "set-url", gpsconfig.ORIGIN, opt.URL); err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrSetRepositoryConfig, err)
+	}
+
+	cloneURL := g.prepareCloneURL(ctx, opt)
+
+	if err := g.transfer(ctx, opt.Git, opt.HTTPClient, env, mirrorDir, "fetch", "--prune", "--force", cloneURL, "+refs/*:refs/*"); err != nil {
+		return model.Repository{}, cloneError(err)
+	}
+
+	if err := g.setMirrorHead(ctx, opt, env, mirrorDir); err != nil {
+		return model.Repository{}, err
+	}
+
+	return g.finalizeClone(ctx, mirrorDir, cloneURL, opt.Git.Type)
+}
+
+// setMirrorHead points the HEAD of the mirror at the default branch of the source, as a clone would.
+// A source without a default branch, like an empty repository, leaves HEAD as it is.
+func (g *Service) setMirrorHead(ctx context.Context, opt model.CloneOption, env []string, mirrorDir string) error {
+	refs, err := g.RemoteRefs(ctx, opt)
+	if err != nil {
+		return err
+	}
+
+	head, found := strings.CutPrefix(refs["HEAD"], "ref: ")
+	if !found {
+		return nil
+	}
+
+	if err := g.executorService.RunGitCommand(ctx, env, mirrorDir, "symbolic-ref", "HEAD", head); err != nil {
+		return fmt.Errorf("%w: %w", ErrSetRepositoryConfig, err)
+	}
+
+	return nil
+}
+
+// RemoteRefs lists the references of the source repository with git ls-remote.
+func (g *Service) RemoteRefs(ctx context.Context, opt model.CloneOption) (map[string]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering RemoteRefs")
+	opt.DebugLog(logger).Msg("RemoteRefs")
+
+	env := SetupSSHCommandEnv(opt.SSHClient.SSHCommand, opt.SSHClient.RewriteSSHURLFrom, opt.SSHClient.RewriteSSHURLTo)
+
+	ctx, cancel := context.WithTimeout(ctx, opt.Git.OperationTimeout())
+	defer cancel()
+
+	output, err := g.executorService.RunGitCommandWithEnvOutput(ctx, env, "", "ls-remote", "--symref", g.prepareCloneURL(ctx, opt))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrListRemote, opt.URL, err)
+	}
+
+	return parseRemoteRefs(string(output)), nil
+}
+
+// parseRemoteRefs reads the output of git ls-remote --symref into reference names and what they point to.
+// A symbolic reference is listed both as "ref: <target>" and by hash, the symbolic form is kept.
+func parseRemoteRefs(output string) map[string]string {
+	refs := map[string]string{}
+	symbolic := map[string]bool{}
+
+	for _, line := range strings.Split(output, "\n") {
+		value, name, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if !found {
+			continue
+		}
+
+		if strings.HasPrefix(value, "ref: ") {
+			refs[name] = value
+			symbolic[name] = true
+
+			continue
+		}
+
+		if !symbolic[name] {
+			refs[name] = value
+		}
+	}
+
+	return refs
+}
+
+// cloneError wraps a failed clone or fetch, giving a hint when the ssh key was refused.
+func cloneError(err error) error {
+	if strings.Contains(err.Error(), "Permission denied (publickey)") {
+		return ErrPermissionDenied
+	}
+
+	return fmt.Errorf("%w: %w", ErrCloneRepository, err)
+}
+
 func (g *Service) prepareCloneURL(ctx context.Context, opt model.CloneOption) string {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering prepareCloneURL")
@@ -86,7 +295,7 @@ func (g *Service) prepareCloneURL(ctx context.Context, opt model.CloneOption) st
 
 	url := opt.URL
 	if !strings.EqualFold(opt.Git.Type, gpsconfig.SSHAGENT) {
-		url = g.authService.AddBasicAuthToURL(ctx, opt.URL, "anyuser", opt.HTTPClient.Token)
+		url = g.authService.AddBasicAuthToURL(ctx, opt.URL, httpclient.GitUsername(opt.URL), opt.HTTPClient.Token)
 	}
 
 	return url
@@ -145,15 +354,111 @@ func (g *Service) Pull(ctx context.Context, pullDirPath string, opt model.PullOp
 	return g.branchService.Fetch(ctx, pullDirPath)
 }
 
-func (g *Service) Push(ctx context.Context, _ interfaces.GitRepository, opt model.PushOption, _ gpsconfig.GitOption) error {
+func (g *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt model.PushOption, gitOpt gpsconfig.GitOption) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering Push")
 	opt.DebugLog(logger).Msg("Push")
 
+	// Objects first, a target checking for them then accepts the references pointing to them
+	if err := lfs.Push(ctx, repo.GoGitRepository(), opt.Target, gitOpt, opt.HTTPClient); err != nil {
+		return fmt.Errorf("%w: %w", ErrPushRepository, err)
+	}
+
 	env := SetupSSHCommandEnv(opt.SSHClient.SSHCommand, opt.SSHClient.RewriteSSHURLFrom, opt.SSHClient.RewriteSSHURLTo)
-	args := append([]string{"push", opt.Target}, opt.RefSpecs...)
+	args := []string{"push"}
+
+	var deletes []string
+
+	// git push --prune can't leave references out, with protected references to keep the stale ones are deleted by name
+	switch {
+	case opt.Prune && len(opt.KeepRefs) > 0:
+		output, err := g.executorService.RunGitCommandWithEnvOutput(ctx, env, repositoryDir(repo), "ls-remote", opt.Target)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrListRemote, opt.Target, err)
+		}
+
+		deletes = staleRefSpecs(repo.GoGitRepository(), opt, parseRemoteRefs(string(output)))
+	case opt.Prune:
+		args = append(args, "--prune")
+	}
+
+	args = append(args, opt.Target)
+	args = append(args, pushRefSpecs(opt.RefSpecs)...)
+	args = append(args, deletes...)
+
+	return g.transfer(ctx, gitOpt, opt.HTTPClient, env, repositoryDir(repo), args...)
+}
+
+// staleRefSpecs returns deleting refspecs for the references at the push target that the pushed refspecs cover,
+// but the repository no longer has, leaving out the references to keep.
+func staleRefSpecs(repo *git.Repository, opt model.PushOption, remoteRefs map[string]string) []string {
+	var deletes []string
+
+	for name, value := range remoteRefs {
+		if strings.HasPrefix(value, "ref: ") || strings.HasSuffix(name, "^{}") || opt.Keeps(name) {
+			continue
+		}
+
+		for _, spec := range opt.RefSpecs {
+			if strings.HasPrefix(spec, "^") {
+				continue
+			}
+
+			reverse := gogitconfig.RefSpec(strings.TrimPrefix(spec, "+")).Reverse()
+			if !reverse.Match(plumbing.ReferenceName(name)) {
+				continue
+			}
+
+			if _, err := repo.Reference(reverse.Dst(plumbing.ReferenceName(name)), false); errors.Is(err, plumbing.ErrReferenceNotFound) {
+				deletes = append(deletes, ":"+name)
+			}
+
+			break
+		}
+	}
+
+	slices.Sort(deletes)
+
+	return deletes
+}
+
+// pushRefSpecs returns the refspecs in the form git push accepts, where a negative refspec has no destination.
+func pushRefSpecs(refSpecs []string) []string {
+	specs := make([]string, 0, len(refSpecs))
+
+	for _, spec := range refSpecs {
+		if strings.HasPrefix(spec, "^") {
+			spec, _, _ = strings.Cut(spec, ":")
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs
+}
+
+// transfer runs a git command talking to a remote, retrying it when it failed with a transient network error.
+// Each attempt is limited by git.timeout.
+func (g *Service) transfer(ctx context.Context, gitOpt gpsconfig.GitOption, option gpsconfig.HTTPClientOption, env []string, workingDir string, args ...string) error {
+	return retry.NewPolicy(option).Do(ctx, retry.IsTransient, func() error { //nolint:wrapcheck
+		ctx, cancel := context.WithTimeout(ctx, gitOpt.OperationTimeout())
+		defer cancel()
+
+		return g.executorService.RunGitCommand(ctx, env, workingDir, args...)
+	})
+}
+
+// repositoryDir returns the directory of a repository stored on disk, empty for in-memory repositories.
+func repositoryDir(repo interfaces.GitRepository) string {
+	if repo == nil || repo.GoGitRepository() == nil {
+		return ""
+	}
+
+	if storage, ok := repo.GoGitRepository().Storer.(*filesystem.Storage); ok {
+		return storage.Filesystem().Root()
+	}
 
-	return g.executorService.RunGitCommand(ctx, env, "", args...)
+	return ""
 }
 
 func ValidateGitBinary() (string, error) {
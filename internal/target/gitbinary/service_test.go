@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitbinary
+
+import (
+	"testing"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteRefs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]string
+	}{
+		{
+			name: "symbolic head",
+			output: "ref: refs/heads/main\tHEAD\n" +
+				"1111111111111111111111111111111111111111\tHEAD\n" +
+				"1111111111111111111111111111111111111111\trefs/heads/main\n" +
+				"2222222222222222222222222222222222222222\trefs/tags/v1.0.0\n",
+			want: map[string]string{
+				"HEAD":             "ref: refs/heads/main",
+				"refs/heads/main":  "1111111111111111111111111111111111111111",
+				"refs/tags/v1.0.0": "2222222222222222222222222222222222222222",
+			},
+		},
+		{
+			name:   "detached head",
+			output: "1111111111111111111111111111111111111111\tHEAD\n",
+			want:   map[string]string{"HEAD": "1111111111111111111111111111111111111111"},
+		},
+		{
+			name:   "empty repository",
+			output: "",
+			want:   map[string]string{},
+		},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require.Equal(t, tabletest.want, parseRemoteRefs(tabletest.output))
+		})
+	}
+}
+
+func TestPushRefSpecs(t *testing.T) {
+	refSpecs := []string{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "^refs/pull/*:refs/pull/*"}
+
+	require.Equal(t, []string{"+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "^refs/pull/*"}, pushRefSpecs(refSpecs))
+}
+
+func TestStaleRefSpecs(t *testing.T) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), plumbing.ZeroHash)))
+
+	opt := model.NewPushOption("https://example.com/target.git", true, false, gpsconfig.HTTPClientOption{})
+	opt.KeepRefs = []string{"refs/heads/release/*"}
+
+	remoteRefs := map[string]string{
+		"HEAD":                   "ref: refs/heads/main",
+		"refs/heads/main":        "abc",
+		"refs/heads/stale":       "abc",
+		"refs/heads/release/1.0": "abc",
+		"refs/tags/v1.0":         "abc",
+		"refs/tags/v1.0^{}":      "abc",
+		"refs/pull/1/head":       "abc",
+	}
+
+	require.Equal(t, []string{":refs/heads/stale", ":refs/tags/v1.0"}, staleRefSpecs(repo, opt, remoteRefs))
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 
+	"itiquette/git-provider-sync/internal/httpclient"
 	"itiquette/git-provider-sync/internal/log"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
 )
@@ -24,7 +25,9 @@ func NewAuthService() *authService { //nolint
 	return &authService{}
 }
 
-func (p *authService) GetAuthMethod(ctx context.Context, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption, _ gpsconfig.SSHClientOption) (transport.AuthMethod, error) {
+// GetAuthMethod returns how git authenticates to the repository at repositoryURL.
+// Over https the token is sent with the username the provider of the repository expects.
+func (p *authService) GetAuthMethod(ctx context.Context, repositoryURL string, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption, _ gpsconfig.SSHClientOption) (transport.AuthMethod, error) {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("getAuthMethod")
 
@@ -32,7 +35,7 @@ func (p *authService) GetAuthMethod(ctx context.Context, gitOpt gpsconfig.GitOpt
 	case gpsconfig.SSHAGENT:
 		return ssh.NewSSHAgentAuth("git") //nolint
 	case gpsconfig.HTTPS, "":
-		return &http.BasicAuth{Username: "anyUser", Password: httpOpt.Token}, nil
+		return &http.BasicAuth{Username: httpclient.GitUsername(repositoryURL), Password: httpOpt.Token}, nil
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrInvalidAuth, gitOpt.Type)
 	}
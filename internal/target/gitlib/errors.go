@@ -10,13 +10,16 @@ var (
 	ErrAuthMethod       = errors.New("failed to get auth method")
 	ErrBranchCheckout   = errors.New("failed to checkout branch")
 	ErrCloneRepository  = errors.New("failed to clone repository")
+	ErrDescription      = errors.New("failed to write repository description")
 	ErrFetchBranches    = errors.New("failed to fetch branches")
 	ErrWorktree         = errors.New("failed to get worktree")
 	ErrHeadSet          = errors.New("failed to set HEAD reference")
 	ErrInvalidAuth      = errors.New("invalid authentication configuration")
+	ErrListRemote       = errors.New("failed to list remote references")
 	ErrOpenRepository   = errors.New("failed to open repository")
 	ErrUncleanWorkspace = errors.New("workspace is unclean, aborting")
 	ErrPullRepository   = errors.New("failed to pull repository")
+	ErrPruneReferences  = errors.New("failed to prune references")
 	ErrPushRepository   = errors.New("failed to push repository")
 	ErrRemoteCreation   = errors.New("failed to set remote in target repository")
 	ErrRepositoryOpen   = errors.New("failed to open repository")
@@ -7,6 +7,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -93,6 +97,74 @@ func (h *operation) FetchBranches(ctx context.Context, repo *git.Repository, aut
 	return nil
 }
 
+// FetchReviewRefs fetches the head refs of the pull or merge requests of the origin, as the refspec maps them.
+func (h *operation) FetchReviewRefs(ctx context.Context, repo *git.Repository, url string, auth transport.AuthMethod, refSpec string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering fetchReviewRefs")
+	logger.Debug().Str("url", url).Str("refSpec", refSpec).Msg("fetchReviewRefs")
+
+	options := &git.FetchOptions{
+		RemoteURL: url,
+		RefSpecs:  []gogitconfig.RefSpec{gogitconfig.RefSpec(refSpec)},
+		Auth:      auth,
+		Force:     true,
+	}
+
+	if err := repo.FetchContext(ctx, options); err != nil {
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			logger.Debug().Str("url", url).Msg("review refs already up-to-date")
+
+			return nil
+		}
+
+		return fmt.Errorf("%w: review refs: %w", ErrFetchBranches, err)
+	}
+
+	return nil
+}
+
+// FetchMirror updates all references of a bare mirror from its origin, removing the ones deleted there.
+func (h *operation) FetchMirror(ctx context.Context, repo *git.Repository, url string, auth transport.AuthMethod) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering fetchMirror")
+	logger.Debug().Str("url", url).Msg("fetchMirror")
+
+	// Keep origin pointing at the current url, in case the source repository moved
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFetchBranches, err)
+	}
+
+	if origin, found := cfg.Remotes[gpsconfig.ORIGIN]; found && !slices.Equal(origin.URLs, []string{url}) {
+		origin.URLs = []string{url}
+
+		if err := repo.SetConfig(cfg); err != nil {
+			return fmt.Errorf("%w: %w", ErrFetchBranches, err)
+		}
+	}
+
+	options := &git.FetchOptions{
+		RemoteName: gpsconfig.ORIGIN,
+		RemoteURL:  url,
+		RefSpecs:   []gogitconfig.RefSpec{"+refs/*:refs/*"},
+		Auth:       auth,
+		Force:      true,
+		Prune:      true,
+	}
+
+	if err := repo.FetchContext(ctx, options); err != nil {
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			logger.Debug().Str("url", url).Msg("mirror already up-to-date")
+
+			return nil
+		}
+
+		return fmt.Errorf("%w: %w", ErrFetchBranches, err)
+	}
+
+	return nil
+}
+
 func (h *operation) SetRemoteAndBranch(ctx context.Context, repository interfaces.GitRepository, targetDirPath string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering setRemoteAndBranch")
@@ -145,3 +217,112 @@ func (h *operation) SetDefaultBranch(ctx context.Context, repo *git.Repository,
 
 	return nil
 }
+
+// PruneReferences removes the branches and tags of the working copy at targetDirPath that the source repository
+// no longer has, along with their remote-tracking branches. The checked out branch is kept.
+// It returns the names of the removed references.
+func (h *operation) PruneReferences(ctx context.Context, targetDirPath string, source *git.Repository) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering pruneReferences")
+	logger.Debug().Str("targetDirPath", targetDirPath).Msg("pruneReferences")
+
+	repo, err := git.PlainOpen(targetDirPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrOpenRepository, targetDirPath, err)
+	}
+
+	sourceRefs, err := source.References()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPruneReferences, err)
+	}
+
+	kept := map[plumbing.ReferenceName]bool{}
+	_ = sourceRefs.ForEach(func(ref *plumbing.Reference) error {
+		kept[ref.Name()] = true
+
+		return nil
+	})
+
+	head, err := repo.Head()
+	if err == nil {
+		kept[head.Name()] = true
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPruneReferences, err)
+	}
+
+	var pruned []plumbing.ReferenceName
+
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+
+		switch {
+		case name.IsBranch(), name.IsTag():
+			if !kept[name] {
+				pruned = append(pruned, name)
+			}
+		case name.IsRemote() && strings.HasPrefix(name.String(), "refs/remotes/"+gpsconfig.ORIGIN+"/"):
+			branch := plumbing.NewBranchReferenceName(strings.TrimPrefix(name.String(), "refs/remotes/"+gpsconfig.ORIGIN+"/"))
+			if !kept[branch] && ref.Type() == plumbing.HashReference {
+				pruned = append(pruned, name)
+			}
+		}
+
+		return nil
+	})
+
+	names := make([]string, 0, len(pruned))
+
+	for _, name := range pruned {
+		if err := repo.Storer.RemoveReference(name); err != nil {
+			return names, fmt.Errorf("%w: %s: %w", ErrPruneReferences, name, err)
+		}
+
+		names = append(names, name.String())
+	}
+
+	return names, nil
+}
+
+// defaultDescription is the placeholder git init writes to the description file.
+const defaultDescription = "Unnamed repository; edit this file 'description' to name the repository."
+
+// SetDescription writes the repository description to the .git/description file, as read by git web frontends
+// and by the archive and directory source providers when restoring.
+func (h *operation) SetDescription(ctx context.Context, targetDirPath string, description string) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering setDescription")
+	logger.Debug().Str("targetDirPath", targetDirPath).Msg("setDescription")
+
+	if description == "" {
+		return nil
+	}
+
+	descriptionPath := filepath.Join(targetDirPath, git.GitDirName, "description")
+	if err := os.WriteFile(descriptionPath, []byte(description+"\n"), 0o644); err != nil { //nolint:gosec
+		return fmt.Errorf("%w: %s: %w", ErrDescription, descriptionPath, err)
+	}
+
+	return nil
+}
+
+// Description reads the repository description from the .git/description file.
+// A missing file or the git init placeholder gives an empty description.
+func (h *operation) Description(ctx context.Context, repoDirPath string) string {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering description")
+
+	content, err := os.ReadFile(filepath.Join(repoDirPath, git.GitDirName, "description"))
+	if err != nil {
+		return ""
+	}
+
+	description := strings.TrimSpace(string(content))
+	if description == defaultDescription {
+		return ""
+	}
+
+	return description
+}
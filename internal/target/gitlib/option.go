@@ -37,7 +37,9 @@ func (s *Service) buildPullOptions(remote string, url string, auth transport.Aut
 // 	}
 // }
 
-func (s *Service) buildPushOptions(url string, refSpec []string, prune bool, auth transport.AuthMethod) git.PushOptions {
+// buildPushOptions leaves pruning to deleting refspecs, as go-git prunes every remote reference
+// matched by a forced refspec, not just the ones missing locally.
+func (s *Service) buildPushOptions(url string, refSpec []string, auth transport.AuthMethod) git.PushOptions {
 	refSpecs := make([]gogitconfig.RefSpec, 0, 20)
 
 	for _, r := range refSpec {
@@ -50,6 +52,5 @@ func (s *Service) buildPushOptions(url string, refSpec []string, prune bool, aut
 		Auth:      auth,
 		RemoteURL: url,
 		RefSpecs:  refSpecs,
-		Prune:     prune, //TO-DO: open an issue - wont allow for protected branch
 	}
 }
@@ -7,17 +7,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/storage/memory"
 
 	"itiquette/git-provider-sync/internal/interfaces"
 	"itiquette/git-provider-sync/internal/log"
 	"itiquette/git-provider-sync/internal/model"
 	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/retry"
+	"itiquette/git-provider-sync/internal/target/lfs"
+	"itiquette/git-provider-sync/internal/target/mirrorcache"
 )
 
 type Service struct {
@@ -43,11 +51,46 @@ func (s *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 	logger.Trace().Msg("Entering GitService:Clone")
 	opt.DebugLog(logger).Msg("GitService:Clone")
 
-	auth, err := s.authService.GetAuthMethod(ctx, opt.Git, opt.HTTPClient, opt.SSHClient)
+	repo, err := s.clone(ctx, opt)
+	if err != nil {
+		return repo, err
+	}
+
+	if opt.ReviewRefs != "" {
+		if err := s.fetchReviewRefs(ctx, repo, opt); err != nil {
+			repo.Release()
+
+			return model.Repository{}, err
+		}
+	}
+
+	if !opt.Git.LFS {
+		return repo, nil
+	}
+
+	if err := lfs.Fetch(ctx, repo.GoGitRepository(), opt.URL, opt.Git, opt.HTTPClient); err != nil {
+		repo.Release()
+
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	return repo, nil
+}
+
+func (s *Service) clone(ctx context.Context, opt model.CloneOption) (model.Repository, error) {
+	auth, err := s.authService.GetAuthMethod(ctx, opt.URL, opt.Git, opt.HTTPClient, opt.SSHClient)
 	if err != nil {
 		return model.Repository{}, fmt.Errorf("%w: %w", ErrAuthMethod, err)
 	}
 
+	if opt.Git.CacheDir != "" && !opt.NonBareRepo {
+		return s.cachedClone(ctx, opt, auth)
+	}
+
+	if opt.Git.LFS && !opt.NonBareRepo {
+		return s.diskClone(ctx, opt, auth)
+	}
+
 	var fileSys billy.Filesystem
 	if opt.NonBareRepo {
 		fileSys = memfs.New()
@@ -55,7 +98,121 @@ func (s *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 
 	cloneOpt := s.buildCloneOptions(opt.URL, opt.Mirror, auth)
 
-	repo, err := git.Clone(memory.NewStorage(), fileSys, cloneOpt)
+	var repo *git.Repository
+
+	err = withRetry(ctx, opt.URL, opt.Git, opt.HTTPClient, func(ctx context.Context) error {
+		repo, err = git.CloneContext(ctx, memory.NewStorage(), fileSys, cloneOpt)
+
+		return err //nolint:wrapcheck
+	})
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	return model.NewRepository(repo) //nolint
+}
+
+// fetchReviewRefs fetches the pull and merge request head refs into the clone, under the namespace they are pushed to.
+func (s *Service) fetchReviewRefs(ctx context.Context, repo model.Repository, opt model.CloneOption) error {
+	auth, err := s.authService.GetAuthMethod(ctx, opt.URL, opt.Git, opt.HTTPClient, opt.SSHClient)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	return withRetry(ctx, opt.URL, opt.Git, opt.HTTPClient, func(ctx context.Context) error {
+		return s.Ops.FetchReviewRefs(ctx, repo.GoGitRepository(), opt.URL, auth, opt.ReviewRefs)
+	})
+}
+
+// cachedClone brings the bare mirror in the cache up to date, cloning it on first use.
+// The mirror stays locked until the repository is released, as the repository is the mirror itself.
+func (s *Service) cachedClone(ctx context.Context, opt model.CloneOption, auth transport.AuthMethod) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:cachedClone")
+
+	mirrorDir, err := mirrorcache.Dir(opt.Git.CacheDir, opt.URL)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	unlock, err := mirrorcache.Lock(ctx, mirrorDir)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	repo, err := s.updateMirror(ctx, opt, auth, mirrorDir)
+	if err != nil {
+		unlock()
+
+		return model.Repository{}, err
+	}
+
+	return repo.WithRelease(unlock), nil
+}
+
+// updateMirror fetches into the locked mirror, or clones it when there is none yet.
+func (s *Service) updateMirror(ctx context.Context, opt model.CloneOption, auth transport.AuthMethod, mirrorDir string) (model.Repository, error) {
+	logger := log.Logger(ctx)
+
+	if !mirrorcache.Exists(mirrorDir) {
+		logger.Debug().Str("mirrorDir", mirrorDir).Msg("no cached mirror, cloning")
+
+		var repo *git.Repository
+
+		err := withRetry(ctx, opt.URL, opt.Git, opt.HTTPClient, func(ctx context.Context) error {
+			var err error
+
+			repo, err = git.PlainCloneContext(ctx, mirrorDir, true, s.buildCloneOptions(opt.URL, true, auth))
+
+			return err //nolint:wrapcheck
+		})
+		if err != nil {
+			return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+		}
+
+		return model.NewRepository(repo) //nolint
+	}
+
+	logger.Debug().Str("mirrorDir", mirrorDir).Msg("fetching into cached mirror")
+
+	repo, err := git.PlainOpen(mirrorDir)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrOpenRepository, err)
+	}
+
+	err = withRetry(ctx, opt.URL, opt.Git, opt.HTTPClient, func(ctx context.Context) error {
+		return s.Ops.FetchMirror(ctx, repo, opt.URL, auth)
+	})
+	if err != nil {
+		return model.Repository{}, err
+	}
+
+	return model.NewRepository(repo) //nolint
+}
+
+// diskClone clones a bare mirror into the temporary directory, where the LFS objects can be kept next to it.
+// A clone left by an interrupted run is replaced.
+func (s *Service) diskClone(ctx context.Context, opt model.CloneOption, auth transport.AuthMethod) (model.Repository, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:diskClone")
+
+	tmpDirPath, err := model.GetTmpDirPath(ctx)
+	if err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	cloneDir := filepath.Join(tmpDirPath, opt.Name)
+	if err := os.RemoveAll(cloneDir); err != nil {
+		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
+	}
+
+	var repo *git.Repository
+
+	err = withRetry(ctx, opt.URL, opt.Git, opt.HTTPClient, func(ctx context.Context) error {
+		repo, err = git.PlainCloneContext(ctx, cloneDir, true, s.buildCloneOptions(opt.URL, opt.Mirror, auth))
+
+		return err //nolint:wrapcheck
+	})
 	if err != nil {
 		return model.Repository{}, fmt.Errorf("%w: %w", ErrCloneRepository, err)
 	}
@@ -63,6 +220,49 @@ func (s *Service) Clone(ctx context.Context, opt model.CloneOption) (model.Repos
 	return model.NewRepository(repo) //nolint
 }
 
+// RemoteRefs lists the references of the source repository, like git ls-remote.
+func (s *Service) RemoteRefs(ctx context.Context, opt model.CloneOption) (map[string]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:RemoteRefs")
+	opt.DebugLog(logger).Msg("GitService:RemoteRefs")
+
+	auth, err := s.authService.GetAuthMethod(ctx, opt.URL, opt.Git, opt.HTTPClient, opt.SSHClient)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthMethod, err)
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &gogitconfig.RemoteConfig{
+		Name: gpsconfig.ORIGIN,
+		URLs: []string{opt.URL},
+	})
+
+	listCtx, cancel := context.WithTimeout(ctx, opt.Git.OperationTimeout())
+	defer cancel()
+
+	references, err := remote.ListContext(listCtx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return map[string]string{}, nil
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrListRemote, err)
+	}
+
+	refs := make(map[string]string, len(references))
+
+	for _, reference := range references {
+		if reference.Type() == plumbing.SymbolicReference {
+			refs[reference.Name().String()] = "ref: " + reference.Target().String()
+
+			continue
+		}
+
+		refs[reference.Name().String()] = reference.Hash().String()
+	}
+
+	return refs, nil
+}
+
 func (s *Service) Pull(ctx context.Context, opt model.PullOption, targetDir string) error {
 	logger := log.Logger(ctx)
 	logger.Trace().Msg("Entering GitService:Pull")
@@ -73,7 +273,8 @@ func (s *Service) Pull(ctx context.Context, opt model.PullOption, targetDir stri
 		return err
 	}
 
-	auth, err := s.authService.GetAuthMethod(ctx, opt.GitOption, opt.HTTPClient, opt.SSHClient)
+	// The working copy pulls from the source it was created from
+	auth, err := s.authService.GetAuthMethod(ctx, originURL(repo), opt.GitOption, opt.HTTPClient, opt.SSHClient)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrAuthMethod, err)
 	}
@@ -91,14 +292,31 @@ func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt m
 	logger.Trace().Msg("Entering GitService:Push")
 	opt.DebugLog(logger).Str("gitOpt", gitOpt.String()).Msg("GitService:Push")
 
-	auth, err := s.authService.GetAuthMethod(ctx, gitOpt, opt.HTTPClient, opt.SSHClient)
+	auth, err := s.authService.GetAuthMethod(ctx, opt.Target, gitOpt, opt.HTTPClient, opt.SSHClient)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrAuthMethod, err)
 	}
 
-	pushOpts := s.buildPushOptions(opt.Target, opt.RefSpecs, opt.Prune, auth)
+	// Objects first, a target checking for them then accepts the references pointing to them
+	if err := lfs.Push(ctx, repo.GoGitRepository(), opt.Target, gitOpt, opt.HTTPClient); err != nil {
+		return fmt.Errorf("%w: %w", ErrPushRepository, err)
+	}
 
-	if err := repo.GoGitRepository().Push(&pushOpts); err != nil {
+	pushOpts := s.buildPushOptions(opt.Target, opt.RefSpecs, auth)
+
+	if opt.Prune {
+		deletes, err := s.staleReferences(ctx, repo.GoGitRepository(), opt, gitOpt, auth)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrPushRepository, err)
+		}
+
+		pushOpts.RefSpecs = append(pushOpts.RefSpecs, deletes...)
+	}
+
+	err = withRetry(ctx, opt.Target, gitOpt, opt.HTTPClient, func(ctx context.Context) error {
+		return repo.GoGitRepository().PushContext(ctx, &pushOpts) //nolint:wrapcheck
+	})
+	if err != nil {
 		if errors.Is(err, git.NoErrAlreadyUpToDate) {
 			logger.Debug().Str("targetDir", opt.Target).Msg("repository already up-to-date")
 			s.metadata.UpdateSyncMetadata(ctx, "uptodate", opt.Target)
@@ -112,6 +330,90 @@ func (s *Service) Push(ctx context.Context, repo interfaces.GitRepository, opt m
 	return nil
 }
 
+// staleReferences returns deleting refspecs for the references at the push target that the pushed refspecs cover,
+// but the repository no longer has. References protected at the target are kept.
+func (s *Service) staleReferences(ctx context.Context, repo *git.Repository, opt model.PushOption, gitOpt gpsconfig.GitOption, auth transport.AuthMethod) ([]gogitconfig.RefSpec, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering GitService:staleReferences")
+
+	remote := git.NewRemote(memory.NewStorage(), &gogitconfig.RemoteConfig{
+		Name: gpsconfig.ORIGIN,
+		URLs: []string{opt.Target},
+	})
+
+	var remoteRefs []*plumbing.Reference
+
+	err := withRetry(ctx, opt.Target, gitOpt, opt.HTTPClient, func(ctx context.Context) error {
+		var err error
+
+		remoteRefs, err = remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+
+		return err //nolint:wrapcheck
+	})
+	if err != nil {
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrListRemote, err)
+	}
+
+	var deletes []gogitconfig.RefSpec
+
+	for _, remoteRef := range remoteRefs {
+		if remoteRef.Type() != plumbing.HashReference || opt.Keeps(remoteRef.Name().String()) {
+			continue
+		}
+
+		for _, spec := range opt.RefSpecs {
+			if strings.HasPrefix(spec, "^") {
+				continue
+			}
+
+			reverse := gogitconfig.RefSpec(strings.TrimPrefix(spec, "+")).Reverse()
+			if !reverse.Match(remoteRef.Name()) {
+				continue
+			}
+
+			if _, err := repo.Reference(reverse.Dst(remoteRef.Name()), false); errors.Is(err, plumbing.ErrReferenceNotFound) {
+				logger.Debug().Str("reference", remoteRef.Name().String()).Msg("pruning reference no longer at the source")
+				deletes = append(deletes, gogitconfig.RefSpec(":"+remoteRef.Name().String()))
+			}
+
+			break
+		}
+	}
+
+	return deletes, nil
+}
+
+// withRetry runs a git transfer limited by git.timeout, retrying it when it failed with a transient network error.
+// Transfers over https are retried per request by the http client of the provider, so only other transports are retried here.
+func withRetry(ctx context.Context, url string, gitOpt gpsconfig.GitOption, option gpsconfig.HTTPClientOption, transfer func(context.Context) error) error {
+	attempt := func() error {
+		ctx, cancel := context.WithTimeout(ctx, gitOpt.OperationTimeout())
+		defer cancel()
+
+		return transfer(ctx)
+	}
+
+	if strings.HasPrefix(strings.ToLower(url), "https://") {
+		return attempt()
+	}
+
+	return retry.NewPolicy(option).Do(ctx, retry.IsTransient, attempt) //nolint:wrapcheck
+}
+
+// originURL returns the URL of the origin remote of the repository, empty without one.
+func originURL(repo *git.Repository) string {
+	remote, err := repo.Remote(gpsconfig.ORIGIN)
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+
+	return remote.Config().URLs[0]
+}
+
 func (s *Service) prepareRepository(ctx context.Context, targetDir string) (*git.Repository, *git.Worktree, error) {
 	repo, err := s.Ops.Open(ctx, targetDir)
 	if err != nil {
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package gitlib
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"itiquette/git-provider-sync/internal/model"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+
+	"github.com/go-git/go-git/v5"
+	gogitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushPrune(t *testing.T) {
+	tests := []struct {
+		name      string
+		prune     bool
+		force     bool
+		keepRefs  []string
+		wantStale bool
+	}{
+		{"without prune the deleted branch stays", false, false, nil, true},
+		{"prune removes the deleted branch", true, false, nil, false},
+		{"prune with force push removes only the deleted branch", true, true, nil, false},
+		{"prune keeps the protected branch", true, false, []string{"refs/heads/st*"}, true},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			source, err := git.PlainInit(filepath.Join(t.TempDir(), "source"), true)
+			require.NoError(err)
+
+			_, err = source.CreateRemote(&gogitconfig.RemoteConfig{Name: gpsconfig.ORIGIN, URLs: []string{"https://example.com/source.git"}})
+			require.NoError(err)
+
+			commit := commitTo(t, source)
+			for _, name := range []string{"main", "stale"} {
+				require.NoError(source.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), commit)))
+			}
+
+			targetDir := filepath.Join(t.TempDir(), "target")
+			_, err = git.PlainInit(targetDir, true)
+			require.NoError(err)
+
+			repo, err := model.NewRepository(source)
+			require.NoError(err)
+
+			service := NewService()
+			require.NoError(service.Push(ctx, repo, model.NewPushOption(targetDir, false, tabletest.force, gpsconfig.HTTPClientOption{}), gpsconfig.GitOption{}))
+
+			require.NoError(source.Storer.RemoveReference(plumbing.NewBranchReferenceName("stale")))
+
+			pushOption := model.NewPushOption(targetDir, tabletest.prune, tabletest.force, gpsconfig.HTTPClientOption{})
+			pushOption.KeepRefs = tabletest.keepRefs
+			require.NoError(service.Push(ctx, repo, pushOption, gpsconfig.GitOption{}))
+
+			target, err := git.PlainOpen(targetDir)
+			require.NoError(err)
+
+			_, err = target.Reference(plumbing.NewBranchReferenceName("main"), false)
+			require.NoError(err)
+
+			_, err = target.Reference(plumbing.NewBranchReferenceName("stale"), false)
+			require.Equal(tabletest.wantStale, err == nil)
+		})
+	}
+}
+
+func TestCloneReviewRefs(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	sourceDir := filepath.Join(t.TempDir(), "source")
+	source, err := git.PlainInit(sourceDir, true)
+	require.NoError(err)
+
+	commit := commitTo(t, source)
+	require.NoError(source.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("main"), commit)))
+	require.NoError(source.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("main"))))
+	require.NoError(source.Storer.SetReference(plumbing.NewHashReference("refs/pull/7/head", commit)))
+	require.NoError(source.Storer.SetReference(plumbing.NewHashReference("refs/pull/7/merge", commit)))
+
+	sourceCfg := gpsconfig.ProviderConfig{ProviderType: gpsconfig.GITHUB, Repositories: gpsconfig.RepositoriesOption{ReviewRefs: "refs/mirror/pr"}}
+
+	repo, err := NewService().Clone(ctx, model.CloneOption{URL: sourceDir, Mirror: true, ReviewRefs: model.ReviewRefsFetchSpec(sourceCfg)})
+	require.NoError(err)
+
+	ref, err := repo.GoGitRepository().Reference("refs/mirror/pr/7", false)
+	require.NoError(err)
+	require.Equal(commit, ref.Hash())
+
+	_, err = repo.GoGitRepository().Reference("refs/mirror/pr/7/merge", false)
+	require.Error(err)
+}
+
+// commitTo writes an empty commit to the repository and returns its hash.
+func commitTo(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+
+	tree := &object.Tree{}
+	treeObject := repo.Storer.NewEncodedObject()
+	require.NoError(t, tree.Encode(treeObject))
+	treeHash, err := repo.Storer.SetEncodedObject(treeObject)
+	require.NoError(t, err)
+
+	signature := object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := &object.Commit{Author: signature, Committer: signature, Message: "initial", TreeHash: treeHash}
+	commitObject := repo.Storer.NewEncodedObject()
+	require.NoError(t, commit.Encode(commitObject))
+	hash, err := repo.Storer.SetEncodedObject(commitObject)
+	require.NoError(t, err)
+
+	return hash
+}
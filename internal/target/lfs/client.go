@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package lfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"itiquette/git-provider-sync/internal/httpclient"
+	"itiquette/git-provider-sync/internal/log"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+	"itiquette/git-provider-sync/internal/retry"
+)
+
+var (
+	ErrBatch    = errors.New("LFS batch request failed")
+	ErrTransfer = errors.New("LFS object transfer failed")
+)
+
+const (
+	mediaType = "application/vnd.git-lfs+json"
+
+	// batchSize is how many objects are asked for in one batch request.
+	batchSize = 100
+
+	operationDownload = "download"
+	operationUpload   = "upload"
+	actionVerify      = "verify"
+)
+
+type batchRequest struct {
+	Operation string    `json:"operation"`
+	Transfers []string  `json:"transfers"`
+	Objects   []Pointer `json:"objects"`
+}
+
+type batchResponse struct {
+	Objects []batchObject `json:"objects"`
+}
+
+type batchObject struct {
+	Pointer
+
+	Actions map[string]action `json:"actions"`
+	Error   *objectError      `json:"error"`
+}
+
+type action struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header"`
+}
+
+type objectError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// client talks to the LFS server of a repository, authenticating like git does over https.
+type client struct {
+	httpClient *http.Client
+	endpoint   string
+	username   string
+	token      string
+}
+
+// newClient returns a client for the LFS endpoint, sending requests through the transport of the provider calls.
+// Requests are retried by httpclient settings, and each is limited by git.timeout, as objects can be as large as a clone.
+func newClient(ctx context.Context, endpoint string, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption) (*client, error) {
+	transport, err := httpclient.NewTransport(ctx, httpOpt)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	return &client{
+		httpClient: &http.Client{
+			Transport: &retry.Transport{
+				Base:    transport,
+				Policy:  retry.NewPolicy(httpOpt),
+				Timeout: gitOpt.OperationTimeout(),
+			},
+		},
+		endpoint: endpoint,
+		username: httpclient.GitUsername(endpoint),
+		token:    httpOpt.Token,
+	}, nil
+}
+
+// download fetches the objects into the LFS directory.
+func (c *client) download(ctx context.Context, dir string, pointers []Pointer) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:download")
+
+	return c.batches(ctx, operationDownload, pointers, func(object batchObject) error {
+		if object.Error != nil {
+			if object.Error.Code == http.StatusNotFound || object.Error.Code == http.StatusGone {
+				logger.Warn().Str("oid", object.OID).Str("reason", object.Error.Message).Msg("LFS object missing at the source, not mirrored")
+
+				return nil
+			}
+
+			return fmt.Errorf("%w: %s: %d %s", ErrTransfer, object.OID, object.Error.Code, object.Error.Message)
+		}
+
+		download, found := object.Actions[operationDownload]
+		if !found {
+			return fmt.Errorf("%w: %s: no download action", ErrTransfer, object.OID)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, download, nil, nil)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrTransfer, object.OID, err)
+		}
+		defer resp.Body.Close()
+
+		return store(dir, object.Pointer, resp.Body)
+	})
+}
+
+// upload sends the objects the server asks for from the LFS directory, and verifies them when it wants to.
+func (c *client) upload(ctx context.Context, dir string, pointers []Pointer) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:upload")
+
+	return c.batches(ctx, operationUpload, pointers, func(object batchObject) error {
+		if object.Error != nil {
+			return fmt.Errorf("%w: %s: %d %s", ErrTransfer, object.OID, object.Error.Code, object.Error.Message)
+		}
+
+		upload, found := object.Actions[operationUpload]
+		if !found {
+			// Already at the server
+			return nil
+		}
+
+		path := objectPath(dir, object.OID)
+		openObject := func() (io.ReadCloser, error) { return os.Open(path) } //nolint:gosec
+
+		body, err := openObject()
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrTransfer, object.OID, err)
+		}
+
+		resp, err := c.do(ctx, http.MethodPut, upload, body, openObject, func(req *http.Request) {
+			req.ContentLength = object.Size
+			req.Header.Set("Content-Type", "application/octet-stream")
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrTransfer, object.OID, err)
+		}
+		resp.Body.Close()
+
+		verify, found := object.Actions[actionVerify]
+		if !found {
+			return nil
+		}
+
+		pointer, _ := json.Marshal(object.Pointer) //nolint:errchkjson
+
+		resp, err = c.do(ctx, http.MethodPost, verify, bytes.NewReader(pointer), nil, lfsHeaders)
+		if err != nil {
+			return fmt.Errorf("%w: %s: verify: %w", ErrTransfer, object.OID, err)
+		}
+		resp.Body.Close()
+
+		logger.Debug().Str("oid", object.OID).Int64("size", object.Size).Msg("uploaded LFS object")
+
+		return nil
+	})
+}
+
+// batches asks the server about the objects, batchSize at a time, and handles each object it answers for.
+func (c *client) batches(ctx context.Context, operation string, pointers []Pointer, handle func(batchObject) error) error {
+	for start := 0; start < len(pointers); start += batchSize {
+		request, _ := json.Marshal(batchRequest{ //nolint:errchkjson
+			Operation: operation,
+			Transfers: []string{"basic"},
+			Objects:   pointers[start:min(start+batchSize, len(pointers))],
+		})
+
+		resp, err := c.do(ctx, http.MethodPost, action{Href: c.endpoint + "/objects/batch"}, bytes.NewReader(request), nil, lfsHeaders)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrBatch, operation, err)
+		}
+
+		var response batchResponse
+
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrBatch, operation, err)
+		}
+
+		for _, object := range response.Objects {
+			if err := handle(object); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// do sends a request for an action, with the headers the server gave for it.
+// The token authenticates requests to the LFS server itself, unless the action brings its own authorization.
+// Any response but a success is an error. A file body is only sent again on a retry when getBody opens it anew.
+func (c *client) do(ctx context.Context, method string, target action, body io.Reader, getBody func() (io.ReadCloser, error), options ...func(*http.Request)) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target.Href, body)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if getBody != nil {
+		req.GetBody = getBody
+	}
+
+	for _, option := range options {
+		option(req)
+	}
+
+	for key, value := range target.Header {
+		req.Header.Set(key, value)
+	}
+
+	if req.Header.Get("Authorization") == "" && c.token != "" && sameHost(target.Href, c.endpoint) {
+		req.SetBasicAuth(c.username, c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("%s %s: %s", method, redact(target.Href), resp.Status) //nolint:err113
+	}
+
+	return resp, nil
+}
+
+func lfsHeaders(req *http.Request) {
+	req.Header.Set("Accept", mediaType)
+	req.Header.Set("Content-Type", mediaType)
+}
+
+func sameHost(first, second string) bool {
+	firstURL, err := url.Parse(first)
+	if err != nil {
+		return false
+	}
+
+	secondURL, err := url.Parse(second)
+
+	return err == nil && firstURL.Host == secondURL.Host
+}
+
+// verifier hashes content the way LFS names objects.
+type verifier struct {
+	hash.Hash
+}
+
+func newVerifier() *verifier {
+	return &verifier{Hash: sha256.New()}
+}
+
+func (v *verifier) oid() string {
+	return hex.EncodeToString(v.Sum(nil))
+}
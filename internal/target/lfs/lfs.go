@@ -0,0 +1,389 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package lfs mirrors the Git LFS objects of repositories, without needing the git-lfs binary.
+//
+// The objects a repository refers to through LFS pointer files are kept where git-lfs keeps them,
+// at <gitdir>/lfs/objects/<oid[0:2]>/<oid[2:4]>/<oid>. They are downloaded from, and uploaded to,
+// the LFS server of a remote with the LFS batch API, over its basic transfer adapter.
+// A remote on the local file system has its objects copied into its git directory instead.
+package lfs
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"itiquette/git-provider-sync/internal/log"
+	gpsconfig "itiquette/git-provider-sync/internal/model/configuration"
+)
+
+var (
+	ErrNoLocalStorage = errors.New("LFS objects need a repository stored on disk")
+	ErrUnsupportedURL = errors.New("LFS objects can only be transferred over http(s) or to a local path")
+	ErrScan           = errors.New("failed to scan repository for LFS pointers")
+	ErrStore          = errors.New("failed to store LFS object")
+)
+
+const (
+	// specVersion starts every LFS pointer file.
+	specVersion = "version https://git-lfs.github.com/spec/v1"
+
+	// maxPointerSize is the largest blob read as a possible pointer, git-lfs writes far smaller ones.
+	maxPointerSize = 1024
+
+	objectsDir = "objects"
+	tmpDir     = "tmp"
+)
+
+// Pointer identifies an LFS object, as a pointer file in the repository refers to it.
+type Pointer struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// ParsePointer reads an LFS pointer file, reporting whether the data is one.
+func ParsePointer(data []byte) (Pointer, bool) {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != specVersion {
+		return Pointer{}, false
+	}
+
+	pointer := Pointer{Size: -1}
+
+	for _, line := range lines[1:] {
+		key, value, found := strings.Cut(line, " ")
+		if !found {
+			return Pointer{}, false
+		}
+
+		switch key {
+		case "oid":
+			oid, isSHA256 := strings.CutPrefix(value, "sha256:")
+			if decoded, err := hex.DecodeString(oid); !isSHA256 || err != nil || len(decoded) != 32 || oid != strings.ToLower(oid) {
+				return Pointer{}, false
+			}
+
+			pointer.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return Pointer{}, false
+			}
+
+			pointer.Size = size
+		}
+	}
+
+	return pointer, pointer.OID != "" && pointer.Size >= 0
+}
+
+// Scan returns the LFS objects the pointer files among the objects of the repository refer to.
+func Scan(repo *git.Repository) ([]Pointer, error) {
+	blobs, err := repo.BlobObjects()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScan, err)
+	}
+	defer blobs.Close()
+
+	var pointers []Pointer
+
+	seen := map[string]bool{}
+
+	err = blobs.ForEach(func(blob *object.Blob) error {
+		if blob.Size > maxPointerSize {
+			return nil
+		}
+
+		reader, err := blob.Reader()
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if pointer, isPointer := ParsePointer(data); isPointer && !seen[pointer.OID] {
+			seen[pointer.OID] = true
+			pointers = append(pointers, pointer)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrScan, err)
+	}
+
+	return pointers, nil
+}
+
+// Dir returns the LFS directory of a repository stored on disk, empty for in-memory repositories.
+func Dir(repo *git.Repository) string {
+	if repo == nil {
+		return ""
+	}
+
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		return filepath.Join(storage.Filesystem().Root(), "lfs")
+	}
+
+	return ""
+}
+
+// Endpoint returns the LFS server URL of a repository cloned over http(s), as git-lfs derives it by default.
+func Endpoint(repositoryURL string) (string, error) {
+	parsed, err := url.Parse(repositoryURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedURL, repositoryURL)
+	}
+
+	parsed.User = nil
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+
+	if !strings.HasSuffix(parsed.Path, ".git") {
+		parsed.Path += ".git"
+	}
+
+	parsed.Path += "/info/lfs"
+
+	return parsed.String(), nil
+}
+
+// Fetch downloads the LFS objects the repository refers to, but doesn't have yet, from the LFS server
+// of the repository URL it was cloned from, or copies them from its git directory when it is a local path.
+// An object the server reports missing is logged and left out.
+func Fetch(ctx context.Context, repo *git.Repository, repositoryURL string, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:Fetch")
+
+	dir := Dir(repo)
+	if dir == "" {
+		return ErrNoLocalStorage
+	}
+
+	pointers, err := Scan(repo)
+	if err != nil {
+		return err
+	}
+
+	missing := absent(dir, pointers)
+
+	logger.Debug().Int("objects", len(pointers)).Int("missing", len(missing)).Str("url", redact(repositoryURL)).Msg("lfs:Fetch")
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if localPath, isLocal := localGitDir(repositoryURL); isLocal {
+		return copyObjects(ctx, filepath.Join(localPath, "lfs"), dir, missing)
+	}
+
+	endpoint, err := Endpoint(repositoryURL)
+	if err != nil {
+		return err
+	}
+
+	lfsClient, err := newClient(ctx, endpoint, gitOpt, httpOpt)
+	if err != nil {
+		return err
+	}
+
+	return lfsClient.download(ctx, dir, missing)
+}
+
+// Push uploads the LFS objects the repository refers to, and has, to the LFS server of the target URL,
+// or copies them into its git directory when it is a local path. The server is only sent the objects it asks for.
+// A repository without LFS objects has nothing to push, and a target over ssh is skipped with a warning.
+func Push(ctx context.Context, repo *git.Repository, target string, gitOpt gpsconfig.GitOption, httpOpt gpsconfig.HTTPClientOption) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:Push")
+
+	dir := Dir(repo)
+	if dir == "" || !exists(filepath.Join(dir, objectsDir)) {
+		return nil
+	}
+
+	pointers, err := Scan(repo)
+	if err != nil {
+		return err
+	}
+
+	present := make([]Pointer, 0, len(pointers))
+
+	for _, pointer := range pointers {
+		if exists(objectPath(dir, pointer.OID)) {
+			present = append(present, pointer)
+		}
+	}
+
+	logger.Debug().Int("objects", len(present)).Str("target", redact(target)).Msg("lfs:Push")
+
+	if len(present) == 0 {
+		return nil
+	}
+
+	if localPath, isLocal := localGitDir(target); isLocal {
+		return copyObjects(ctx, dir, filepath.Join(localPath, "lfs"), present)
+	}
+
+	endpoint, err := Endpoint(target)
+	if err != nil {
+		logger.Warn().Str("target", redact(target)).Int("objects", len(present)).Msg("LFS objects are not pushed over ssh, push them with git lfs push --all")
+
+		return nil
+	}
+
+	lfsClient, err := newClient(ctx, endpoint, gitOpt, httpOpt)
+	if err != nil {
+		return err
+	}
+
+	return lfsClient.upload(ctx, dir, present)
+}
+
+// absent returns the pointers whose objects are not in the LFS directory.
+func absent(dir string, pointers []Pointer) []Pointer {
+	var missing []Pointer
+
+	for _, pointer := range pointers {
+		if !exists(objectPath(dir, pointer.OID)) {
+			missing = append(missing, pointer)
+		}
+	}
+
+	return missing
+}
+
+// copyObjects copies the objects of the pointers between two LFS directories, skipping those already there.
+func copyObjects(ctx context.Context, fromDir, toDir string, pointers []Pointer) error {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering lfs:copyObjects")
+
+	for _, pointer := range pointers {
+		target := objectPath(toDir, pointer.OID)
+		if exists(target) {
+			continue
+		}
+
+		source, err := os.Open(objectPath(fromDir, pointer.OID))
+		if errors.Is(err, fs.ErrNotExist) {
+			logger.Warn().Str("oid", pointer.OID).Str("dir", fromDir).Msg("LFS object missing, not copied")
+
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrStore, err)
+		}
+
+		err = store(toDir, pointer, source)
+		source.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// store writes an object to the LFS directory, verifying it against its pointer before moving it in place.
+func store(dir string, pointer Pointer, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Join(dir, tmpDir), 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrStore, err)
+	}
+
+	file, err := os.CreateTemp(filepath.Join(dir, tmpDir), pointer.OID+"-*")
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStore, err)
+	}
+	defer os.Remove(file.Name())
+
+	hash := newVerifier()
+
+	written, err := io.Copy(io.MultiWriter(file, hash), content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrStore, pointer.OID, err)
+	}
+
+	if written != pointer.Size || hash.oid() != pointer.OID {
+		return fmt.Errorf("%w: %s: content does not match its pointer", ErrStore, pointer.OID)
+	}
+
+	target := objectPath(dir, pointer.OID)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrStore, err)
+	}
+
+	if err := os.Rename(file.Name(), target); err != nil {
+		return fmt.Errorf("%w: %w", ErrStore, err)
+	}
+
+	return nil
+}
+
+// objectPath returns where the object is kept in the LFS directory.
+func objectPath(dir, oid string) string {
+	return filepath.Join(dir, objectsDir, oid[0:2], oid[2:4], oid)
+}
+
+// localGitDir returns the git directory of a repository given as a local path, the .git directory of a working copy.
+func localGitDir(repositoryURL string) (string, bool) {
+	parsed, err := url.Parse(repositoryURL)
+	if err != nil {
+		return "", false
+	}
+
+	switch parsed.Scheme {
+	case "file":
+		repositoryURL = parsed.Path
+	case "":
+	default:
+		return "", false
+	}
+
+	if !filepath.IsAbs(repositoryURL) {
+		return "", false
+	}
+
+	if dotGit := filepath.Join(repositoryURL, git.GitDirName); exists(dotGit) {
+		return dotGit, true
+	}
+
+	return repositoryURL, true
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+// redact leaves credentials out of a URL to log.
+func redact(repositoryURL string) string {
+	if parsed, err := url.Parse(repositoryURL); err == nil {
+		return parsed.Redacted()
+	}
+
+	return repositoryURL
+}
@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package mirrorcache keeps bare mirrors of source repositories on disk between runs,
+// so a sync only has to fetch what changed since the last one.
+//
+// The mirror of a clone url is stored at <cachedir>/<host>/<path>.git, and is guarded by
+// an exclusive file lock next to it while it is fetched or removed.
+package mirrorcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"itiquette/git-provider-sync/internal/log"
+)
+
+var (
+	ErrCacheDir   = errors.New("invalid mirror cache directory")
+	ErrMirrorPath = errors.New("failed to derive mirror cache path from url")
+	ErrLock       = errors.New("failed to lock mirror cache entry")
+	ErrPrune      = errors.New("failed to prune mirror cache")
+)
+
+const (
+	lockSuffix   = ".lock"
+	mirrorSuffix = ".git"
+	localHost    = "_local"
+)
+
+// Dir returns the directory the bare mirror of the clone url is kept in.
+// Credentials, ports and schemes are left out, so the same repository always maps to the same mirror.
+func Dir(cacheDir, cloneURL string) (string, error) {
+	if cacheDir == "" || !filepath.IsAbs(cacheDir) {
+		return "", fmt.Errorf("%w: %s", ErrCacheDir, cacheDir)
+	}
+
+	host, repoPath, err := splitURL(cloneURL)
+	if err != nil {
+		return "", err
+	}
+
+	elements := []string{host}
+
+	for _, element := range strings.Split(repoPath, "/") {
+		switch element {
+		case "", ".":
+			continue
+		case "..":
+			return "", fmt.Errorf("%w: %s", ErrMirrorPath, cloneURL)
+		default:
+			elements = append(elements, element)
+		}
+	}
+
+	if len(elements) < 2 {
+		return "", fmt.Errorf("%w: %s", ErrMirrorPath, cloneURL)
+	}
+
+	last := len(elements) - 1
+	elements[last] = strings.TrimSuffix(elements[last], mirrorSuffix) + mirrorSuffix
+
+	return filepath.Join(append([]string{cacheDir}, elements...)...), nil
+}
+
+// splitURL splits a clone url into host and repository path.
+// Both scheme urls and the scp-like user@host:path syntax are accepted, anything else is taken as a local path.
+func splitURL(cloneURL string) (string, string, error) {
+	if strings.Contains(cloneURL, "://") {
+		parsed, err := url.Parse(cloneURL)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %w", ErrMirrorPath, err)
+		}
+
+		if parsed.Scheme == "file" {
+			return localHost, parsed.Path, nil
+		}
+
+		return strings.ToLower(parsed.Hostname()), parsed.Path, nil
+	}
+
+	if host, repoPath, found := strings.Cut(cloneURL, ":"); found && !strings.Contains(host, "/") {
+		if _, hostName, hasUser := strings.Cut(host, "@"); hasUser {
+			host = hostName
+		}
+
+		return strings.ToLower(host), repoPath, nil
+	}
+
+	return localHost, filepath.ToSlash(cloneURL), nil
+}
+
+// Lock takes an exclusive lock on the mirror directory, waiting for other runs holding it.
+// The returned function releases the lock.
+func Lock(ctx context.Context, mirrorDir string) (func(), error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering MirrorCache:Lock")
+	logger.Debug().Str("mirrorDir", mirrorDir).Msg("MirrorCache:Lock")
+
+	if err := os.MkdirAll(filepath.Dir(mirrorDir), 0o755); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLock, err)
+	}
+
+	file, err := os.OpenFile(mirrorDir+lockSuffix, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLock, err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+
+		return nil, fmt.Errorf("%w: %w", ErrLock, err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
+
+// Exists reports whether a mirror has been cached in the directory.
+func Exists(mirrorDir string) bool {
+	info, err := os.Stat(filepath.Join(mirrorDir, "HEAD"))
+
+	return err == nil && !info.IsDir()
+}
+
+// Mirrors returns the directories of all mirrors in the cache, sorted.
+func Mirrors(cacheDir string) ([]string, error) {
+	var mirrors []string
+
+	err := filepath.WalkDir(cacheDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == cacheDir {
+				return filepath.SkipAll
+			}
+
+			return err
+		}
+
+		if entry.IsDir() && strings.HasSuffix(entry.Name(), mirrorSuffix) && path != cacheDir {
+			mirrors = append(mirrors, path)
+
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPrune, err)
+	}
+
+	slices.Sort(mirrors)
+
+	return mirrors, nil
+}
+
+// Prune removes the mirrors in the cache that are not among the mirrors to keep,
+// and returns the removed directories. With dryRun nothing is removed.
+func Prune(ctx context.Context, cacheDir string, keep []string, dryRun bool) ([]string, error) {
+	logger := log.Logger(ctx)
+	logger.Trace().Msg("Entering MirrorCache:Prune")
+	logger.Debug().Str("cacheDir", cacheDir).Int("keep", len(keep)).Bool("dryRun", dryRun).Msg("MirrorCache:Prune")
+
+	mirrors, err := Mirrors(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+
+	for _, mirrorDir := range mirrors {
+		if slices.Contains(keep, mirrorDir) {
+			continue
+		}
+
+		removed = append(removed, mirrorDir)
+
+		if dryRun {
+			continue
+		}
+
+		if err := remove(ctx, mirrorDir); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// remove deletes a mirror and its lock file, waiting for any run still using it.
+func remove(ctx context.Context, mirrorDir string) error {
+	unlock, err := Lock(ctx, mirrorDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := os.RemoveAll(mirrorDir); err != nil {
+		return fmt.Errorf("%w: %w", ErrPrune, err)
+	}
+
+	if err := os.Remove(mirrorDir + lockSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %w", ErrPrune, err)
+	}
+
+	return nil
+}
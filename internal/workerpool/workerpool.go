@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+// Package workerpool runs indexed work items on a bounded number of goroutines.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Run calls work for every index in [0, count) using at most workers goroutines.
+// A workers value below one runs the items one by one.
+//
+// Callers collect results by index, which keeps the outcome independent of the
+// order the items happen to finish in. Once an item fails no new items are started,
+// items already running are allowed to finish. The returned error joins the errors
+// of the failed items in index order.
+func Run(ctx context.Context, workers, count int, work func(ctx context.Context, index int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, count)
+	slots := make(chan struct{}, workers)
+	stop := make(chan struct{})
+
+	var (
+		waitGroup sync.WaitGroup
+		failed    sync.Once
+	)
+
+dispatch:
+	for index := range count {
+		if err := ctx.Err(); err != nil {
+			errs[index] = err
+
+			break
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			errs[index] = ctx.Err()
+
+			break dispatch
+		}
+
+		// A failing item closes stop before it frees its slot, so with one worker
+		// nothing runs after the first failure, exactly as a plain loop would.
+		select {
+		case <-stop:
+			break dispatch
+		default:
+		}
+
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-slots }()
+
+			if err := work(ctx, index); err != nil {
+				errs[index] = err
+
+				failed.Do(func() { close(stop) })
+			}
+		}()
+	}
+
+	waitGroup.Wait()
+
+	return errors.Join(errs...)
+}
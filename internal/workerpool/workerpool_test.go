@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2024 Josef Andersson
+//
+// SPDX-License-Identifier: EUPL-1.2
+
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name       string
+		workers    int
+		count      int
+		failAt     int
+		wantMax    int32
+		wantCalled int32
+		wantErr    bool
+	}{
+		{name: "sequential", workers: 1, count: 5, failAt: -1, wantMax: 1, wantCalled: 5},
+		{name: "zero workers runs sequentially", workers: 0, count: 3, failAt: -1, wantMax: 1, wantCalled: 3},
+		{name: "bounded", workers: 3, count: 9, failAt: -1, wantMax: 3, wantCalled: 9},
+		{name: "more workers than items", workers: 8, count: 2, failAt: -1, wantMax: 2, wantCalled: 2},
+		{name: "sequential stops at first failure", workers: 1, count: 5, failAt: 1, wantMax: 1, wantCalled: 2, wantErr: true},
+		{name: "no items", workers: 2, count: 0, failAt: -1, wantMax: 0, wantCalled: 0},
+	}
+
+	for _, tabletest := range tests {
+		t.Run(tabletest.name, func(t *testing.T) {
+			var running, maxRunning, called atomic.Int32
+
+			results := make([]int, tabletest.count)
+
+			err := Run(context.Background(), tabletest.workers, tabletest.count, func(_ context.Context, index int) error {
+				called.Add(1)
+
+				current := running.Add(1)
+				defer running.Add(-1)
+
+				for {
+					seen := maxRunning.Load()
+					if current <= seen || maxRunning.CompareAndSwap(seen, current) {
+						break
+					}
+				}
+
+				time.Sleep(5 * time.Millisecond)
+
+				if index == tabletest.failAt {
+					return errFailed
+				}
+
+				results[index] = index * 10
+
+				return nil
+			})
+
+			require.Equal(t, tabletest.wantCalled, called.Load())
+			require.Equal(t, tabletest.wantMax, maxRunning.Load())
+
+			if tabletest.wantErr {
+				require.ErrorIs(t, err, errFailed)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			for index, result := range results {
+				require.Equal(t, index*10, result)
+			}
+		})
+	}
+}
+
+func TestRun_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Run(ctx, 1, 3, func(_ context.Context, _ int) error {
+		return nil
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+}